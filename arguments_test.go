@@ -221,3 +221,27 @@ func TestCompilingArgumentsLdflagsProcessing(t *testing.T) {
 		})
 	}
 }
+
+func TestStripSymbols(t *testing.T) {
+	for _, tc := range []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{"-X", "main.version=v1", "-X main.name=my app"}, "-ldflags=-X main.version=v1 -X 'main.name=my app' -s -w"},
+		{[]string{"-ldflags=-w"}, "-ldflags=-w -s"},
+		{nil, "-ldflags=-s -w"},
+	} {
+		gb := New(&Config{
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     "build",
+			CompilingArguments:        func() []string { return tc.args },
+			StripSymbols:              true,
+		})
+
+		expected := []string{"build", tc.expected, "-o", filepath.Join("build", "app_temp"), "main.go"}
+		if args := gb.BuildArguments(); strings.Join(args, "|") != strings.Join(expected, "|") {
+			t.Errorf("%q: expected %q, got %q", tc.args, expected, args)
+		}
+	}
+}
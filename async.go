@@ -0,0 +1,25 @@
+package gobuild
+
+import "context"
+
+// CompileAsync starts a build and delivers its result on the returned channel
+// The channel receives exactly one BuildResult and is then closed
+// Cancelling ctx cancels the build, eg: select { case r := <-gb.CompileAsync(ctx): ... }
+// The configured Callback (if any) is still invoked
+func (h *GoBuild) CompileAsync(ctx context.Context) <-chan BuildResult {
+	results := make(chan BuildResult, 1)
+	b := h.Start()
+
+	go func() {
+		select {
+		case <-b.Done():
+		case <-ctx.Done():
+			b.Cancel()
+			<-b.Done()
+		}
+		results <- *b.Result()
+		close(results)
+	}()
+
+	return results
+}
@@ -0,0 +1,40 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCompileAsync(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+
+	select {
+	case result := <-gb.CompileAsync(context.Background()):
+		if result.Err != nil {
+			t.Errorf("Expected successful build, got: %v", result.Err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("CompileAsync did not deliver a result")
+	}
+}
+
+func TestCompileAsyncContextCancel(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	results := gb.CompileAsync(ctx)
+
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	result := <-results
+	if !errors.Is(result.Err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled, got: %v", result.Err)
+	}
+
+	if _, ok := <-results; ok {
+		t.Error("Results channel should be closed after delivering the result")
+	}
+}
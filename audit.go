@@ -0,0 +1,212 @@
+package gobuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"os/user"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord is the accountability entry written for every compile
+type AuditRecord struct {
+	BuildID   uint64    `json:"build_id"`
+	Label     string    `json:"label,omitempty"` // Config.Label, eg: "tenant-42/api"
+	User      string    `json:"user"`            // user running the compiler, Config.RunAs when set
+	Argv      []string  `json:"argv,omitempty"`  // executed command with sensitive -X values redacted
+	EnvHash   string    `json:"env_hash,omitempty"`
+	Restored  bool      `json:"restored,omitempty"` // served from the artifact cache, nothing executed
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+}
+
+// AuditSink receives one AuditRecord per finished compile
+// It is kept separate from the debug Logger
+type AuditSink interface {
+	Record(AuditRecord) error
+}
+
+// AuditFile is an append-only AuditSink writing one JSON object per line
+// eg: gobuild.AuditFile("/var/log/gobuild/audit.jsonl")
+type AuditFile string
+
+var auditFileMu sync.Mutex
+
+func (f AuditFile) Record(r AuditRecord) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	auditFileMu.Lock()
+	defer auditFileMu.Unlock()
+
+	file, err := os.OpenFile(string(f), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// sensitiveVar matches -X variable names redacted by default
+var sensitiveVar = regexp.MustCompile(`(?i)(key|secret|token|password|passwd|credential)`)
+
+// stampFlag finds "-X name=" wherever it appears in an argument: -ldflags=...,
+// a separate -ldflags value or a whole shell command line. Group 1 holds the
+// quotes opening the value (eg: a quote or the escaped quote of sh -c strings), group 2 the name
+var stampFlag = regexp.MustCompile(`(?:^|[\s='"])-X(?:\s+|=)(['"\\]*)([\w./-]+)=`)
+
+// audit writes the record of a finished compile to Config.Audit
+func (h *GoBuild) audit(comp *Build, err error) {
+	if h.config.Audit == nil {
+		return
+	}
+
+	r := AuditRecord{
+		BuildID:   comp.ID,
+		Label:     comp.label,
+		Argv:      h.redactArgv(comp.argv),
+		EnvHash:   comp.envHash,
+		Restored:  comp.restored,
+		Success:   err == nil,
+		StartTime: comp.startTime,
+		EndTime:   h.now(),
+	}
+	r.User = h.auditUser()
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	if aerr := h.config.Audit.Record(r); aerr != nil {
+		h.logf(LogWarn, comp.ID, "Audit record failed:", aerr)
+	}
+}
+
+// redactArgv replaces the value of sensitive -X variables with REDACTED wherever they
+// appear: -ldflags arguments, "-X" "name=value" pairs and shell (UseShell) command strings
+// Sensitive: names matching key/secret/token/password or listed in Config.RedactVars
+func (h *GoBuild) redactArgv(argv []string) []string {
+	if argv == nil {
+		return nil
+	}
+	out := make([]string, len(argv))
+	for i, a := range argv {
+		if i > 0 && argv[i-1] == "-X" {
+			if name, _, ok := strings.Cut(a, "="); ok && h.isSensitiveVar(name) {
+				out[i] = name + "=REDACTED"
+				continue
+			}
+		}
+		out[i] = h.redactStamps(a)
+	}
+	return out
+}
+
+// redactStamps redacts the sensitive -X values inside a single argument
+// A quoted value runs until its closing quote, an unquoted one until a space or quote
+func (h *GoBuild) redactStamps(a string) string {
+	var b strings.Builder
+	for {
+		m := stampFlag.FindStringSubmatchIndex(a)
+		if m == nil {
+			break
+		}
+		quotes, name, start := a[m[2]:m[3]], a[m[4]:m[5]], m[1]
+
+		stop := " \t\n'\""
+		if quotes != "" {
+			stop = quotes[len(quotes)-1:]
+		}
+		end := strings.IndexAny(a[start:], stop)
+		if end < 0 {
+			end = len(a) - start
+		}
+
+		b.WriteString(a[:start])
+		if h.isSensitiveVar(name) {
+			b.WriteString("REDACTED")
+		} else {
+			b.WriteString(a[start : start+end])
+		}
+		a = a[start+end:]
+	}
+	b.WriteString(a)
+	return b.String()
+}
+
+// auditUser names the user the compiler runs as: Config.RunAs when set, else the current OS user
+func (h *GoBuild) auditUser() string {
+	if c := h.config.RunAs; c != nil {
+		uid := strconv.FormatUint(uint64(c.Uid), 10)
+		if u, err := user.LookupId(uid); err == nil {
+			return u.Username
+		}
+		return "uid " + uid
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// splitQuoted splits s on spaces keeping single or double quoted fields together
+func splitQuoted(s string) []string {
+	var fields []string
+	var cur strings.Builder
+	var quote rune
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			cur.WriteRune(r)
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			cur.WriteRune(r)
+		case r == ' ' || r == '\t':
+			if cur.Len() > 0 {
+				fields = append(fields, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if cur.Len() > 0 {
+		fields = append(fields, cur.String())
+	}
+	return fields
+}
+
+func (h *GoBuild) isSensitiveVar(name string) bool {
+	if sensitiveVar.MatchString(name) {
+		return true
+	}
+	for _, v := range h.config.RedactVars {
+		if v == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hashEnv returns a stable SHA-256 of the env entries (order independent)
+func hashEnv(env []string) string {
+	sorted := append([]string{}, env...)
+	sort.Strings(sorted)
+	sum := sha256.Sum256([]byte(strings.Join(sorted, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
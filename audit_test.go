@@ -0,0 +1,105 @@
+package gobuild
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRedactArgv(t *testing.T) {
+	gb := New(&Config{RedactVars: []string{"main.tenant"}})
+
+	tests := []struct {
+		name           string
+		argv, expected []string
+	}{
+		{
+			"ldflags",
+			[]string{"go", "build", "-ldflags=-X main.version=v1 -X 'main.apiKey=abc 123' -X main.tenant=acme", "-o", "app", "main.go"},
+			[]string{"go", "build", "-ldflags=-X main.version=v1 -X 'main.apiKey=REDACTED' -X main.tenant=REDACTED", "-o", "app", "main.go"},
+		},
+		{
+			"separate values",
+			[]string{"tool", "-ldflags", "-X=main.token=xyz", "-X", "main.secret=abc", "-X", "main.version=v1"},
+			[]string{"tool", "-ldflags", "-X=main.token=REDACTED", "-X", "main.secret=REDACTED", "-X", "main.version=v1"},
+		},
+		{
+			"shell",
+			[]string{"/bin/sh", "-lc", `go build '-ldflags=-X '\''main.apiKey=abc 123'\'' -X main.token=xyz' -o app main.go`},
+			[]string{"/bin/sh", "-lc", `go build '-ldflags=-X '\''main.apiKey=REDACTED'\'' -X main.token=REDACTED' -o app main.go`},
+		},
+		{
+			"cmd",
+			[]string{"cmd", "/S", "/C", `"go build "-ldflags=-X ""main.apiKey=abc 123"" -X main.version=v1" main.go"`},
+			[]string{"cmd", "/S", "/C", `"go build "-ldflags=-X ""main.apiKey=REDACTED"" -X main.version=v1" main.go"`},
+		},
+	}
+	for _, tt := range tests {
+		if got := gb.redactArgv(tt.argv); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestAuditUserRunAs(t *testing.T) {
+	gb := New(&Config{RunAs: &Credential{Uid: 0}})
+	if got := gb.auditUser(); got != "root" {
+		t.Errorf("Expected the RunAs user root, got %q", got)
+	}
+
+	gb = New(&Config{RunAs: &Credential{Uid: 4242424}})
+	if got := gb.auditUser(); got != "uid 4242424" {
+		t.Errorf("Expected the RunAs uid, got %q", got)
+	}
+}
+
+func TestAuditFileRecordsEveryCompile(t *testing.T) {
+	tempDir := t.TempDir()
+	auditPath := filepath.Join(tempDir, "audit.jsonl")
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+		CompilingArguments:        func() []string { return []string{"-X", "main.token=xyz"} },
+		Label:                     "tenant-42/api",
+		Audit:                     AuditFile(auditPath),
+	})
+
+	for range 2 {
+		if err := gb.CompileProgram(); err != nil {
+			t.Fatalf("Compilation failed: %v", err)
+		}
+	}
+
+	f, err := os.Open(auditPath)
+	if err != nil {
+		t.Fatalf("Audit file not written: %v", err)
+	}
+	defer f.Close()
+
+	var records []AuditRecord
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r AuditRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("Invalid audit line: %v", err)
+		}
+		records = append(records, r)
+	}
+
+	if len(records) != 2 {
+		t.Fatalf("Expected 2 audit records, got %d", len(records))
+	}
+	r := records[1]
+	if r.BuildID != 2 || r.Label != "tenant-42/api" || !r.Success || r.EnvHash == "" {
+		t.Errorf("Unexpected audit record: %+v", r)
+	}
+	if r.Argv[2] != "-ldflags=-X main.token=REDACTED" {
+		t.Errorf("Expected redacted token in argv, got %q", r.Argv)
+	}
+}
@@ -0,0 +1,204 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"sort"
+	"time"
+)
+
+// BenchmarkOptions configures Benchmark
+type BenchmarkOptions struct {
+	Iterations int  // measured builds per mode, defaults to 3
+	Cold       bool // also measure cold builds, each one with a fresh empty GOCACHE
+}
+
+// BenchmarkStats summarizes the durations of one mode
+type BenchmarkStats struct {
+	Runs   []time.Duration
+	Min    time.Duration
+	Max    time.Duration
+	Mean   time.Duration
+	Median time.Duration
+	StdDev time.Duration
+}
+
+// BenchmarkReport holds the statistics of warm and (optionally) cold builds
+type BenchmarkReport struct {
+	Warm BenchmarkStats
+	Cold *BenchmarkStats // nil unless BenchmarkOptions.Cold
+}
+
+// Benchmark measures build times over several iterations so toolchain and
+// dependency changes can be compared objectively
+// Builds use the compiler settings only (see buildOnlyConfig) and write to a temp folder:
+// the artifact cache, hooks, webhooks, metrics, history, RunAfterBuild and the real
+// output are left alone. Cold builds use a temporary GOCACHE, the user's is never cleared.
+func (h *GoBuild) Benchmark(ctx context.Context, opts BenchmarkOptions) (*BenchmarkReport, error) {
+	if opts.Iterations <= 0 {
+		opts.Iterations = 3
+	}
+
+	report := &BenchmarkReport{}
+
+	if opts.Cold {
+		var runs []time.Duration
+		for i := 0; i < opts.Iterations; i++ {
+			d, err := h.benchmarkRun(ctx, true)
+			if err != nil {
+				return nil, err
+			}
+			runs = append(runs, d)
+		}
+		stats := newBenchmarkStats(runs)
+		report.Cold = &stats
+	}
+
+	// untimed build so the warm runs start from a populated cache
+	if _, err := h.benchmarkRun(ctx, false); err != nil {
+		return nil, err
+	}
+
+	var runs []time.Duration
+	for i := 0; i < opts.Iterations; i++ {
+		d, err := h.benchmarkRun(ctx, false)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, d)
+	}
+	report.Warm = newBenchmarkStats(runs)
+
+	return report, nil
+}
+
+// benchmarkRun performs one build with the compiler settings of h into a temp folder
+func (h *GoBuild) benchmarkRun(ctx context.Context, cold bool) (time.Duration, error) {
+	outDir, err := os.MkdirTemp("", "gobuild_bench")
+	if err != nil {
+		return 0, errors.Join(errors.New("Benchmark"), err)
+	}
+	defer os.RemoveAll(outDir)
+
+	c := h.buildOnlyConfig(outDir)
+	if cold {
+		gocache, err := os.MkdirTemp("", "gobuild_bench_cache")
+		if err != nil {
+			return 0, errors.Join(errors.New("Benchmark"), err)
+		}
+		defer os.RemoveAll(gocache)
+		c.Env = append(c.Env, "GOCACHE="+gocache)
+	}
+
+	result := <-h.sideBuilder(c).CompileAsync(ctx)
+	if result.Err != nil {
+		return 0, errors.Join(errors.New("Benchmark"), result.Err)
+	}
+	return result.Duration, nil
+}
+
+// buildOnlyConfig copies the settings of h that shape the compiled artifact (command,
+// arguments, env, target, stamps...) into a config writing to outDir. Everything acting
+// after or around a build (cache, hooks, webhooks, metrics, history, audit, tracing,
+// quarantine, RunAfterBuild, sidecars, loggers) is left out, for builds made on the side
+func (h *GoBuild) buildOnlyConfig(outDir string) *Config {
+	c := h.config
+	var tinyGo *TinyGoWasm
+	if c.TinyGoWasm != nil {
+		t := *c.TinyGoWasm
+		t.Gzip, t.Report = false, nil
+		tinyGo = &t
+	}
+	return &Config{
+		Command:                   c.Command,
+		CommandLine:               c.CommandLine,
+		UseShell:                  c.UseShell,
+		MainInputFileRelativePath: c.MainInputFileRelativePath,
+		OutName:                   c.OutName,
+		Extension:                 c.Extension,
+		CompilingArguments:        c.CompilingArguments,
+		OutFolderRelativePath:     outDir,
+		WorkDir:                   c.WorkDir,
+		Timeout:                   c.Timeout,
+		Env:                       append([]string{}, c.Env...),
+		EnvFiles:                  c.EnvFiles,
+		IsolateEnv:                c.IsolateEnv,
+		EnvAllowlist:              c.EnvAllowlist,
+		OutputDecoder:             c.OutputDecoder,
+		Agent:                     c.Agent,
+		Runner:                    c.Runner,
+		Secrets:                   c.Secrets,
+		SecretStamps:              c.SecretStamps,
+		Profiling:                 c.Profiling,
+		Mod:                       c.Mod,
+		ModFile:                   c.ModFile,
+		Experiments:               c.Experiments,
+		GoDebug:                   c.GoDebug,
+		TinyGoWasm:                tinyGo,
+		Mobile:                    c.Mobile,
+		CgoToolchains:             c.CgoToolchains,
+		ZigCC:                     c.ZigCC,
+		ExitCodes:                 c.ExitCodes,
+		ErrorMode:                 c.ErrorMode,
+		Device:                    c.Device,
+		TargetWASM:                c.TargetWASM,
+		Clock:                     c.Clock,
+		BuildSalt:                 c.BuildSalt,
+		BuildSaltVar:              c.BuildSaltVar,
+		StampGitInfo:              c.StampGitInfo,
+		Race:                      c.Race,
+		Coverage:                  c.Coverage,
+		StripSymbols:              c.StripSymbols,
+		Labels:                    c.Labels,
+		LabelsVar:                 c.LabelsVar,
+		Policy:                    c.Policy,
+		RunAs:                     c.RunAs,
+		Sandbox:                   c.Sandbox,
+	}
+}
+
+// sideBuilder returns a GoBuild for c carrying the SetEnv/UnsetEnv overrides of h
+func (h *GoBuild) sideBuilder(c *Config) *GoBuild {
+	gb := New(c)
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	for key, value := range h.envOverrides {
+		gb.overrideEnv(key, value)
+	}
+	return gb
+}
+
+func newBenchmarkStats(runs []time.Duration) BenchmarkStats {
+	s := BenchmarkStats{Runs: runs}
+	if len(runs) == 0 {
+		return s
+	}
+
+	sorted := append([]time.Duration{}, runs...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	s.Min = sorted[0]
+	s.Max = sorted[len(sorted)-1]
+	if len(sorted)%2 == 1 {
+		s.Median = sorted[len(sorted)/2]
+	} else {
+		s.Median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	var total time.Duration
+	for _, d := range runs {
+		total += d
+	}
+	s.Mean = total / time.Duration(len(runs))
+
+	var variance float64
+	for _, d := range runs {
+		diff := float64(d - s.Mean)
+		variance += diff * diff
+	}
+	s.StdDev = time.Duration(math.Sqrt(variance / float64(len(runs))))
+
+	return s
+}
@@ -0,0 +1,72 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewBenchmarkStats(t *testing.T) {
+	s := newBenchmarkStats([]time.Duration{4 * time.Second, 2 * time.Second, 6 * time.Second, 4 * time.Second})
+
+	if s.Min != 2*time.Second || s.Max != 6*time.Second {
+		t.Errorf("Unexpected min/max: %v/%v", s.Min, s.Max)
+	}
+	if s.Mean != 4*time.Second || s.Median != 4*time.Second {
+		t.Errorf("Unexpected mean/median: %v/%v", s.Mean, s.Median)
+	}
+	if s.StdDev < 1414*time.Millisecond || s.StdDev > 1415*time.Millisecond {
+		t.Errorf("Unexpected stddev: %v", s.StdDev)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	tempDir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	report, err := gb.Benchmark(context.Background(), BenchmarkOptions{Iterations: 2, Cold: true})
+	if err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+	if len(report.Warm.Runs) != 2 {
+		t.Errorf("Expected 2 warm runs, got %d", len(report.Warm.Runs))
+	}
+	if report.Cold == nil || len(report.Cold.Runs) != 2 {
+		t.Errorf("Expected 2 cold runs, got %+v", report.Cold)
+	}
+}
+
+func TestBenchmarkLeavesOutputAlone(t *testing.T) {
+	tempDir := t.TempDir()
+	artifact := filepath.Join(tempDir, "app")
+	if err := os.WriteFile(artifact, []byte("release"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	marks := filepath.Join(tempDir, "marks")
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeEchoCompiler+"\n[ -n \"$BENCH_MARK\" ] && printf x >> \"$BENCH_MARK\""),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+		Callback:                  func(error) { t.Error("Callback called by Benchmark") },
+	})
+	gb.SetEnv("BENCH_MARK", marks)
+
+	if _, err := gb.Benchmark(context.Background(), BenchmarkOptions{Iterations: 2}); err != nil {
+		t.Fatalf("Benchmark failed: %v", err)
+	}
+	if data, _ := os.ReadFile(artifact); string(data) != "release" {
+		t.Errorf("Benchmark overwrote the artifact: %q", data)
+	}
+	if data, _ := os.ReadFile(marks); len(data) == 0 {
+		t.Errorf("Expected SetEnv to reach the benchmark builds")
+	}
+}
@@ -0,0 +1,231 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BinarySpec describes one main package built by Binaries
+type BinarySpec struct {
+	Name string   // OutName of the binary and its key in Binaries, eg: "server", "worker"
+	Main string   // MainInputFileRelativePath, eg: "cmd/server/main.go"
+	Env  []string // appended to the base Env, eg: []string{"CGO_ENABLED=0"}
+	Args []string // appended to the base CompilingArguments
+
+	// DependsOn names binaries that must build before this one, eg: the wasm bundle
+	// a server embeds. Compile starts it once they all succeed and skips it otherwise
+	DependsOn []string
+}
+
+// Binaries builds several main packages of the same project (server, worker, CLI...)
+// concurrently, each one can be rebuilt or cancelled on its own
+type Binaries struct {
+	names    []string
+	builders map[string]*GoBuild
+	deps     map[string][]string // BinarySpec.DependsOn by name
+
+	mu     sync.Mutex
+	builds map[string]*Build // latest build of each binary
+}
+
+// NewBinaries creates one builder per spec from a copy of base
+// maxParallel caps simultaneous compiles (<= 0 means no limit) unless base.Limiter is set
+func NewBinaries(base Config, specs []BinarySpec, maxParallel int) (*Binaries, error) {
+	if base.Limiter == nil && maxParallel > 0 {
+		base.Limiter = NewLimiter(maxParallel)
+	}
+
+	bs := &Binaries{builders: map[string]*GoBuild{}, deps: map[string][]string{}, builds: map[string]*Build{}}
+	for _, spec := range specs {
+		if spec.Name == "" || spec.Main == "" {
+			return nil, fmt.Errorf("NewBinaries: Name and Main are required, got %+v", spec)
+		}
+		if _, dup := bs.builders[spec.Name]; dup {
+			return nil, fmt.Errorf("NewBinaries: duplicate binary %q", spec.Name)
+		}
+
+		c := base
+		c.OutName = spec.Name
+		c.MainInputFileRelativePath = spec.Main
+		c.Env = append(append([]string{}, base.Env...), spec.Env...)
+		if len(spec.Args) > 0 {
+			baseArgs, args := base.CompilingArguments, spec.Args
+			c.CompilingArguments = func() []string {
+				var all []string
+				if baseArgs != nil {
+					all = append(all, baseArgs()...)
+				}
+				return append(all, args...)
+			}
+		}
+		bs.names = append(bs.names, spec.Name)
+		bs.builders[spec.Name] = New(&c)
+		bs.deps[spec.Name] = spec.DependsOn
+	}
+	if err := bs.checkDeps(); err != nil {
+		return nil, err
+	}
+	return bs, nil
+}
+
+// checkDeps rejects unknown dependencies and cycles, eg: "server" -> "wasm" -> "server"
+func (bs *Binaries) checkDeps() error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	var path []string
+
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("NewBinaries: dependency cycle %s -> %s", strings.Join(path, " -> "), name)
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		path = append(path, name)
+		for _, dep := range bs.deps[name] {
+			if _, ok := bs.builders[dep]; !ok {
+				return fmt.Errorf("NewBinaries: %q depends on unknown binary %q", name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[name] = visited
+		return nil
+	}
+
+	for _, name := range bs.names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Builder returns the GoBuild of the named binary, nil if unknown
+func (bs *Binaries) Builder(name string) *GoBuild {
+	return bs.builders[name]
+}
+
+// Validate checks every binary config and returns an *OutputConflictError
+// when several binaries share a final path (eg: "api" and "API" on Windows)
+func (bs *Binaries) Validate() error {
+	var errs []error
+	for _, name := range bs.names {
+		if err := bs.builders[name].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	}
+	return errors.Join(append(errs, checkOutputs(bs.names, bs.list()))...)
+}
+
+// list returns the builders in spec order
+func (bs *Binaries) list() []*GoBuild {
+	builders := make([]*GoBuild, len(bs.names))
+	for i, name := range bs.names {
+		builders[i] = bs.builders[name]
+	}
+	return builders
+}
+
+// Compile builds every binary and waits for all of them, results are in spec order
+// Binaries without DependsOn start at once, the others as soon as their dependencies
+// succeed, so independent branches still build in parallel. A binary whose dependency
+// failed is not started, its result carries ErrDependencyFailed (E_CANCELLED).
+// Cancelling ctx cancels the builds still running. The returned error joins every failure.
+// Nothing is started when binaries share a final path, see Validate
+func (bs *Binaries) Compile(ctx context.Context) ([]BuildResult, error) {
+	if err := checkOutputs(bs.names, bs.list()); err != nil {
+		return nil, err
+	}
+
+	// roots start in spec order, keeping the Limiter queue order of plain specs
+	bs.mu.Lock()
+	builds := make([]*Build, len(bs.names))
+	for i, name := range bs.names {
+		if len(bs.deps[name]) == 0 {
+			builds[i] = bs.builders[name].Start()
+			bs.builds[name] = builds[i]
+		}
+	}
+	bs.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, bs.Cancel)
+	defer stop()
+
+	results := make([]BuildResult, len(bs.names))
+	errs := make([]error, len(bs.names))
+	index := make(map[string]int, len(bs.names))
+	finished := make(map[string]chan struct{}, len(bs.names))
+	for i, name := range bs.names {
+		index[name] = i
+		finished[name] = make(chan struct{})
+	}
+
+	var wg sync.WaitGroup
+	for i, name := range bs.names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			defer close(finished[name])
+
+			b := builds[i]
+			if b == nil {
+				for _, dep := range bs.deps[name] {
+					<-finished[dep]
+					if err := results[index[dep]].Err; err != nil {
+						err = withCode(fmt.Errorf("%w: %s: %v", ErrDependencyFailed, dep, err))
+						results[i] = BuildResult{ExitCode: -1, EndTime: time.Now(), Code: CodeOf(err), Err: err}
+						errs[i] = fmt.Errorf("%s: %w", name, err)
+						return
+					}
+				}
+				bs.mu.Lock()
+				b = bs.builders[name].Start()
+				bs.builds[name] = b
+				bs.mu.Unlock()
+				// ctx may have ended while waiting, Cancel only reaches started builds
+				if ctx.Err() != nil {
+					b.Cancel()
+				}
+			}
+
+			if err := b.Wait(); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+			results[i] = *b.Result()
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results, errors.Join(errs...)
+}
+
+// Cancel stops every binary
+func (bs *Binaries) Cancel() {
+	for _, name := range bs.names {
+		bs.builders[name].Cancel()
+	}
+}
+
+// Status returns the state of every binary in spec order, TargetStatus.Target is the binary name
+func (bs *Binaries) Status() []TargetStatus {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	status := make([]TargetStatus, 0, len(bs.names))
+	for _, name := range bs.names {
+		status = append(status, buildStatus(name, bs.builds[name]))
+	}
+	return status
+}
@@ -0,0 +1,122 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinariesCompile(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	bs, err := NewBinaries(Config{
+		Command:               writeFakeCompiler(t, dir, "echo \"$@\" >> "+log+"; "+fakeEchoCompiler),
+		OutFolderRelativePath: dir,
+		CompilingArguments:    func() []string { return []string{"-trimpath"} },
+	}, []BinarySpec{
+		{Name: "server", Main: "cmd/server/main.go"},
+		{Name: "worker", Main: "cmd/worker/main.go", Args: []string{"-tags=worker"}},
+	}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := bs.Compile(context.Background())
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	for i, name := range []string{"server", "worker"} {
+		if want := filepath.Join(dir, name); results[i].OutputPath != want {
+			t.Errorf("Expected %s, got %q", want, results[i].OutputPath)
+		}
+		if s := bs.Status()[i]; s.Target != name || s.State != TargetSucceeded {
+			t.Errorf("Unexpected status %+v", s)
+		}
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		worker := strings.Contains(line, "cmd/worker/main.go")
+		if !strings.Contains(line, "-trimpath") || strings.Contains(line, "-tags=worker") != worker {
+			t.Errorf("Unexpected arguments %q", line)
+		}
+	}
+}
+
+func TestNewBinariesRejectsDuplicates(t *testing.T) {
+	_, err := NewBinaries(Config{Command: "go"}, []BinarySpec{
+		{Name: "app", Main: "a/main.go"},
+		{Name: "app", Main: "b/main.go"},
+	}, 0)
+	if err == nil {
+		t.Error("Expected an error for duplicate names")
+	}
+}
+
+func TestBinariesDependsOn(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "order")
+	script := `case "$*" in
+*cmd/wasm/*) sleep 0.2 ;;
+*cmd/bad/*) echo "syntax error" >&2; exit 1 ;;
+esac
+for arg; do case "$arg" in *main.go) echo "$arg" >> ` + log + ` ;; esac; done
+` + fakeEchoCompiler
+
+	bs, err := NewBinaries(Config{
+		Command:               writeFakeCompiler(t, dir, script),
+		OutFolderRelativePath: dir,
+	}, []BinarySpec{
+		{Name: "server", Main: "cmd/server/main.go", DependsOn: []string{"wasm"}},
+		{Name: "wasm", Main: "cmd/wasm/main.go"},
+		{Name: "bad", Main: "cmd/bad/main.go"},
+		{Name: "worker", Main: "cmd/worker/main.go", DependsOn: []string{"bad"}},
+	}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := bs.Compile(context.Background())
+	if err == nil {
+		t.Fatal("Expected the bad binary to fail")
+	}
+	if !results[0].Success() || !results[1].Success() {
+		t.Errorf("Expected server and wasm to build, got %v / %v", results[0].Err, results[1].Err)
+	}
+	if !errors.Is(results[3].Err, ErrDependencyFailed) || results[3].Code != ErrCodeCancelled {
+		t.Errorf("Expected worker to be skipped, got %v (%s)", results[3].Err, results[3].Code)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order := string(data)
+	if strings.Contains(order, "cmd/worker") {
+		t.Errorf("worker must not compile after bad failed:\n%s", order)
+	}
+	if strings.Index(order, "cmd/wasm") > strings.Index(order, "cmd/server") {
+		t.Errorf("Expected wasm before server:\n%s", order)
+	}
+}
+
+func TestNewBinariesRejectsBadDependencies(t *testing.T) {
+	for name, specs := range map[string][]BinarySpec{
+		"unknown": {{Name: "server", Main: "a/main.go", DependsOn: []string{"wasm"}}},
+		"self":    {{Name: "server", Main: "a/main.go", DependsOn: []string{"server"}}},
+		"cycle": {
+			{Name: "server", Main: "a/main.go", DependsOn: []string{"wasm"}},
+			{Name: "wasm", Main: "b/main.go", DependsOn: []string{"server"}},
+		},
+	} {
+		if _, err := NewBinaries(Config{Command: "go"}, specs, 0); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
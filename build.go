@@ -0,0 +1,36 @@
+package gobuild
+
+// Wait blocks until the build finishes and returns its error, nil on success
+func (b *Build) Wait() error {
+	<-b.done
+	return b.err
+}
+
+// Done returns a channel closed when the build finishes
+func (b *Build) Done() <-chan struct{} {
+	return b.done
+}
+
+// Result returns the outcome of the build, nil while it is still running
+func (b *Build) Result() *BuildResult {
+	select {
+	case <-b.done:
+		return b.result
+	default:
+		return nil
+	}
+}
+
+// Cancel stops this build only, other builds of the same GoBuild keep running
+// A build still waiting to start is dropped, a finished build is left untouched
+func (b *Build) Cancel() {
+	h := b.gb
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.unqueue(b.ID) != nil || h.undebounce(b.ID) != nil {
+		h.drop(b, ErrCancelled)
+		return
+	}
+	b.cancel(ErrCancelled)
+}
@@ -0,0 +1,60 @@
+package gobuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBuildHandle(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+
+	b := gb.Start()
+	if b.Result() != nil {
+		t.Error("Result should be nil while the build is running")
+	}
+
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	select {
+	case <-b.Done():
+	default:
+		t.Error("Done channel should be closed after Wait returns")
+	}
+
+	result := b.Result()
+	if result == nil || !result.Success() {
+		t.Fatalf("Expected successful result, got %+v", result)
+	}
+	if result.ID != b.ID {
+		t.Errorf("Expected result ID %d, got %d", b.ID, result.ID)
+	}
+	if result.OutputPath != gb.FinalOutputPath() {
+		t.Errorf("Expected output path %s, got %s", gb.FinalOutputPath(), result.OutputPath)
+	}
+	if result.Duration <= 0 {
+		t.Error("Expected a positive duration")
+	}
+}
+
+func TestBuildHandleCancelOnlyThisBuild(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+	gb.config.CancelMode = CancelSoft
+
+	first := gb.Start()
+	second := gb.Start()
+	if second.ID <= first.ID {
+		t.Errorf("Build ids should increase, got %d then %d", first.ID, second.ID)
+	}
+
+	second.Cancel()
+
+	if err := second.Wait(); !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled for the cancelled build, got: %v", err)
+	}
+	if err := first.Wait(); err != nil {
+		t.Errorf("First build should not be affected, got: %v", err)
+	}
+}
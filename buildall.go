@@ -0,0 +1,90 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// BuildAll compiles every builder with at most maxParallel builds running at once
+// (maxParallel <= 0 means no limit) and waits for all of them to finish.
+// Results are in the same order as builders. The returned error joins every failure.
+// Nothing is started when builders share a final path, see OutputConflictError
+func BuildAll(ctx context.Context, builders []*GoBuild, maxParallel int) ([]BuildResult, error) {
+	return buildAll(ctx, builders, maxParallel, false)
+}
+
+// BuildAllFailFast behaves like BuildAll but cancels the remaining builds as soon
+// as one fails and returns that first error
+func BuildAllFailFast(ctx context.Context, builders []*GoBuild, maxParallel int) ([]BuildResult, error) {
+	return buildAll(ctx, builders, maxParallel, true)
+}
+
+func buildAll(ctx context.Context, builders []*GoBuild, maxParallel int, failFast bool) ([]BuildResult, error) {
+	names := make([]string, len(builders))
+	for i, b := range builders {
+		names[i] = b.MainInputFileRelativePath()
+	}
+	if err := checkOutputs(names, builders); err != nil {
+		return nil, err
+	}
+
+	if maxParallel <= 0 {
+		maxParallel = len(builders)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	results := make([]BuildResult, len(builders))
+	slots := make(chan struct{}, maxParallel)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		errs     []error
+		firstErr error
+	)
+
+	for i, b := range builders {
+		wg.Add(1)
+		go func(i int, b *GoBuild) {
+			defer wg.Done()
+
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				results[i] = BuildResult{Err: context.Cause(ctx)}
+				return
+			}
+
+			// the context may have ended while waiting for the slot
+			if err := context.Cause(ctx); err != nil {
+				results[i] = BuildResult{Err: err}
+				return
+			}
+
+			results[i] = <-b.CompileAsync(ctx)
+			if results[i].Err == nil {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, fmt.Errorf("%s: %w", b.MainInputFileRelativePath(), results[i].Err))
+			if failFast && firstErr == nil {
+				firstErr = errs[len(errs)-1]
+				cancel(ErrCancelled)
+			}
+		}(i, b)
+	}
+
+	wg.Wait()
+
+	if failFast {
+		return results, firstErr
+	}
+	return results, errors.Join(errs...)
+}
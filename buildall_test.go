@@ -0,0 +1,67 @@
+package gobuild
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBuildAll(t *testing.T) {
+	builders := []*GoBuild{
+		newSlowBuild(t, 5*time.Second),
+		newSlowBuild(t, 5*time.Second),
+		newSlowBuild(t, 5*time.Second),
+	}
+
+	results, err := BuildAll(context.Background(), builders, 2)
+	if err != nil {
+		t.Fatalf("BuildAll failed: %v", err)
+	}
+	if len(results) != len(builders) {
+		t.Fatalf("Expected %d results, got %d", len(builders), len(results))
+	}
+	for i, r := range results {
+		if r.Err != nil || r.OutputPath != builders[i].FinalOutputPath() {
+			t.Errorf("Result %d: unexpected %+v", i, r)
+		}
+	}
+}
+
+func TestBuildAllCollectsErrors(t *testing.T) {
+	failing := New(&Config{
+		Command:                   "nonexistentcommand",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "broken",
+		OutFolderRelativePath:     t.TempDir(),
+	})
+	builders := []*GoBuild{failing, newSlowBuild(t, 5*time.Second)}
+
+	results, err := BuildAll(context.Background(), builders, 0)
+	if err == nil {
+		t.Fatal("Expected joined error from the failing builder")
+	}
+	if results[0].Err == nil {
+		t.Error("Expected first result to fail")
+	}
+	if results[1].Err != nil {
+		t.Errorf("Second builder should still succeed in collect-all mode, got: %v", results[1].Err)
+	}
+}
+
+func TestBuildAllFailFast(t *testing.T) {
+	failing := New(&Config{
+		Command:                   "nonexistentcommand",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "broken",
+		OutFolderRelativePath:     t.TempDir(),
+	})
+	builders := []*GoBuild{failing, newSlowBuild(t, 5*time.Second)}
+
+	results, err := BuildAllFailFast(context.Background(), builders, 0)
+	if err == nil {
+		t.Fatal("Expected the first failure to be returned")
+	}
+	if results[1].Err == nil {
+		t.Error("Remaining builds should be cancelled in fail-fast mode")
+	}
+}
@@ -0,0 +1,248 @@
+package gobuild
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// cacheToolchainVars are the go env values hashed into the cache key: the toolchain
+// and the effective target, cgo and flags settings, wherever they come from (host env, go env -w)
+var cacheToolchainVars = []string{
+	"GOVERSION", "GOROOT", "GOOS", "GOARCH", "GOARM", "GOARM64", "GOAMD64", "GO386", "GOWASM",
+	"GOEXPERIMENT", "GOFLAGS", "GOWORK", "CGO_ENABLED", "CC", "CXX",
+	"CGO_CFLAGS", "CGO_CPPFLAGS", "CGO_CXXFLAGS", "CGO_FFLAGS", "CGO_LDFLAGS",
+}
+
+// cacheKey hashes the command, build arguments, environment, toolchain and every file
+// of the module (//go:embed assets included) and of its local replace/go.work modules,
+// so an identical combination maps to the same stored artifact
+func (h *GoBuild) cacheKey(ctx context.Context, args, env []string) (string, error) {
+	sum := sha256.New()
+
+	io.WriteString(sum, h.config.Command+"\x00")
+	for _, a := range h.config.CommandLine {
+		io.WriteString(sum, a+"\x00")
+	}
+	for i, a := range args {
+		if i > 0 && args[i-1] == "-o" {
+			a = filepath.Base(a) // the output folder doesn't change the artifact
+		}
+		io.WriteString(sum, a+"\x00")
+	}
+	for _, e := range env {
+		io.WriteString(sum, e+"\x00")
+	}
+
+	cmd := exec.CommandContext(ctx, h.goTool(), append([]string{"env"}, cacheToolchainVars...)...)
+	cmd.Dir = h.config.WorkDir
+	cmd.Env = h.environment(env)
+	toolchain, err := cmd.Output()
+	if err != nil {
+		return "", errors.Join(errors.New("cacheKey: go env"), err)
+	}
+	sum.Write(toolchain)
+
+	root := moduleRoot(filepath.Dir(h.resolve(h.config.MainInputFileRelativePath)))
+	skip := h.outputDirs()
+	for _, dir := range append([]string{root}, localModules(root)...) {
+		if err := h.hashTree(sum, dir, skip); err != nil {
+			return "", errors.Join(errors.New("cacheKey"), err)
+		}
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// hashTree writes the path and content of every file under root into sum
+// Hidden folders (eg: .git), nested modules, the skip folders and the files
+// gobuild writes itself (artifact, temp files, sidecars) are left out
+func (h *GoBuild) hashTree(sum io.Writer, root string, skip []string) error {
+	io.WriteString(sum, filepath.ToSlash(root)+"\x00")
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			if strings.HasPrefix(d.Name(), ".") || slices.Contains(skip, p) {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir // another module, hashed only when replaced
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || h.generatedFile(d.Name()) {
+			return nil
+		}
+
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		rel, _ := filepath.Rel(root, p)
+		io.WriteString(sum, filepath.ToSlash(rel)+"\x00")
+		_, err = io.Copy(sum, f)
+		return err
+	})
+}
+
+// localModules returns the absolute folders of the local modules the build compiles
+// besides root: replace targets of go.mod, then use and replace entries of the go.work above it
+func localModules(root string) []string {
+	var dirs []string
+	add := func(base, dir string) {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(base, filepath.FromSlash(dir))
+		}
+		if dir = filepath.Clean(dir); dir != root && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+		for _, dir := range parseLocalDirs(data) {
+			add(root, dir)
+		}
+	}
+	if os.Getenv("GOWORK") == "off" {
+		return dirs
+	}
+	for d := root; ; d = filepath.Dir(d) {
+		if data, err := os.ReadFile(filepath.Join(d, "go.work")); err == nil {
+			for _, dir := range parseLocalDirs(data) {
+				add(d, dir)
+			}
+			break
+		}
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	return dirs
+}
+
+// parseLocalDirs returns the local folders named by the replace and use directives
+// of a go.mod or go.work file, eg: "replace example.com/lib => ../lib" => "../lib"
+func parseLocalDirs(data []byte) []string {
+	var dirs []string
+	block := "" // directive of the open ( ) block
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		directive := block
+		switch {
+		case len(fields) == 0:
+			continue
+		case block != "" && fields[0] == ")":
+			block = ""
+			continue
+		case (fields[0] == "replace" || fields[0] == "use") && len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case fields[0] == "replace" || fields[0] == "use":
+			directive, fields = fields[0], fields[1:]
+		case block == "":
+			continue
+		}
+
+		var dir string
+		if directive == "use" && len(fields) > 0 {
+			dir = strings.Trim(fields[0], `"`)
+		} else if i := slices.Index(fields, "=>"); directive == "replace" && i >= 0 && i+1 < len(fields) {
+			dir = strings.Trim(fields[i+1], `"`)
+		}
+		if dir == "." || dir == ".." || strings.HasPrefix(dir, "./") || strings.HasPrefix(dir, "../") || filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// moduleRoot walks up from dir looking for go.mod, returns dir itself if none is found
+func moduleRoot(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, "go.mod")); err == nil {
+			return d
+		}
+		if filepath.Dir(d) == d {
+			return abs
+		}
+	}
+}
+
+// cacheBackend returns the configured artifact cache, nil when caching is disabled
+func (h *GoBuild) cacheBackend() CacheBackend {
+	if h.config.Cache != nil {
+		return h.config.Cache
+	}
+	if h.config.CacheDir != "" {
+		return DirCache(h.resolve(h.config.CacheDir))
+	}
+	return nil
+}
+
+// restoreFromCache copies the stored artifact for key into the temp file
+// Returns false when there is no stored artifact or it can't be copied
+func (h *GoBuild) restoreFromCache(ctx context.Context, cache CacheBackend, key, tempFileName string) bool {
+	artifact, err := cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, ErrCacheMiss) {
+			h.logf(LogWarn, 0, "Cache get failed:", err)
+		}
+		return false
+	}
+	defer artifact.Close()
+
+	if err := writeFile(h.tempPath(tempFileName), artifact, 0755); err != nil {
+		h.cleanupTempFile(tempFileName)
+		return false
+	}
+	return true
+}
+
+// storeInCache keeps a copy of the final artifact under key
+// Failures are only logged, the build itself already succeeded
+func (h *GoBuild) storeInCache(ctx context.Context, cache CacheBackend, key string) {
+	f, err := h.openArtifact(h.FinalOutputPath())
+	if err == nil {
+		defer f.Close()
+		err = cache.Put(ctx, key, f)
+	}
+	if err != nil {
+		h.logf(LogWarn, 0, "Cache put failed:", err)
+	}
+}
+
+// writeFile creates (or truncates) dst with the content of r
+func writeFile(dst string, r io.Reader, perm os.FileMode) error {
+	out, err := os.OpenFile(fixLongPath(dst), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,139 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestCacheRestoresIdenticalBuild(t *testing.T) {
+	tempDir := t.TempDir()
+	srcDir := filepath.Join(tempDir, "src")
+	outDir := filepath.Join(srcDir, "out")
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	mainPath := filepath.Join(srcDir, "main.go")
+	os.WriteFile(filepath.Join(srcDir, "go.mod"), []byte("module example\n"), 0644)
+	os.WriteFile(mainPath, []byte("package main\nfunc main() {}\n"), 0644)
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeEchoCompiler),
+		MainInputFileRelativePath: mainPath,
+		OutName:                   "app",
+		OutFolderRelativePath:     outDir,
+		CacheDir:                  filepath.Join(tempDir, "cache"),
+	})
+
+	first := gb.Start()
+	if err := first.Wait(); err != nil {
+		t.Fatalf("First build failed: %v", err)
+	}
+	if first.Result().RestoredFromCache {
+		t.Error("First build should compile, nothing is cached yet")
+	}
+
+	// the artifact written into OutFolder must not change the key
+	second := gb.Start()
+	if err := second.Wait(); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	if !second.Result().RestoredFromCache {
+		t.Error("Second build of identical sources should be restored from cache")
+	}
+	if second.Result().Hash != first.Result().Hash {
+		t.Error("Restored artifact should have the same hash")
+	}
+
+	os.WriteFile(mainPath, []byte("package main\nfunc main() { println() }\n"), 0644)
+
+	third := gb.Start()
+	if err := third.Wait(); err != nil {
+		t.Fatalf("Third build failed: %v", err)
+	}
+	if third.Result().RestoredFromCache {
+		t.Error("Changed sources should be compiled again")
+	}
+}
+
+func TestCacheKeyInputs(t *testing.T) {
+	tempDir := t.TempDir()
+	srcDir := filepath.Join(tempDir, "src")
+	libDir := filepath.Join(tempDir, "lib")
+	for _, dir := range []string{filepath.Join(srcDir, "web"), filepath.Join(srcDir, "build"), libDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(srcDir, "go.mod"), "module example\n\nreplace example.com/lib => ../lib\n")
+	write(filepath.Join(srcDir, "main.go"), "package main\nfunc main() {}\n")
+	write(filepath.Join(srcDir, "web", "app.wasm"), "v1")
+	write(filepath.Join(libDir, "go.mod"), "module example.com/lib\n")
+	write(filepath.Join(libDir, "lib.go"), "package lib\n")
+
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		WorkDir:                   srcDir,
+	})
+	key := func() string {
+		t.Helper()
+		k, err := gb.cacheKey(context.Background(), []string{"build"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return k
+	}
+
+	base := key()
+	write(filepath.Join(srcDir, "build", "app"), "artifact")
+	if key() != base {
+		t.Error("Files in the output folder must not change the key")
+	}
+
+	for name, change := range map[string]func(){
+		"embedded asset": func() { write(filepath.Join(srcDir, "web", "app.wasm"), "v2") },
+		"replace target": func() { write(filepath.Join(libDir, "lib.go"), "package lib\n\nvar X int\n") },
+		"host GOARCH":    func() { t.Setenv("GOARCH", "riscv64") },
+	} {
+		change()
+		if k := key(); k == base {
+			t.Errorf("%s: expected a new key", name)
+		} else {
+			base = k
+		}
+	}
+}
+
+func TestParseLocalDirs(t *testing.T) {
+	dirs := parseLocalDirs([]byte(`module example
+
+replace example.com/a => ../a
+replace example.com/b v1.0.0 => example.com/fork v1.1.0
+
+replace (
+	example.com/c => ./third_party/c // vendored fork
+	example.com/d => example.com/d v2.0.0
+)
+
+use ./tools
+use (
+	.
+	../shared
+)
+`))
+	want := []string{"../a", "./third_party/c", "./tools", ".", "../shared"}
+	if !slices.Equal(dirs, want) {
+		t.Errorf("Expected %q, got %q", want, dirs)
+	}
+}
@@ -0,0 +1,120 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrCacheMiss is returned by CacheBackend.Get when no artifact is stored under the key
+var ErrCacheMiss = errors.New("cache miss")
+
+// CacheBackend stores compiled artifacts by their source+flags key
+// Implementations must be safe for concurrent use and give up when ctx (the build
+// context, bound by Timeout and Cancel) is done
+type CacheBackend interface {
+	// Get returns the artifact stored under key or ErrCacheMiss
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Put stores the artifact under key, replacing any previous one
+	Put(ctx context.Context, key string, artifact io.Reader) error
+}
+
+// DirCache is a CacheBackend storing artifacts as files in a local folder
+// eg: gobuild.DirCache(".cache/gobuild")
+type DirCache string
+
+func (d DirCache) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(string(d), key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCacheMiss
+	}
+	return f, err
+}
+
+func (d DirCache) Put(ctx context.Context, key string, artifact io.Reader) error {
+	if err := os.MkdirAll(string(d), 0755); err != nil {
+		return err
+	}
+	// write aside and rename so concurrent readers (and writers of the same key)
+	// never see a partial artifact
+	tmp, err := os.CreateTemp(string(d), key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, artifact)
+	err = errors.Join(err, tmp.Chmod(0755), tmp.Close())
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(string(d), key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// HTTPCache is a reference CacheBackend for sharing artifacts between machines
+// It issues GET and PUT requests to BaseURL/<key>, a 404 response is a cache miss
+// eg: &gobuild.HTTPCache{BaseURL: "https://cache.example.com/gobuild"}
+type HTTPCache struct {
+	BaseURL string       // eg: https://cache.example.com/gobuild
+	Client  *http.Client // defaults to a client with a httpCacheTimeout timeout
+	Header  http.Header  // optional extra headers, eg: Authorization
+}
+
+// httpCacheTimeout bounds each HTTPCache request made without a custom Client
+const httpCacheTimeout = 2 * time.Minute
+
+var httpCacheClient = &http.Client{Timeout: httpCacheTimeout}
+
+func (c *HTTPCache) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := c.do(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrCacheMiss
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("HTTPCache get %s: %s", key, resp.Status)
+	}
+}
+
+func (c *HTTPCache) Put(ctx context.Context, key string, artifact io.Reader) error {
+	resp, err := c.do(ctx, http.MethodPut, key, artifact)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTPCache put %s: %s", key, resp.Status)
+	}
+	return nil
+}
+
+func (c *HTTPCache) do(ctx context.Context, method, key string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+"/"+key, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range c.Header {
+		req.Header[k] = v
+	}
+
+	client := c.Client
+	if client == nil {
+		client = httpCacheClient
+	}
+	return client.Do(req)
+}
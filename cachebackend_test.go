@@ -0,0 +1,149 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newCacheServer returns a minimal in-memory artifact store speaking GET/PUT
+func newCacheServer(t *testing.T) *httptest.Server {
+	var (
+		mu    sync.Mutex
+		store = map[string][]byte{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodGet:
+			data, ok := store[r.URL.Path]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write(data)
+		case http.MethodPut:
+			data, _ := io.ReadAll(r.Body)
+			store[r.URL.Path] = data
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPCache(t *testing.T) {
+	ctx := context.Background()
+	cache := &HTTPCache{BaseURL: newCacheServer(t).URL + "/artifacts/"}
+
+	if _, err := cache.Get(ctx, "abc"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Expected ErrCacheMiss, got %v", err)
+	}
+
+	if err := cache.Put(ctx, "abc", strings.NewReader("binary")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+
+	r, err := cache.Get(ctx, "abc")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer r.Close()
+
+	data, _ := io.ReadAll(r)
+	if string(data) != "binary" {
+		t.Errorf("Expected 'binary', got %q", data)
+	}
+}
+
+func TestDirCache(t *testing.T) {
+	ctx := context.Background()
+	cache := DirCache(filepath.Join(t.TempDir(), "cache"))
+
+	if _, err := cache.Get(ctx, "abc"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Expected ErrCacheMiss, got %v", err)
+	}
+	if err := cache.Put(ctx, "abc", strings.NewReader("binary")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(string(cache), "abc")); string(data) != "binary" {
+		t.Errorf("Expected stored artifact, got %q", data)
+	}
+}
+
+func TestHTTPCacheHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	t.Cleanup(srv.Close)
+	t.Cleanup(func() { close(release) })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	cache := &HTTPCache{BaseURL: srv.URL}
+	if _, err := cache.Get(ctx, "abc"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Get to stop with the context, got %v", err)
+	}
+}
+
+func TestDirCacheLeavesNoTempFiles(t *testing.T) {
+	cache := DirCache(t.TempDir())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := cache.Put(context.Background(), "abc", strings.NewReader("binary")); err != nil {
+				t.Errorf("Put failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	entries, _ := os.ReadDir(string(cache))
+	if len(entries) != 1 || entries[0].Name() != "abc" {
+		t.Errorf("Expected only the stored artifact, got %v", entries)
+	}
+}
+
+func TestSharedHTTPCacheBetweenBuilders(t *testing.T) {
+	tempDir := t.TempDir()
+	mainPath := filepath.Join(tempDir, "main.go")
+	os.WriteFile(mainPath, []byte("package main\nfunc main() {}\n"), 0644)
+
+	cache := &HTTPCache{BaseURL: newCacheServer(t).URL}
+	compiler := writeFakeCompiler(t, tempDir, fakeEchoCompiler)
+
+	newBuilder := func(outDir string) *GoBuild {
+		os.MkdirAll(outDir, 0755)
+		return New(&Config{
+			Command:                   compiler,
+			MainInputFileRelativePath: mainPath,
+			OutName:                   "app",
+			OutFolderRelativePath:     outDir,
+			Cache:                     cache,
+		})
+	}
+
+	ci := newBuilder(filepath.Join(tempDir, "ci"))
+	if b := ci.Start(); b.Wait() != nil || b.Result().RestoredFromCache {
+		t.Fatalf("CI build should compile, got %+v", b.Result())
+	}
+
+	dev := newBuilder(filepath.Join(tempDir, "dev"))
+	if b := dev.Start(); b.Wait() != nil || !b.Result().RestoredFromCache {
+		t.Fatalf("Developer build should be restored from the shared cache, got %+v", b.Result())
+	}
+}
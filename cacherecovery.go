@@ -0,0 +1,78 @@
+package gobuild
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+)
+
+// CacheRecovery retries a build once when its output shows a corrupted go build cache
+type CacheRecovery int
+
+const (
+	CacheRecoveryOff     CacheRecovery = iota // report the failure as is (default)
+	CacheRecoveryRebuild                      // retry with GOFLAGS=-a, rebuilding every package without touching the cache
+	CacheRecoveryClean                        // run go clean -cache, then retry
+)
+
+func (c CacheRecovery) String() string {
+	switch c {
+	case CacheRecoveryRebuild:
+		return "GOFLAGS=-a"
+	case CacheRecoveryClean:
+		return "go clean -cache"
+	}
+	return ""
+}
+
+// cacheCorruptionSignatures are output fragments of builds broken by damaged GOCACHE entries
+// rather than by the sources, eg: a disk filled up or a process killed while writing the cache
+var cacheCorruptionSignatures = []string{
+	"fingerprint mismatch",
+	"reading export data",
+	"corrupt object file",
+	"malformed archive",
+	"/go-build/", // GOCACHE entries, work folders are go-build<number>
+	`\go-build\`,
+}
+
+// cacheCorrupted reports whether output points at the build cache instead of the sources
+func cacheCorrupted(output []byte) bool {
+	text := string(output)
+	for _, s := range cacheCorruptionSignatures {
+		if strings.Contains(text, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// recoverCache applies Config.CacheRecovery before the single retry of comp
+// Returns the env for the retry, false when recovery is off or failed
+func (h *GoBuild) recoverCache(ctx context.Context, comp *Build, userEnv []string) ([]string, bool) {
+	mode := h.config.CacheRecovery
+	if mode == CacheRecoveryOff {
+		return nil, false
+	}
+	h.logf(LogWarn, comp.ID, "Build cache corrupted, retrying after", mode.String())
+
+	if mode == CacheRecoveryClean {
+		cmd := exec.CommandContext(ctx, h.goTool(), "clean", "-cache")
+		cmd.Env = h.maintenanceEnv()
+		if output, err := cmd.CombinedOutput(); err != nil {
+			h.logf(LogError, comp.ID, "go clean -cache:", err, strings.TrimSpace(h.decodeOutput(output)))
+			return nil, false
+		}
+		comp.recovery = mode.String()
+		return userEnv, true
+	}
+
+	goflags := h.getenv("GOFLAGS")
+	for _, e := range userEnv {
+		if v, ok := strings.CutPrefix(e, "GOFLAGS="); ok {
+			goflags = v
+		}
+	}
+	comp.recovery = mode.String()
+	return append(append([]string{}, userEnv...), "GOFLAGS="+strings.TrimSpace(goflags+" -a")), true
+}
@@ -0,0 +1,60 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCacheRecoveryRebuild(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	gb := New(&Config{
+		Command: writeFakeCompiler(t, dir, `echo run >> `+runs+`
+case "$GOFLAGS" in
+*-a*) ;;
+*) echo 'link: fingerprint mismatch: runtime has 1a2b, import from main expecting 3c4d' >&2; exit 1 ;;
+esac
+`+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Env:                       []string{"GOFLAGS=-trimpath"},
+		CacheRecovery:             CacheRecoveryRebuild,
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Expected the retry to succeed, got %v", err)
+	}
+	if r := b.Result(); r.CacheRecovery != "GOFLAGS=-a" {
+		t.Errorf("Expected the remediation in the result, got %q", r.CacheRecovery)
+	}
+	if data, _ := os.ReadFile(runs); strings.Count(string(data), "run") != 2 {
+		t.Errorf("Expected exactly one retry, got %q", data)
+	}
+}
+
+func TestCacheRecoveryIgnoresSourceErrors(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo run >> "+runs+"; echo './main.go:3:1: undefined: a (/tmp/go-build1234/b001)' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		CacheRecovery:             CacheRecoveryRebuild,
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err == nil {
+		t.Fatal("Expected the build to fail")
+	}
+	if r := b.Result(); r.CacheRecovery != "" {
+		t.Errorf("Expected no remediation, got %q", r.CacheRecovery)
+	}
+	if data, _ := os.ReadFile(runs); strings.Count(string(data), "run") != 1 {
+		t.Errorf("Expected no retry, got %q", data)
+	}
+}
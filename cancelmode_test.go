@@ -0,0 +1,55 @@
+package gobuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCancelSoftKeepsRunningCompilation(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+	gb.config.CancelMode = CancelSoft
+
+	first := make(chan error, 1)
+	go func() { first <- gb.CompileProgram() }()
+	time.Sleep(100 * time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() { second <- gb.CompileProgram() }()
+	time.Sleep(50 * time.Millisecond)
+
+	third := make(chan error, 1)
+	go func() { third <- gb.CompileProgram() }()
+
+	if err := <-first; err != nil {
+		t.Errorf("Running compilation should finish in soft mode, got: %v", err)
+	}
+	if err := <-second; !errors.Is(err, ErrSuperseded) {
+		t.Errorf("Queued compilation should be dropped with ErrSuperseded, got: %v", err)
+	}
+	if err := <-third; err != nil {
+		t.Errorf("Latest request should run after the active one, got: %v", err)
+	}
+}
+
+func TestCancelSoftCancelDropsPending(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+	gb.config.CancelMode = CancelSoft
+
+	first := make(chan error, 1)
+	go func() { first <- gb.CompileProgram() }()
+	time.Sleep(100 * time.Millisecond)
+
+	second := make(chan error, 1)
+	go func() { second <- gb.CompileProgram() }()
+	time.Sleep(50 * time.Millisecond)
+
+	gb.Cancel()
+
+	if err := <-first; !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled for the running compilation, got: %v", err)
+	}
+	if err := <-second; !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled for the pending compilation, got: %v", err)
+	}
+}
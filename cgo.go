@@ -0,0 +1,131 @@
+package gobuild
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Placeholders expanded in CgoToolchain commands
+// eg: CgoToolchain{CC: "zig cc -target " + gobuild.TriplePlaceholder}
+const (
+	GOOSPlaceholder   = "{{goos}}"
+	GOARCHPlaceholder = "{{goarch}}"
+	TriplePlaceholder = "{{triple}}" // zig/clang target triple, eg: aarch64-linux-gnu
+)
+
+// CgoToolchain is the C/C++ cross compiler pair used for one cgo target
+type CgoToolchain struct {
+	CC  string // eg: "aarch64-linux-gnu-gcc", "zig cc -target {{triple}}"
+	CXX string // optional, eg: "zig c++ -target {{triple}}"
+}
+
+// CgoToolchains maps "goos/goarch" (or "*" for any target) to its cgo cross compilers
+type CgoToolchains map[string]CgoToolchain
+
+// ZigToolchain cross compiles cgo code for any target in cgoTriples with zig cc/c++
+// It is the fallback of Config.ZigCC, or can be set explicitly, eg: CgoToolchains{"linux/arm64": gobuild.ZigToolchain}
+var ZigToolchain = CgoToolchain{
+	CC:  "zig cc -target " + TriplePlaceholder,
+	CXX: "zig c++ -target " + TriplePlaceholder,
+}
+
+// cgoTriples maps GOOS/GOARCH to the target triples understood by zig cc and clang
+var cgoTriples = map[string]string{
+	"linux/amd64":   "x86_64-linux-gnu",
+	"linux/arm64":   "aarch64-linux-gnu",
+	"linux/arm":     "arm-linux-gnueabihf",
+	"linux/386":     "x86-linux-gnu",
+	"linux/riscv64": "riscv64-linux-gnu",
+	"linux/ppc64le": "powerpc64le-linux-gnu",
+	"linux/s390x":   "s390x-linux-gnu",
+	"windows/amd64": "x86_64-windows-gnu",
+	"windows/arm64": "aarch64-windows-gnu",
+	"windows/386":   "x86-windows-gnu",
+	"darwin/amd64":  "x86_64-macos",
+	"darwin/arm64":  "aarch64-macos",
+}
+
+// cgoToolchainEnv adds CC/CXX for cgo cross-builds (CGO_ENABLED=1 and a target other
+// than the host) from Config.CgoToolchains, keyed by "goos/goarch" with "*" as fallback
+// The compilers must exist, a missing one fails before the build with the target named
+// instead of deep linker errors. CC/CXX already set in the build env are only checked.
+func (h *GoBuild) cgoToolchainEnv(env []string) ([]string, error) {
+	lookup := func(key string) string {
+		value := h.getenv(key)
+		for _, entry := range env {
+			if v, ok := strings.CutPrefix(entry, key+"="); ok {
+				value = v
+			}
+		}
+		return value
+	}
+
+	goos, goarch := targetOf(lookup)
+	target := goos + "/" + goarch
+	if lookup("CGO_ENABLED") != "1" || target == runtime.GOOS+"/"+runtime.GOARCH {
+		return env, nil
+	}
+
+	tc, ok := h.config.CgoToolchains[target]
+	if !ok {
+		tc, ok = h.config.CgoToolchains["*"]
+	}
+	if !ok && h.config.ZigCC {
+		tc, ok = ZigToolchain, true
+	}
+
+	var added []string
+	for _, c := range []struct{ key, cmd string }{{"CC", tc.CC}, {"CXX", tc.CXX}} {
+		if lookup(c.key) != "" {
+			if err := checkCgoCompiler(target, c.key, lookup(c.key)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !ok || c.cmd == "" {
+			if c.key == "CC" {
+				return nil, &BuildError{Code: ErrCodeToolchainMissing, Err: fmt.Errorf(
+					"cgo cross-compile to %s needs a C cross compiler: set CC or Config.CgoToolchains[%q], eg: zig cc -target %s",
+					target, target, cgoTriples[target])}
+			}
+			continue // CXX is only needed by C++ sources
+		}
+		cmd, err := expandCgoCommand(c.cmd, goos, goarch)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkCgoCompiler(target, c.key, cmd); err != nil {
+			return nil, err
+		}
+		added = append(added, c.key+"="+cmd)
+	}
+	return append(append([]string{}, env...), added...), nil
+}
+
+// expandCgoCommand replaces the target placeholders of a CgoToolchain command
+func expandCgoCommand(cmd, goos, goarch string) (string, error) {
+	if strings.Contains(cmd, TriplePlaceholder) {
+		triple, ok := cgoTriples[goos+"/"+goarch]
+		if !ok {
+			return "", fmt.Errorf("CgoToolchains: no target triple known for %s/%s, write it in the command", goos, goarch)
+		}
+		cmd = strings.ReplaceAll(cmd, TriplePlaceholder, triple)
+	}
+	cmd = strings.ReplaceAll(cmd, GOOSPlaceholder, goos)
+	return strings.ReplaceAll(cmd, GOARCHPlaceholder, goarch), nil
+}
+
+// checkCgoCompiler verifies the executable of a CC/CXX command can be found
+func checkCgoCompiler(target, key, cmd string) error {
+	fields := strings.Fields(cmd)
+	if len(fields) == 0 {
+		return nil
+	}
+	if _, err := exec.LookPath(fields[0]); err != nil {
+		return &BuildError{Code: ErrCodeToolchainMissing, Err: fmt.Errorf(
+			"%s for %s: %q not found, install it or fix Config.CgoToolchains[%q]", key, target, fields[0], target)}
+	}
+	return nil
+}
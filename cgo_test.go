@@ -0,0 +1,78 @@
+package gobuild
+
+import (
+	"os/exec"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// crossTarget returns a GOOS/GOARCH pair different from the host
+func crossTarget() (string, string) {
+	if runtime.GOOS == "linux" && runtime.GOARCH == "arm64" {
+		return "linux", "amd64"
+	}
+	return "linux", "arm64"
+}
+
+func TestCgoToolchainEnv(t *testing.T) {
+	goos, goarch := crossTarget()
+	base := []string{"CGO_ENABLED=1", "GOOS=" + goos, "GOARCH=" + goarch}
+
+	gb := New(&Config{CgoToolchains: CgoToolchains{
+		"*": {CC: "sh -c cc {{goos}} {{goarch}} {{triple}}"},
+	}})
+	env, err := gb.cgoToolchainEnv(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(append([]string{}, base...), "CC=sh -c cc "+goos+" "+goarch+" "+cgoTriples[goos+"/"+goarch])
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %q, got %q", expected, env)
+	}
+
+	// cgo disabled or a native build leave the env alone
+	native := []string{"CGO_ENABLED=1", "GOOS=" + runtime.GOOS, "GOARCH=" + runtime.GOARCH}
+	if env, err := gb.cgoToolchainEnv(native); err != nil || !reflect.DeepEqual(env, native) {
+		t.Errorf("Expected native env unchanged, got %q (%v)", env, err)
+	}
+}
+
+func TestCgoToolchainMissing(t *testing.T) {
+	goos, goarch := crossTarget()
+	env := []string{"CGO_ENABLED=1", "GOOS=" + goos, "GOARCH=" + goarch}
+
+	if _, err := New(&Config{}).cgoToolchainEnv(env); CodeOf(err) != ErrCodeToolchainMissing {
+		t.Errorf("Expected %s without a toolchain, got %v", ErrCodeToolchainMissing, err)
+	}
+
+	gb := New(&Config{CgoToolchains: CgoToolchains{goos + "/" + goarch: {CC: "no-such-cross-gcc"}}})
+	if _, err := gb.cgoToolchainEnv(env); CodeOf(err) != ErrCodeToolchainMissing {
+		t.Errorf("Expected %s for a missing compiler, got %v", ErrCodeToolchainMissing, err)
+	}
+}
+
+func TestZigCCPreset(t *testing.T) {
+	goos, goarch := crossTarget()
+	base := []string{"CGO_ENABLED=1", "GOOS=" + goos, "GOARCH=" + goarch}
+	triple := cgoTriples[goos+"/"+goarch]
+
+	if _, err := exec.LookPath("zig"); err != nil {
+		// without zig installed the preset must say which compiler is missing
+		_, err := New(&Config{ZigCC: true}).cgoToolchainEnv(base)
+		if CodeOf(err) != ErrCodeToolchainMissing || !strings.Contains(err.Error(), `"zig"`) {
+			t.Errorf("Expected missing zig error, got %v", err)
+		}
+		return
+	}
+
+	env, err := New(&Config{ZigCC: true}).cgoToolchainEnv(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := append(append([]string{}, base...), "CC=zig cc -target "+triple, "CXX=zig c++ -target "+triple)
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %q, got %q", expected, env)
+	}
+}
@@ -0,0 +1,188 @@
+package gobuild
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock is the source of time for timeouts, debounce, idle maintenance, Watch polling
+// and timestamps. Config.Clock defaults to the system clock, tests use a FakeClock
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+	NewTicker(d time.Duration) Ticker
+}
+
+// Timer is the Clock counterpart of *time.Timer created with AfterFunc
+type Timer interface {
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker is the Clock counterpart of *time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// systemClock is the Clock of the time package
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+func (systemClock) NewTicker(d time.Duration) Ticker { return systemTicker{time.NewTicker(d)} }
+
+type systemTicker struct{ *time.Ticker }
+
+func (t systemTicker) C() <-chan time.Time { return t.Ticker.C }
+
+// clock returns Config.Clock or the system clock
+func (h *GoBuild) clock() Clock {
+	if h.config.Clock != nil {
+		return h.config.Clock
+	}
+	return systemClock{}
+}
+
+// now returns the current time of the configured Clock
+func (h *GoBuild) now() time.Time {
+	return h.clock().Now()
+}
+
+// FakeClock is a Clock that only moves with Advance, so timeouts and debounce
+// can be tested without sleeping. Safe for concurrent use
+// eg: clock := gobuild.NewFakeClock(time.Now()); config.Clock = clock; ...; clock.Advance(time.Second)
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// NewFakeClock returns a FakeClock stopped at start
+func NewFakeClock(start time.Time) *FakeClock {
+	return &FakeClock{now: start}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d, firing due timers (synchronously, in order)
+// and ticks on the way
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+	for {
+		w := c.next(target)
+		if w == nil {
+			break
+		}
+		c.now = w.when
+		if w.ticker != nil {
+			w.when = w.when.Add(w.period)
+			select {
+			case w.ticker <- c.now:
+			default: // dropped like time.Ticker does for slow receivers
+			}
+			continue
+		}
+		w.active = false
+		c.mu.Unlock()
+		w.f()
+		c.mu.Lock()
+	}
+	c.now = target
+	c.mu.Unlock()
+}
+
+// Pending returns the number of active timers and tickers, eg: to wait until
+// a build armed its timeout before advancing
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, w := range c.waiters {
+		if w.active {
+			n++
+		}
+	}
+	return n
+}
+
+// next returns the earliest active waiter due at or before target
+// Must be called with c.mu held
+func (c *FakeClock) next(target time.Time) *fakeWaiter {
+	active := c.waiters[:0]
+	for _, w := range c.waiters {
+		if w.active {
+			active = append(active, w)
+		}
+	}
+	c.waiters = active
+	sort.SliceStable(active, func(i, j int) bool { return active[i].when.Before(active[j].when) })
+	if len(active) == 0 || active[0].when.After(target) {
+		return nil
+	}
+	return active[0]
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, when: c.now.Add(d), f: f, active: true}
+	c.waiters = append(c.waiters, w)
+	return w
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w := &fakeWaiter{clock: c, when: c.now.Add(d), period: d, ticker: make(chan time.Time, 1), active: true}
+	c.waiters = append(c.waiters, w)
+	return fakeTicker{w}
+}
+
+// fakeWaiter is a FakeClock timer (f) or ticker (period and ticker)
+type fakeWaiter struct {
+	clock  *FakeClock
+	when   time.Time
+	f      func()
+	period time.Duration
+	ticker chan time.Time
+	active bool
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	was := w.active
+	w.active = false
+	return was
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	was := w.active
+	w.when = w.clock.now.Add(d)
+	w.active = true
+	for _, other := range w.clock.waiters {
+		if other == w {
+			return was
+		}
+	}
+	w.clock.waiters = append(w.clock.waiters, w)
+	return was
+}
+
+// fakeTicker is the Ticker view of a fakeWaiter
+type fakeTicker struct{ w *fakeWaiter }
+
+func (t fakeTicker) C() <-chan time.Time { return t.w.ticker }
+
+func (t fakeTicker) Stop() { t.w.Stop() }
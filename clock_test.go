@@ -0,0 +1,65 @@
+package gobuild
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClockDebounce(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Debounce:                  150 * time.Millisecond,
+		Clock:                     clock,
+	})
+
+	b := gb.Start()
+	clock.Advance(100 * time.Millisecond)
+	if gb.Start() != b {
+		t.Fatal("Expected the request to join the held build")
+	}
+	clock.Advance(100 * time.Millisecond)
+	if gb.IsCompiling() {
+		t.Fatal("Expected the build held until 150ms after the last request")
+	}
+
+	clock.Advance(50 * time.Millisecond)
+	if err := b.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if r := b.Result(); r.Timings.Queued != 250*time.Millisecond || !r.StartTime.Equal(clock.Now()) {
+		t.Errorf("Expected fake clock timestamps, queued %v, started %v", r.Timings.Queued, r.StartTime)
+	}
+}
+
+func TestFakeClockTimeout(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Now())
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "exec sleep 5"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Timeout:                   time.Minute,
+		Clock:                     clock,
+	})
+
+	b := gb.Start()
+	if clock.Pending() != 1 {
+		t.Fatalf("Expected the timeout armed, %d pending timers", clock.Pending())
+	}
+	clock.Advance(time.Minute)
+
+	select {
+	case <-b.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected the fake minute to time the build out")
+	}
+	if code := CodeOf(b.Wait()); code != ErrCodeTimeout {
+		t.Errorf("Expected %s, got %s", ErrCodeTimeout, code)
+	}
+}
@@ -0,0 +1,64 @@
+package gobuild
+
+import (
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// OutputConflict lists the builders promoting to the same final path
+type OutputConflict struct {
+	Path     string   // eg: "/app/build/server"
+	Builders []string // Binaries names, Matrix targets or BuildAll main files
+}
+
+// OutputConflictError is returned before any process is spawned when several
+// builders would overwrite each other's artifacts
+type OutputConflictError struct {
+	Conflicts []OutputConflict
+}
+
+func (e *OutputConflictError) Error() string {
+	msgs := make([]string, 0, len(e.Conflicts))
+	for _, c := range e.Conflicts {
+		msgs = append(msgs, fmt.Sprintf("%s -> %q", strings.Join(c.Builders, ", "), c.Path))
+	}
+	return "output conflict: " + strings.Join(msgs, "; ")
+}
+
+// checkOutputs returns an *OutputConflictError when two builders share a final path
+// names label the builders in the error. Windows and macOS paths are compared case-insensitively
+func checkOutputs(names []string, builders []*GoBuild) error {
+	var conflicts []OutputConflict
+	seen := map[string]int{} // key -> index in conflicts, -1 while unique
+	first := map[string]string{}
+
+	for i, b := range builders {
+		path := b.FinalOutputPath()
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		key := path
+		if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+			key = strings.ToLower(key)
+		}
+
+		idx, dup := seen[key]
+		switch {
+		case !dup:
+			seen[key] = -1
+			first[key] = names[i]
+		case idx < 0:
+			seen[key] = len(conflicts)
+			conflicts = append(conflicts, OutputConflict{Path: path, Builders: []string{first[key], names[i]}})
+		default:
+			conflicts[idx].Builders = append(conflicts[idx].Builders, names[i])
+		}
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return &OutputConflictError{Conflicts: conflicts}
+}
@@ -0,0 +1,80 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatrixOutputConflict(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewMatrix(Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Layout:                    LayoutByVersion,
+		Version:                   "v1.0.0",
+	}, []string{"linux/amd64", "linux/arm64", "windows/amd64", "darwin/arm64"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var conflict *OutputConflictError
+	if err := m.Validate(); !errors.As(err, &conflict) {
+		t.Fatalf("Expected an OutputConflictError, got %v", err)
+	}
+	if len(conflict.Conflicts) != 1 {
+		t.Fatalf("Expected one conflict, got %+v", conflict.Conflicts)
+	}
+	c := conflict.Conflicts[0]
+	if c.Path != filepath.Join(dir, "v1.0.0", "app") || len(c.Builders) != 3 || c.Builders[2] != "darwin/arm64" {
+		t.Errorf("Unexpected conflict %+v", c)
+	}
+
+	results, err := m.Compile(context.Background())
+	if !errors.As(err, &conflict) || results != nil {
+		t.Fatalf("Expected Compile to refuse, got %v %v", results, err)
+	}
+	for _, s := range m.Status() {
+		if s.State != TargetIdle {
+			t.Errorf("%s: expected no build started, got %s", s.Target, s.State)
+		}
+	}
+}
+
+func TestBuildAllOutputConflict(t *testing.T) {
+	dir := t.TempDir()
+	config := Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "cmd/a/main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}
+	other := config
+	other.MainInputFileRelativePath = "cmd/b/main.go"
+
+	_, err := BuildAll(context.Background(), []*GoBuild{New(&config), New(&other)}, 0)
+	var conflict *OutputConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Expected an OutputConflictError, got %v", err)
+	}
+	if got := conflict.Conflicts[0].Builders; len(got) != 2 || got[0] != "cmd/a/main.go" || got[1] != "cmd/b/main.go" {
+		t.Errorf("Unexpected offenders %v", got)
+	}
+}
+
+func TestBinariesValidate(t *testing.T) {
+	dir := t.TempDir()
+	bs, err := NewBinaries(Config{
+		Command:               writeFakeCompiler(t, dir, fakeEchoCompiler),
+		OutFolderRelativePath: dir,
+	}, []BinarySpec{{Name: "server", Main: "cmd/server/main.go"}, {Name: "worker", Main: "cmd/worker/main.go"}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bs.Validate(); err != nil {
+		t.Errorf("Expected distinct outputs, got %v", err)
+	}
+}
@@ -0,0 +1,40 @@
+package gobuild
+
+// ArgsPlaceholder marks where Config.CommandLine receives the computed build arguments
+// eg: []string{"docker", "run", "--rm", "golang:1.22", "go", gobuild.ArgsPlaceholder, "-v"}
+const ArgsPlaceholder = "{{args}}"
+
+// commandLine returns the executable and its full argument list for buildArgs
+// Config.CommandLine (eg: []string{"nix", "develop", "-c", "go"}) takes precedence
+// over Command, the build arguments replace ArgsPlaceholder or are appended at the end
+// With Config.UseShell the result is wrapped in a login shell invocation
+func (h *GoBuild) commandLine(buildArgs []string) (string, []string) {
+	name, args := h.wrappedCommand(buildArgs)
+	if h.config.UseShell {
+		return shellCommand(name, args)
+	}
+	return name, args
+}
+
+// wrappedCommand applies Config.CommandLine to the build arguments
+func (h *GoBuild) wrappedCommand(buildArgs []string) (string, []string) {
+	if len(h.config.CommandLine) == 0 {
+		return h.config.Command, buildArgs
+	}
+
+	name := h.config.CommandLine[0]
+	args := make([]string, 0, len(h.config.CommandLine)+len(buildArgs))
+	placed := false
+	for _, a := range h.config.CommandLine[1:] {
+		if a == ArgsPlaceholder {
+			args = append(args, buildArgs...)
+			placed = true
+			continue
+		}
+		args = append(args, a)
+	}
+	if !placed {
+		args = append(args, buildArgs...)
+	}
+	return name, args
+}
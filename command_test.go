@@ -0,0 +1,64 @@
+package gobuild
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandLine(t *testing.T) {
+	buildArgs := []string{"build", "-o", "app", "main.go"}
+
+	tests := []struct {
+		name         string
+		config       *Config
+		expectedName string
+		expectedArgs []string
+	}{
+		{
+			name:         "plain command",
+			config:       &Config{Command: "go"},
+			expectedName: "go",
+			expectedArgs: buildArgs,
+		},
+		{
+			name:         "wrapper appends args",
+			config:       &Config{Command: "go", CommandLine: []string{"nix", "develop", "-c", "go"}},
+			expectedName: "nix",
+			expectedArgs: []string{"develop", "-c", "go", "build", "-o", "app", "main.go"},
+		},
+		{
+			name:         "placeholder",
+			config:       &Config{CommandLine: []string{"docker", "run", "--rm", "golang:1.22", "go", ArgsPlaceholder, "-v"}},
+			expectedName: "docker",
+			expectedArgs: []string{"run", "--rm", "golang:1.22", "go", "build", "-o", "app", "main.go", "-v"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name, args := New(tt.config).commandLine(buildArgs)
+			if name != tt.expectedName {
+				t.Errorf("Expected command %s, got %s", tt.expectedName, name)
+			}
+			if !reflect.DeepEqual(args, tt.expectedArgs) {
+				t.Errorf("Expected args %v, got %v", tt.expectedArgs, args)
+			}
+		})
+	}
+}
+
+func TestCommandLineWrapperCompiles(t *testing.T) {
+	tempDir := t.TempDir()
+	compiler := writeFakeCompiler(t, tempDir, fakeEchoCompiler)
+
+	gb := New(&Config{
+		CommandLine:               []string{"env", "GOFLAGS=-mod=mod", compiler},
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Errorf("Wrapped compilation failed: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package gobuild
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefBuild is the outcome of building the target at one git ref
+type RefBuild struct {
+	Ref       string
+	Size      int64
+	BuildTime time.Duration
+	Symbols   map[string]int64 // symbol name => size in bytes
+}
+
+// SymbolDelta is the size change of one symbol between two refs
+type SymbolDelta struct {
+	Name  string
+	Delta int64 // bytes, positive when the symbol grew
+}
+
+// RefComparison reports the deltas of building the same target from two refs
+type RefComparison struct {
+	Base, Head     RefBuild
+	SizeDelta      int64         // Head.Size - Base.Size
+	BuildTimeDelta time.Duration // Head.BuildTime - Base.BuildTime
+	SymbolsAdded   int
+	SymbolsRemoved int
+	TopSymbols     []SymbolDelta // largest absolute changes first, at most 20
+}
+
+// CompareRefs builds the target from two git refs (eg: "main" and "HEAD") in
+// temporary worktrees and reports binary size, symbol and build time deltas
+// WorkDir (or the current directory) must be inside the git repository
+// Both builds use the compiler settings and SetEnv overrides only (see buildOnlyConfig):
+// hooks, webhooks, metrics, history, RunAfterBuild and the real output are left alone
+func (h *GoBuild) CompareRefs(ctx context.Context, baseRef, headRef string) (*RefComparison, error) {
+	e := errors.New("CompareRefs")
+
+	top, err := h.git(ctx, h.config.WorkDir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, errors.Join(e, err)
+	}
+
+	// the target location inside the repository, identical in both worktrees
+	absMain, err := filepath.Abs(h.resolve(h.config.MainInputFileRelativePath))
+	if err != nil {
+		return nil, errors.Join(e, err)
+	}
+	relMain, err := filepath.Rel(top, absMain)
+	if err != nil || strings.HasPrefix(relMain, "..") {
+		return nil, errors.Join(e, fmt.Errorf("main file %s is outside the repository %s", absMain, top))
+	}
+
+	base, err := h.buildRef(ctx, top, relMain, baseRef)
+	if err != nil {
+		return nil, errors.Join(e, err)
+	}
+	head, err := h.buildRef(ctx, top, relMain, headRef)
+	if err != nil {
+		return nil, errors.Join(e, err)
+	}
+
+	c := &RefComparison{
+		Base:           *base,
+		Head:           *head,
+		SizeDelta:      head.Size - base.Size,
+		BuildTimeDelta: head.BuildTime - base.BuildTime,
+	}
+
+	for name, size := range head.Symbols {
+		before, ok := base.Symbols[name]
+		if !ok {
+			c.SymbolsAdded++
+		}
+		if size != before {
+			c.TopSymbols = append(c.TopSymbols, SymbolDelta{Name: name, Delta: size - before})
+		}
+	}
+	for name, size := range base.Symbols {
+		if _, ok := head.Symbols[name]; !ok {
+			c.SymbolsRemoved++
+			c.TopSymbols = append(c.TopSymbols, SymbolDelta{Name: name, Delta: -size})
+		}
+	}
+
+	sort.Slice(c.TopSymbols, func(i, j int) bool {
+		a, b := abs64(c.TopSymbols[i].Delta), abs64(c.TopSymbols[j].Delta)
+		if a != b {
+			return a > b
+		}
+		return c.TopSymbols[i].Name < c.TopSymbols[j].Name
+	})
+	if len(c.TopSymbols) > 20 {
+		c.TopSymbols = c.TopSymbols[:20]
+	}
+
+	return c, nil
+}
+
+// buildRef checks out ref in a temporary worktree and builds the target there
+func (h *GoBuild) buildRef(ctx context.Context, repo, relMain, ref string) (*RefBuild, error) {
+	tmp, err := os.MkdirTemp("", "gobuild_ref")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	tree := filepath.Join(tmp, "tree")
+	if _, err := h.git(ctx, repo, "worktree", "add", "--detach", tree, ref); err != nil {
+		return nil, err
+	}
+	defer h.git(context.Background(), repo, "worktree", "remove", "--force", tree)
+
+	c := h.buildOnlyConfig(tmp)
+	c.WorkDir = tree
+	c.MainInputFileRelativePath = relMain
+
+	result := <-h.sideBuilder(c).CompileAsync(ctx)
+	if result.Err != nil {
+		return nil, fmt.Errorf("build %s: %w", ref, result.Err)
+	}
+
+	info, err := os.Stat(result.OutputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols, err := h.readSymbols(ctx, result.OutputPath)
+	if err != nil {
+		return nil, fmt.Errorf("symbols %s: %w", ref, err)
+	}
+
+	return &RefBuild{Ref: ref, Size: info.Size(), BuildTime: result.Duration, Symbols: symbols}, nil
+}
+
+// readSymbols lists the sized symbols of a binary with "go tool nm -size"
+func (h *GoBuild) readSymbols(ctx context.Context, binary string) (map[string]int64, error) {
+	out, err := h.goToolCommand(ctx, "tool", "nm", "-size", binary).Output()
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := map[string]int64{}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		// eg: "  4a5f20        136 T main.main"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 4 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		symbols[strings.Join(fields[3:], " ")] += size
+	}
+	return symbols, scanner.Err()
+}
+
+// goToolCommand prepares a go subcommand with the toolchain of the builds: the go
+// Command or the Config.CommandLine wrapper (not a tinygo one), Config.UseShell,
+// WorkDir and the build env (SetEnv, env files), eg: a GOROOT other than the PATH one
+func (h *GoBuild) goToolCommand(ctx context.Context, args ...string) *exec.Cmd {
+	name, cmdArgs := h.goTool(), args
+	if len(h.config.CommandLine) > 0 && !h.tinyGo() {
+		name, cmdArgs = h.wrappedCommand(args)
+	}
+	if h.config.UseShell {
+		name, cmdArgs = shellCommand(name, cmdArgs)
+	}
+	cmd := exec.CommandContext(ctx, name, cmdArgs...)
+	cmd.Dir = h.config.WorkDir
+	cmd.Env = h.maintenanceEnv()
+	return cmd
+}
+
+// git runs a git command in dir and returns its trimmed output
+func (h *GoBuild) git(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s: %v %s", strings.Join(args, " "), err, bytes.TrimSpace(out))
+	}
+	return string(bytes.TrimSpace(out)), nil
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
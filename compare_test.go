@@ -0,0 +1,91 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCompareRefs(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles with the real toolchain")
+	}
+	for _, tool := range []string{"go", "git"} {
+		if _, err := exec.LookPath(tool); err != nil {
+			t.Skipf("%s not available", tool)
+		}
+	}
+
+	repo := t.TempDir()
+	run := func(args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+	write := func(content string) {
+		os.WriteFile(filepath.Join(repo, "cmd", "main.go"), []byte(content), 0644)
+	}
+
+	os.MkdirAll(filepath.Join(repo, "cmd"), 0755)
+	os.WriteFile(filepath.Join(repo, "go.mod"), []byte("module example\n\ngo 1.22\n"), 0644)
+	run("init", "-q")
+	write("package main\n\nfunc main() { println(\"v1\") }\n")
+	run("add", ".")
+	run("commit", "-qm", "v1")
+	run("tag", "v1")
+	write("package main\n\nimport \"fmt\"\n\nfunc bigger() { fmt.Println(\"v2\") }\n\nfunc main() { bigger() }\n")
+	run("commit", "-qam", "v2")
+
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "cmd/main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		WorkDir:                   repo,
+		Timeout:                   2 * time.Minute,
+		Callback:                  func(error) { t.Error("Callback called by CompareRefs") },
+	})
+
+	c, err := gb.CompareRefs(context.Background(), "v1", "HEAD")
+	if err != nil {
+		t.Fatalf("CompareRefs failed: %v", err)
+	}
+
+	if c.SizeDelta <= 0 {
+		t.Errorf("Importing fmt should grow the binary, got delta %d", c.SizeDelta)
+	}
+	if c.SymbolsAdded == 0 || len(c.TopSymbols) == 0 {
+		t.Errorf("Expected added symbols, got %+v", c.TopSymbols)
+	}
+	if c.Base.Ref != "v1" || c.Head.Ref != "HEAD" {
+		t.Errorf("Unexpected refs %s/%s", c.Base.Ref, c.Head.Ref)
+	}
+}
+
+func TestReadSymbolsUsesBuildToolchain(t *testing.T) {
+	dir := t.TempDir()
+	script := writeFakeCompiler(t, dir, `[ "$1 $2 $3" = "tool nm -size" ] && [ "$GOROOT" = /opt/go1.22 ] || exit 1
+echo "  4a5f20        136 T main.main"`)
+
+	gb := New(&Config{
+		CommandLine:               []string{"/bin/sh", script},
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		WorkDir:                   dir,
+	})
+	gb.SetEnv("GOROOT", "/opt/go1.22")
+
+	symbols, err := gb.readSymbols(context.Background(), filepath.Join(dir, "app"))
+	if err != nil {
+		t.Fatalf("Expected the CommandLine go with the build env, got %v", err)
+	}
+	if symbols["main.main"] != 136 {
+		t.Errorf("Unexpected symbols %v", symbols)
+	}
+}
@@ -0,0 +1,47 @@
+package gobuild
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCompileResult(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'go: downloading example.com/lib v1.0.0' >&2; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+
+	r, err := gb.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if r.OutputPath != filepath.Join(dir, "app") || r.Size != int64(len("artifact")) || r.ExitCode != 0 || r.Duration <= 0 {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+	if !strings.Contains(r.Output, "go: downloading") {
+		t.Errorf("Expected captured output, got %q", r.Output)
+	}
+}
+
+func TestCompileResultOnFailure(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo './main.go:3:1: syntax error' >&2; exit 2"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Callback:                  func(error) {},
+	})
+
+	r, err := gb.Compile()
+	if err == nil || r == nil {
+		t.Fatalf("Expected an error and a result, got %v / %v", r, err)
+	}
+	if r.ExitCode != 2 || r.Size != 0 || r.OutputPath != "" || !strings.Contains(r.Output, "syntax error") {
+		t.Errorf("Unexpected failure result: %+v", r)
+	}
+}
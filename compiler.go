@@ -1,43 +1,109 @@
 package gobuild
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
-	"os"
+	"io"
 	"os/exec"
-	"path"
+	"path/filepath"
 	"strings"
 )
 
 // compileSync performs the actual compilation synchronously with context timeout
-func (h *GoBuild) compileSync(ctx context.Context, comp *compilation) error {
+func (h *GoBuild) compileSync(ctx context.Context, comp *Build) error {
 	var e = errors.New("compileSync")
 
-	buildArgs := h.buildArguments(comp.tempFile)
+	userArgs, userEnv, err := h.resolveBuild(ctx, comp)
+	if err != nil {
+		return err
+	}
+	h.diffDependencies(comp)
+
+	if err := h.ensureOutFolder(); err != nil {
+		return err
+	}
+	comp.lap(&comp.timings.Validation)
+	comp.progress.setPhase("prepare")
 
-	comp.cmd = exec.CommandContext(ctx, h.config.Command, buildArgs...)
+	// Delegate to a remote agent instead of running the compiler locally
+	if h.config.Agent != nil {
+		return h.delegate(ctx, comp, userArgs, userEnv)
+	}
+
+	// Unknown experiments fail here instead of deep inside the compile
+	if err := h.checkExperiments(ctx); err != nil {
+		return err
+	}
+
+	// Stale vendor folders fail here with a clear message instead of missing packages
+	if err := h.syncVendor(ctx, userEnv); err != nil {
+		return err
+	}
+
+	buildArgs := h.buildArgumentsFrom(userArgs, comp.tempFile)
+
+	// Secret stamps are compiled in from a generated -overlay file, never through argv
+	buildArgs, secretsDigest, removeSecrets, err := h.applySecretStamps(ctx, buildArgs, userEnv)
+	if err != nil {
+		return err
+	}
+	defer removeSecrets()
 
-	// Set working directory to output folder for relative paths
-	comp.cmd.Dir = h.config.OutFolderRelativePath
+	// Restore a stored artifact built from the same sources and flags instead of compiling
+	cache := h.cacheBackend()
+	if cache != nil && !comp.discard && !comp.salted {
+		keyArgs := h.buildArgumentsFrom(userArgs, h.outFileName)
+		if secretsDigest != "" {
+			keyArgs = append(keyArgs, "secrets="+secretsDigest)
+		}
+		key, err := h.cacheKey(ctx, keyArgs, userEnv)
+		if err == nil {
+			comp.cacheKey = key
+			if h.restoreFromCache(ctx, cache, key, comp.tempFile) {
+				comp.restored = true
+				comp.lap(&comp.timings.Prepare)
+				return h.finishArtifact(ctx, comp)
+			}
+		}
+	}
 
-	// Set environment variables if provided
-	if len(h.config.Env) > 0 {
-		comp.cmd.Env = append(os.Environ(), h.config.Env...)
+	// Shared Limiter: wait for a free slot before spawning the compiler
+	if err := h.waitSlot(comp); err != nil {
+		return fmt.Errorf("%w: waiting for a build slot", err)
 	}
+	defer h.config.Limiter.release()
+	comp.lap(&comp.timings.Prepare)
 
-	// Use CombinedOutput for simpler and more reliable error capture
-	output, err := comp.cmd.CombinedOutput()
+	output, err := h.execute(ctx, comp, buildArgs, userEnv)
+	if err != nil && ctx.Err() == nil && cacheCorrupted(output) {
+		if env, ok := h.recoverCache(ctx, comp, userEnv); ok {
+			output, err = h.execute(ctx, comp, buildArgs, env)
+		}
+	}
+	comp.lapCompile()
+	comp.progress.setPhase("post-process")
+	comp.output = h.decodeOutput(output)
+	h.eventOutput(comp)
+	if ctx.Err() == nil {
+		err = h.applyExitCodes(comp, err)
+		h.reportDiagnostics(comp, output, err != nil)
+		if err != nil {
+			h.reportSuggestions(comp)
+		}
+	}
 
 	if err != nil {
 		// Emit a single log entry containing the error and the raw build output (no processing)
-		errMsg := fmt.Sprintf("%v build failed: %v", e, err)
+		buildErr := fmt.Errorf("%v build failed: %w", e, err)
 
 		if len(output) > 0 {
-			errMsg += " " + string(output)
+			buildErr = fmt.Errorf("%w %s", buildErr, comp.output)
 		}
+		errMsg := buildErr.Error()
 		// Clean up temporary file if compilation failed
-		h.cleanupTempFile(comp.tempFile)
+		h.removeFailedTemp(comp)
 
 		// Always return an error when the build process reports an error.
 		// Previously, "signal: killed" (from context timeout/cancel) was treated
@@ -46,46 +112,301 @@ func (h *GoBuild) compileSync(ctx context.Context, comp *compilation) error {
 		// failures where compilation appeared successful but the final binary
 		// was missing. Returning the error here ensures callers handle timeouts
 		// and cancellations as failures and the test paths behave correctly.
-		return errors.New(errMsg)
+
+		// Report why the context ended (superseded, cancelled or timeout) when it did
+		if cause := context.Cause(ctx); cause != nil {
+			return fmt.Errorf("%w: %s", cause, errMsg)
+		}
+		if toolchainMissing(err) {
+			return &BuildError{Code: ErrCodeToolchainMissing, Err: buildErr}
+		}
+		// the compiler ran and reported the failure: a non-zero exit or ErrorsFirst stopped it
+		if comp.exitCode > 0 || comp.firstErr {
+			return &BuildError{Code: ErrCodeCompile, Err: buildErr}
+		}
+		return buildErr
 	}
 
 	// fmt.Fprintf(h.config.Logger, "Compilation successful, renaming %s\n", comp.tempFile)
 
-	return h.renameOutputFile(comp.tempFile)
+	// Prewarm builds only fill the go build cache
+	if comp.discard {
+		h.cleanupTempFile(comp.tempFile)
+		return nil
+	}
+
+	if h.config.TinyGoWasm != nil {
+		if err := h.optimizeWasm(ctx, comp); err != nil {
+			h.removeFailedTemp(comp)
+			return err
+		}
+	}
+
+	comp.lap(&comp.timings.PostProcess)
+	if err := h.finishArtifact(ctx, comp); err != nil {
+		return err
+	}
+
+	if comp.cacheKey != "" && !comp.salted {
+		h.storeInCache(ctx, cache, comp.cacheKey)
+	}
+	return nil
+}
+
+// finishArtifact promotes the temp file of a successful build and runs every step
+// that follows: FinalNameFunc link, Manifest, wasm_exec.js, TinyGoWasm gzip and Compress
+// Compiled, cache-restored and Agent builds all go through it
+func (h *GoBuild) finishArtifact(ctx context.Context, comp *Build) error {
+	comp.progress.setPhase("rename")
+	err := h.promote(comp)
+	if err == nil {
+		err = h.publishNamed(comp)
+	}
+	if err == nil {
+		err = h.writeManifest(comp)
+	}
+	comp.lap(&comp.timings.Rename)
+	if err != nil {
+		return err
+	}
+	defer comp.lap(&comp.timings.PostProcess)
+
+	if err := h.copyWasmExec(ctx); err != nil {
+		return err
+	}
+	if h.config.TinyGoWasm != nil {
+		if err := h.compressWasm(comp); err != nil {
+			return err
+		}
+	}
+	return h.compressArtifact(ctx, comp)
+}
+
+// execute runs the compiler once with buildArgs and userEnv
+// Returns the combined stdout and stderr output
+func (h *GoBuild) execute(ctx context.Context, comp *Build, buildArgs, userEnv []string) (output []byte, err error) {
+	name, cmdArgs := h.commandLine(buildArgs)
+	comp.argv = append([]string{name}, cmdArgs...)
+	h.logf(LogDebug, comp.ID, "Running:", strings.Join(h.redactArgv(comp.argv), " "))
+	comp.envHash = hashEnv(userEnv)
+
+	// Relative MainInputFileRelativePath and -o paths are resolved against the working directory
+	// Set environment variables if provided (or the isolated env)
+	req := RunRequest{Name: name, Args: cmdArgs, Dir: h.config.WorkDir, Env: h.environment(userEnv)}
+	comp.progress.setPhase("compile")
+
+	if h.config.Runner != nil {
+		output, err = h.runInjected(ctx, comp, req)
+	} else {
+		comp.cmd = exec.CommandContext(ctx, name, cmdArgs...)
+		if h.config.UseShell {
+			setShellCmdLine(comp.cmd)
+		}
+		if err := h.applySysProcAttr(comp.cmd); err != nil {
+			return nil, err
+		}
+		if err := h.prepareSandbox(comp.cmd); err != nil {
+			return nil, err
+		}
+		comp.cmd.Dir = req.Dir
+		comp.cmd.Env = req.Env
+
+		// Capture stdout and stderr together for simpler and more reliable error capture
+		output, err = h.runCommand(comp)
+		comp.exitCode = comp.cmd.ProcessState.ExitCode() // -1 if it didn't start or was killed
+	}
+	return output, err
+}
+
+// resolveBuild validates the config and returns the user arguments and env of comp
+func (h *GoBuild) resolveBuild(ctx context.Context, comp *Build) (userArgs, userEnv []string, err error) {
+	if err := h.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	compiling := h.compilingArguments() // called once, CompilingArguments may change between calls
+	configured := append(compiling[:len(compiling):len(compiling)], comp.args...)
+	userArgs = append(h.gitStampArgs(ctx, comp), compiling...)
+	userArgs = append(userArgs, h.raceArgs(userArgs)...)
+	userArgs = append(userArgs, h.coverArgs(userArgs)...)
+	userArgs = append(append(userArgs, comp.args...), h.saltArgs(comp)...)
+	userArgs = append(userArgs, h.labelArgs(comp)...)
+
+	userEnv, err = h.userEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reject disallowed flags/env before any process is spawned
+	// Only the configured args are checked, the stamp/race/cover/salt/label flags are the library's own
+	if err := h.config.Policy.Check(configured, userEnv); err != nil {
+		return nil, nil, err
+	}
+
+	// cgo cross-builds get their CC/CXX from Config.CgoToolchains
+	if userEnv, err = h.cgoToolchainEnv(userEnv); err != nil {
+		return nil, nil, err
+	}
+	return userArgs, userEnv, nil
+}
+
+// runCommand starts cmd, attaches the sandbox (if any) and waits for it to exit
+// Children still running afterwards are killed, none outlives the build
+// Returns the combined stdout and stderr output
+func (h *GoBuild) runCommand(comp *Build) ([]byte, error) {
+	cmd := comp.cmd
+	var output bytes.Buffer
+	var out io.Writer = downloadWatcher{out: &output, last: &comp.fetchedAt, now: h.now}
+	if comp.progress != nil {
+		out = progressWriter{out: out, progress: comp.progress}
+	}
+	out, flush := h.streamOutput(comp, out)
+	defer flush()
+	w := h.outputWriter(out, func() {
+		comp.firstErr = true
+		if cmd.Process != nil {
+			cmd.Process.Kill()
+		}
+	})
+	if h.config.ErrorMode == ErrorsFirst {
+		// Compiler subprocesses outliving the killed command may hold the output pipe open
+		cmd.WaitDelay = firstErrorWaitDelay
+	}
+	cmd.Stdout = w
+	cmd.Stderr = w
+	group := isolateGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	comp.progress.setProcess(cmd.Process)
+
+	release, err := h.attachSandbox(group)
+	if err != nil {
+		cmd.Process.Kill()
+		cmd.Wait()
+		return output.Bytes(), err
+	}
+	defer release()
+
+	err = group.wait()
+	return output.Bytes(), err
+}
+
+// quoteLdflagValue quotes a -X key=value containing spaces so the go tool keeps
+// it as a single field when splitting -ldflags, eg: main.name=My App => 'main.name=My App'
+// Values already quoted by the user are returned unchanged
+func quoteLdflagValue(v string) string {
+	if !strings.ContainsAny(v, " \t") || strings.HasPrefix(v, "'") || strings.HasPrefix(v, `"`) {
+		return v
+	}
+	if strings.Contains(v, "'") {
+		return `"` + v + `"`
+	}
+	return "'" + v + "'"
+}
+
+// compilingArguments returns the user supplied arguments, nil if none configured
+// Config.Mod, ModFile and the TinyGoWasm/Mobile flags come first so the Policy and remote agents see them too
+func (h *GoBuild) compilingArguments() []string {
+	var args []string
+	if h.config.Mobile != nil {
+		args = append(args, h.config.Mobile.args()...)
+	}
+	if h.config.TinyGoWasm != nil {
+		args = append(args, h.config.TinyGoWasm.args()...)
+	}
+	if h.config.Mod != "" {
+		args = append(args, "-mod="+h.config.Mod)
+	}
+	if h.config.ModFile != "" {
+		args = append(args, "-modfile="+filepath.FromSlash(h.config.ModFile))
+	}
+	if h.config.CompilingArguments == nil {
+		return args
+	}
+	return append(args, h.config.CompilingArguments()...)
 }
 
 // buildArguments constructs the command line arguments for go build
 func (h *GoBuild) buildArguments(tempFileName string) []string {
-	buildArgs := []string{"build"}
+	args := h.compilingArguments()
+	return h.buildArgumentsFrom(append(args, h.raceArgs(args)...), tempFileName)
+}
+
+// buildArgumentsFrom constructs the go build arguments from already resolved user arguments
+func (h *GoBuild) buildArgumentsFrom(args []string, tempFileName string) []string {
+	buildArgs := []string{h.subcommand()}
 	ldFlags := []string{}
+	gcFlags, hasGcFlags := "", false
 
-	if h.config.CompilingArguments != nil {
-		args := h.config.CompilingArguments()
-		for i := 0; i < len(args); i++ {
-			arg := args[i]
-			if strings.HasPrefix(arg, "-X") {
-				if arg == "-X" && i+1 < len(args) {
-					// -X followed by separate argument
-					ldFlags = append(ldFlags, arg, args[i+1])
-					i++ // Skip next argument as it's part of -X
-				} else if strings.Contains(arg, "=") {
-					// -X key=value in single argument
-					ldFlags = append(ldFlags, arg)
-				} else {
-					// Just -X without value, add to ldFlags
-					ldFlags = append(ldFlags, arg)
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if strings.HasPrefix(arg, "-X") {
+			if arg == "-X" && i+1 < len(args) {
+				// -X followed by separate argument
+				ldFlags = append(ldFlags, arg, quoteLdflagValue(args[i+1]))
+				i++ // Skip next argument as it's part of -X
+			} else if strings.Contains(arg, "=") {
+				// -X key=value in single argument
+				if value, ok := strings.CutPrefix(arg, "-X "); ok {
+					arg = "-X " + quoteLdflagValue(strings.TrimSpace(value))
 				}
+				ldFlags = append(ldFlags, arg)
 			} else {
-				buildArgs = append(buildArgs, arg)
+				// Just -X without value, add to ldFlags
+				ldFlags = append(ldFlags, arg)
 			}
+		} else if value, ok := strings.CutPrefix(arg, "-ldflags="); ok {
+			// merged with the -X flags, a second -ldflags would replace the first one
+			ldFlags = append(ldFlags, value)
+		} else if value, ok := strings.CutPrefix(arg, "-gcflags="); ok && h.config.ErrorMode == ErrorsAll {
+			// -e is added to the user's flags, see ErrorMode
+			gcFlags, hasGcFlags = value, true
+		} else {
+			buildArgs = append(buildArgs, arg)
 		}
 	}
 
+	if h.config.StripSymbols {
+		ldFlags = stripSymbols(ldFlags)
+	}
+
+	if h.config.Profiling != nil {
+		ldFlags = h.config.Profiling.ldflags(ldFlags)
+	}
+
+	if h.tinyGo() {
+		buildArgs, ldFlags = h.tinyGoArgs(buildArgs, ldFlags)
+	}
+
+	if gcFlags, ok := h.config.ErrorMode.gcflags(h.tinyGo(), gcFlags, hasGcFlags); ok {
+		buildArgs = append(buildArgs, "-gcflags="+gcFlags)
+	}
+
 	// Add ldflags if any were found
 	if len(ldFlags) > 0 {
 		buildArgs = append(buildArgs, "-ldflags="+strings.Join(ldFlags, " "))
 	}
 
-	buildArgs = append(buildArgs, "-o", path.Join(h.config.OutFolderRelativePath, tempFileName), h.config.MainInputFileRelativePath)
+	// -o and the main file are relative to the compiler working directory (Config.WorkDir)
+	outPath := filepath.Join(h.tempFolder(), tempFileName)
+	buildArgs = append(buildArgs, "-o", outPath, filepath.FromSlash(h.config.MainInputFileRelativePath))
 	return buildArgs
 }
+
+// stripSymbols adds -s and -w to the linker flags unless already present
+func stripSymbols(ldFlags []string) []string {
+	has := map[string]bool{}
+	for _, entry := range ldFlags {
+		for _, f := range splitQuoted(entry) {
+			has[strings.TrimSuffix(f, "=true")] = true
+		}
+	}
+	for _, f := range []string{"-s", "-w"} {
+		if !has[f] {
+			ldFlags = append(ldFlags, f)
+		}
+	}
+	return ldFlags
+}
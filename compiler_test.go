@@ -3,6 +3,8 @@ package gobuild
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -141,3 +143,30 @@ func TestCompileSyncArgumentParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkDirResolvesRelativePaths(t *testing.T) {
+	workDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workDir, "build"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, t.TempDir(), fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		WorkDir:                   workDir,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Compilation failed: %v", err)
+	}
+
+	expected := filepath.Join(workDir, "build", "app")
+	if gb.FinalOutputPath() != expected {
+		t.Errorf("Expected FinalOutputPath %s, got %s", expected, gb.FinalOutputPath())
+	}
+	if _, err := os.Stat(expected); err != nil {
+		t.Errorf("Output should be written relative to WorkDir: %v", err)
+	}
+}
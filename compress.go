@@ -0,0 +1,134 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Compress writes precompressed copies of every promoted artifact next to it,
+// eg: app.wasm.gz and app.wasm.br for servers sending them with Content-Encoding
+type Compress struct {
+	Gzip          bool   // <artifact>.gz with the best gzip compression
+	Brotli        bool   // <artifact>.br through BrotliCommand, the standard library has no brotli encoder
+	BrotliCommand string // brotli binary, defaults to "brotli"
+}
+
+// CompressReport lists the sizes of the Config.Compress copies, 0 for a disabled format
+type CompressReport struct {
+	Gzip   int64 `json:"gzip,omitempty"`
+	Brotli int64 `json:"brotli,omitempty"`
+}
+
+// compressedFiles returns the sidecar names written by Config.Compress for fileName
+func (c *Compress) compressedFiles(fileName string) []string {
+	var files []string
+	if c.Gzip {
+		files = append(files, fileName+".gz")
+	}
+	if c.Brotli {
+		files = append(files, fileName+".br")
+	}
+	return files
+}
+
+// compressArtifact writes the Config.Compress copies of the promoted artifact
+// A .gz already written by TinyGoWasm.Gzip is reused
+func (h *GoBuild) compressArtifact(ctx context.Context, comp *Build) error {
+	c := h.config.Compress
+	if c == nil {
+		return nil
+	}
+	finalPath := h.FinalOutputPath()
+	comp.compress = &CompressReport{}
+
+	if c.Gzip {
+		if comp.wasmSizes != nil && comp.wasmSizes.Gzipped > 0 {
+			comp.compress.Gzip = comp.wasmSizes.Gzipped
+		} else {
+			size, err := h.gzipFile(finalPath, finalPath+".gz")
+			if err != nil {
+				return errors.Join(errors.New("gzip artifact"), err)
+			}
+			comp.compress.Gzip = size
+		}
+	}
+
+	if c.Brotli {
+		size, err := h.brotliFile(ctx, c.BrotliCommand, finalPath, finalPath+".br")
+		if err != nil {
+			return err
+		}
+		comp.compress.Brotli = size
+	}
+	return nil
+}
+
+// brotliFile pipes src through the brotli command into dst, returns the dst size
+// Both are promoted files, see Config.OutputFS
+func (h *GoBuild) brotliFile(ctx context.Context, command, src, dst string) (int64, error) {
+	if command == "" {
+		command = "brotli"
+	}
+	in, err := h.openArtifact(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	return h.writeSidecar(dst, func(w io.Writer) error {
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, command, "-c", "-Z")
+		cmd.Dir = h.config.WorkDir
+		cmd.Stdin = in
+		cmd.Stdout = w
+		cmd.Stderr = &stderr
+		if err := cmd.Run(); err != nil {
+			brErr := fmt.Errorf("brotli: %v %s", err, strings.TrimSpace(h.decodeOutput(stderr.Bytes())))
+			if toolchainMissing(err) {
+				return &BuildError{Code: ErrCodeToolchainMissing, Err: brErr}
+			}
+			return brErr
+		}
+		return nil
+	})
+}
+
+// writeSidecar writes a copy of the promoted artifact (eg: app.wasm.gz) to a temp file
+// renamed to dst once complete and returns its size. On failure the temp file and the
+// dst left by the previous build are removed, so no half-written or stale sidecar
+// is served next to the new artifact (Config.OutputFS can't remove, its dst stays)
+func (h *GoBuild) writeSidecar(dst string, write func(io.Writer) error) (int64, error) {
+	dir := filepath.Dir(dst)
+	if h.config.OutputFS != nil {
+		dir = h.tempPath("")
+	}
+	tmp, err := os.CreateTemp(fixLongPath(dir), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return 0, err
+	}
+
+	counter := &countingWriter{w: tmp}
+	err = errors.Join(write(counter), tmp.Chmod(0644), tmp.Close())
+	if err == nil {
+		if h.config.OutputFS != nil {
+			err = h.config.OutputFS.Promote(tmp.Name(), dst)
+		} else {
+			err = os.Rename(tmp.Name(), fixLongPath(dst))
+		}
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		if h.config.OutputFS == nil {
+			os.Remove(fixLongPath(dst))
+		}
+		return 0, err
+	}
+	return counter.n, nil
+}
@@ -0,0 +1,99 @@
+package gobuild
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	dir := t.TempDir()
+	brotli := writeFakeCompiler(t, t.TempDir(), `echo "$@" > `+filepath.Join(dir, "brotli-args")+`; printf br; cat > /dev/null`)
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     dir,
+		Compress:                  &Compress{Gzip: true, Brotli: true, BrotliCommand: brotli},
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "app.wasm.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); string(data) != "artifact" {
+		t.Errorf("Expected the artifact gzipped, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "app.wasm.br")); string(data) != "br" {
+		t.Errorf("Expected the brotli output, got %q", data)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(dir, "brotli-args")); string(data) != "-c -Z\n" {
+		t.Errorf("Unexpected brotli arguments %q", data)
+	}
+
+	info, _ := f.Stat()
+	if c := b.Result().Compressed; c == nil || c.Gzip != info.Size() || c.Brotli != 2 {
+		t.Errorf("Unexpected sizes %+v", c)
+	}
+	unobserved := gb.UnobservedFiles()
+	if unobserved[len(unobserved)-1] != "app.wasm.br" {
+		t.Errorf("Expected the copies unobserved, got %v", unobserved)
+	}
+}
+
+func TestCompressBrotliMissing(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Compress:                  &Compress{Brotli: true, BrotliCommand: filepath.Join(dir, "no-brotli")},
+	})
+
+	if err := gb.CompileProgram(); CodeOf(err) != ErrCodeToolchainMissing {
+		t.Errorf("Expected %s, got %q (%v)", ErrCodeToolchainMissing, CodeOf(err), err)
+	}
+}
+
+func TestCompressFailureLeavesNoSidecar(t *testing.T) {
+	dir := t.TempDir()
+	brotli := filepath.Join(dir, "brotli")
+	if err := os.WriteFile(brotli, []byte("#!/bin/sh\nprintf partial\nexit 1\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	stale := filepath.Join(dir, "app.br")
+	os.WriteFile(stale, []byte("previous build"), 0644)
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Compress:                  &Compress{Brotli: true, BrotliCommand: brotli},
+	})
+
+	if err := gb.CompileProgram(); err == nil {
+		t.Fatal("Expected the brotli failure")
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Expected the stale app.br removed, got %v", err)
+	}
+	if tmps, _ := filepath.Glob(filepath.Join(dir, "app.br.*")); len(tmps) != 0 {
+		t.Errorf("Expected no half-written sidecar, got %v", tmps)
+	}
+}
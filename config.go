@@ -1,22 +1,123 @@
 package gobuild
 
 import (
+	"io"
+	"log/slog"
 	"time"
 )
 
 // CompileCallback is called when compilation completes (success or failure)
 type CompileCallback func(error)
 
+// CancelMode controls what happens to a running compilation when a new one is requested
+type CancelMode int
+
+const (
+	// CancelHard kills the running compilation and starts the new one right away (default)
+	CancelHard CancelMode = iota
+	// CancelSoft lets the running compilation finish, its result may still be useful.
+	// Only the latest request waits for it, anything queued before is dropped.
+	CancelSoft
+	// CancelQueue keeps every request, they run one after another ordered by
+	// priority then arrival (FIFO by default), see GoBuild.PendingBuilds and StartWith
+	// Use it when every requested build has to complete, eg: variants with BuildOptions.Args
+	CancelQueue
+)
+
 // Config holds the configuration for Go compilation
 type Config struct {
 	Command                   string               // eg: "go", "tinygo"
+	CommandLine               []string             // optional wrapper replacing Command, eg: []string{"nix", "develop", "-c", "go"}. Build args are appended or replace ArgsPlaceholder
+	UseShell                  bool                 // run the full command through a login shell (/bin/sh -lc / cmd /S /C) to pick up asdf, mise...
 	MainInputFileRelativePath string               // eg: web/main.server.go, web/main.wasm.go
 	OutName                   string               // eg: app, user, main.server
 	Extension                 string               // eg: .exe, .wasm
 	CompilingArguments        func() []string      // eg: []string{"-X 'main.version=v1.0.0'"}
 	OutFolderRelativePath     string               // eg: web, web/public/wasm
-	Logger                    func(message ...any) // output for log messages to integrate with other tools (e.g., TUI)
+	WorkDir                   string               // compiler working directory (eg: module root), relative paths are resolved against it, ".." included (eg: ../dist). Defaults to the current directory
+	Logger                    func(message ...any) // output for log messages to integrate with other tools (e.g., TUI), receives LogWarn and above
+	JSONEvents                bool                 // Logger receives NDJSON BuildEvents (build_started, build_output, build_finished, log) instead of free-form text
+	StreamOutput              bool                 // send the compiler output to Logger line by line while it runs (long builds, -x/-v) instead of only in the result
+	OutputWriter              io.Writer            // optional live copy of the compiler output, one line per write as it is produced
+	Slog                      *slog.Logger         // optional leveled output: command lines at Debug, results at Info, failures at Error, with a build_id attribute
+	LogSinks                  []LogSink            // optional outputs (terminal, file, channel) each with its own minimum LogLevel
 	Callback                  CompileCallback      // optional callback for async compilation
 	Timeout                   time.Duration        // max compilation time, defaults to 5 seconds if not set
 	Env                       []string             // environment variables, eg: []string{"GOOS=js", "GOARCH=wasm"}
+	CacheDir                  string               // optional folder storing artifacts by a hash of every module file (embeds and local replaces included), flags, env and toolchain, a match is restored instead of compiled
+	Cache                     CacheBackend         // optional shared artifact cache (eg: HTTPCache), takes precedence over CacheDir
+	Agent                     Agent                // optional remote agent compiling instead of the local toolchain (eg: HTTPAgent, Coordinator)
+	OutputDecoder             OutputDecoder        // optional decoder for non UTF-8 compiler output, defaults to UTF-16/Windows-1252 detection
+	EnvFiles                  []string             // dotenv files merged into the env on every compile, later files and Env take precedence, eg: []string{".env", ".env.local"}
+	IsolateEnv                bool                 // start from an empty env keeping only HOME, PATH, GO*... instead of inheriting os.Environ()
+	EnvAllowlist              []string             // extra host variables kept with IsolateEnv, eg: []string{"CC", "PKG_CONFIG_*"}
+	Secrets                   SecretSource         // optional source of secret values resolved at build time
+	SecretStamps              map[string]string    // -X variable => Secrets key, eg: {"main.apiKey": "API_KEY"}. Compiled in from a generated -overlay file, never on a command line
+	Label                     string               // who/what label for audit records, eg: "tenant-42/api"
+	Audit                     AuditSink            // optional append-only record of every compile (eg: AuditFile), separate from Logger
+	RedactVars                []string             // extra -X variables redacted in audit records, key/secret/token/password names always are
+	Limiter                   *Limiter             // optional, shared between GoBuild instances to cap simultaneous compiles, eg: gobuild.NewLimiter(2)
+	Profiling                 *ProfilingOptions    // optional preset keeping symbols/DWARF for perf investigations and enabling a pprof listener
+	Layout                    Layout               // artifact arrangement inside the output folder, eg: gobuild.LayoutByTarget => build/linux_arm64/app
+	Version                   string               // folder name for LayoutByVersion, eg: v1.2.0
+	Fsync                     bool                 // fsync the artifact before and its folder after the rename, for builds deployed right away (eg: to devices)
+	VerifyArtifact            bool                 // re-hash the final file after the rename, guards against antivirus/file-sync tools rewriting it
+	Mod                       string               // go build -mod value: readonly, vendor or mod
+	ModFile                   string               // alternate go.mod relative to WorkDir (its go.sum sits next to it), eg: go.legacy.mod
+	VendorSync                bool                 // run go mod vendor (go work vendor in a workspace) before vendor-mode builds when go.mod/go.sum changed
+	Experiments               []string             // GOEXPERIMENT values checked against the toolchain, eg: []string{"loopvar", "nogreenteagc"}
+	GoDebug                   []string             // GODEBUG settings, eg: []string{"gotypesalias=1", "http2client=0"}
+	OnDiagnostic              func(Diagnostic)     // optional, receives each parsed compiler error/warning/note, warnings never fail the build
+	PrewarmDiscard            bool                 // Prewarm deletes its artifact instead of promoting it (only the go build cache is filled)
+	CacheMaintenance          *CacheMaintenance    // optional GOCACHE budget applied while idle, see StartCacheMaintenance
+	CacheRecovery             CacheRecovery        // opt-in single retry of builds failing on a corrupted GOCACHE (fingerprint mismatch, unreadable entries), reported in BuildResult.CacheRecovery
+	TinyGoWasm                *TinyGoWasm          // optional TinyGo size pipeline: -no-debug/-panic=trap/-opt, wasm-opt, gzip and a size report
+	Compress                  *Compress            // optional precompressed copies of each promoted artifact (<artifact>.gz, .br with the brotli command), eg: for wasm served with Content-Encoding
+	Mobile                    *Mobile              // optional gomobile bind/build mode for Android/iOS (AAR, XCFramework, APK, app)
+	CgoToolchains             CgoToolchains        // CC/CXX per "goos/goarch" ("*" fallback) for CGO_ENABLED=1 cross-builds, eg: {"*": {CC: "zig cc -target {{triple}}"}}
+	ZigCC                     bool                 // cgo cross-builds without a CgoToolchains entry use zig cc -target <triple> (see ZigToolchain)
+	DedupeDiagnostics         bool                 // stream "same 3 errors as previous build" instead of repeating identical diagnostics to OnDiagnostic
+	OnStart                   StartHook            // optional, called with the build ID and start time when a build begins (queued builds: when they leave the queue)
+	OnSuccess                 ResultHook           // optional, called with the result of each successful build
+	OnFailure                 ResultHook           // optional, called when a build fails (compile, timeout, validation, promotion...)
+	OnCancel                  ResultHook           // optional, called when a build is cancelled, superseded or dropped from the queue
+	Webhooks                  []Webhook            // optional URLs receiving the BuildResult JSON of each build, HMAC signed and retried with backoff
+	Watchdog                  *Watchdog            // optional periodic "still compiling, 45s elapsed, phase=link" events and a stack dump of stalled builds
+	Metrics                   *Metrics             // optional Prometheus counters (builds by result, duration, binary size), can be shared between instances
+	TracerProvider            TracerProvider       // optional OpenTelemetry-style tracing: a "gobuild.compile" span per build with target, args hash, size and result
+	HistorySize               int                  // builds kept for History/QueryHistory (outcome, duration, error summary), 0 disables it
+	ExitCodes                 ExitCodes            // optional classes of nonzero exit codes of a wrapped Command, eg: {2: gobuild.ExitWarning}. Others fail the build
+	ErrorMode                 ErrorMode            // ErrorsFirst stops at the first compiler error (watch mode), ErrorsAll lists every error (CI)
+	Debounce                  time.Duration        // optional quiet period: requests made within it (eg: editor save storms) collapse into one build
+	DebounceMaxWait           time.Duration        // optional upper bound on how long Debounce delays a build during a continuous burst
+	WatchInterval             time.Duration        // how often Watch polls the source folders, defaults to 300ms
+	OnSuggestion              func(Suggestion)     // optional, receives the fixes proposed for a failed build (go get, go mod tidy, goimports the file)
+	OutputFS                  OutputFS             // optional destination of the promoted artifact and sidecars (eg: in-memory, zip, object store), nil uses the local disk
+	Device                    string               // named target profile expanding into GOOS/GOARCH/GOARM and the extension, eg: "raspberry-pi-4", see DeviceProfiles. Env overrides it
+	TargetWASM                bool                 // js/wasm preset: GOOS=js GOARCH=wasm, ".wasm" Extension when empty, incompatible flags/env rejected by Validate (also the tinygo -target)
+	CopyWasmExec              bool                 // js/wasm builds copy wasm_exec.js from the toolchain (GOROOT or TINYGOROOT) next to the output
+	RunAfterBuild             bool                 // start the artifact after each successful build, the previous process is stopped before the rename (dev reload loops)
+	RunArgs                   []string             // arguments of the started artifact, eg: []string{"-port", "8080"}
+	RunOutput                 io.Writer            // stdout and stderr of the started artifact, nil discards them
+	RunStopTimeout            time.Duration        // how long the previous process gets to exit after SIGTERM before it is killed, defaults to 5s
+	Clock                     Clock                // optional source of time for timeouts, debounce, Watch and timestamps, eg: a FakeClock in tests
+	Runner                    Runner               // optional replacement for os/exec when running the compiler, eg: deterministic fakes in tests
+	BuildSalt                 bool                 // link a random salt into every build so identical sources give distinct binaries (cache-busting tests), skips the artifact cache
+	BuildSaltVar              string               // optional string variable receiving the salt via -X, eg: "main.buildSalt". Default: the link -buildid (not available with tinygo)
+	StampGitInfo              bool                 // inject -X main.version/main.commit/main.date from git describe and the last commit in WorkDir
+	QuarantineDir             string               // optional folder receiving the temp file, output and error of failed builds (one folder per build) instead of deleting them
+	StagingDir                string               // writable folder the compiler writes into when the output folder is read-only (immutable deploy dirs, containers)
+	Elevate                   ElevateFunc          // copies the staged artifact into the read-only output folder, required with StagingDir
+	Race                      bool                 // build with the race detector (-race), Validate rejects targets and settings it doesn't support
+	Coverage                  *Coverage            // optional -cover build writing profiles to Coverage.Dir (GOCOVERDIR), per target in a Matrix
+	StripSymbols              bool                 // release builds: add -s -w to the -ldflags built from CompilingArguments (-no-debug with tinygo)
+	Labels                    Labels               // key/value build metadata (eg: CI pipeline ID, PR number) for the Manifest and LabelsVar, BuildOptions.Labels add per build
+	LabelsVar                 string               // optional string variable receiving the labels via -X as a query string, eg: "main.buildLabels"
+	Manifest                  bool                 // write a JSON sidecar (artifact + ".json") with the labels, hash, size and target of each promoted build
+	FinalNameFunc             NameFunc             // optional versioned file name linked to each promoted artifact, eg: app-v1.4.2+abc1234.exe. OutName+Extension stays the stable entry point
+	KeepArtifacts             int                  // with FinalNameFunc: versioned files kept, older ones are deleted unless a RunAfterBuild/RegisterPID process still maps them. 0 keeps all
+	CancelMode                CancelMode           // what a new request does to the running compilation, defaults to CancelHard
+	Policy                    *Policy              // optional allowlist for CompilingArguments flags and Env vars, checked before spawning the compiler
+	RunAs                     *Credential          // optional (unix only) uid/gid the compiler runs as, eg: drop root privileges
+	Sandbox                   *Sandbox             // optional isolation of the compiler process (linux namespaces, windows job objects)
 }
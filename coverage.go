@@ -0,0 +1,242 @@
+package gobuild
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Coverage builds -cover binaries whose runs write their profiles to Dir (GOCOVERDIR)
+// In a Matrix each target gets its own Dir/<goos>_<goarch> folder, see Matrix.CoverageSummary
+type Coverage struct {
+	Dir      string // GOCOVERDIR of the binary runs, relative to WorkDir
+	Mode     string // -covermode: set, count or atomic. Empty uses the go default
+	Packages string // -coverpkg pattern, eg: ./... Empty covers the main module packages
+}
+
+// CoverageSummary is the statement coverage of one or several GOCOVERDIRs
+type CoverageSummary struct {
+	Statements int               // total statements in the covered packages
+	Covered    int               // statements run at least once
+	Packages   []PackageCoverage // sorted by import path
+}
+
+// PackageCoverage is the statement coverage of one package
+type PackageCoverage struct {
+	Package    string
+	Statements int
+	Covered    int
+}
+
+// Percent returns the covered statements percentage, 0 without statements
+func (s CoverageSummary) Percent() float64 {
+	return percent(s.Covered, s.Statements)
+}
+
+// Percent returns the covered statements percentage, 0 without statements
+func (p PackageCoverage) Percent() float64 {
+	return percent(p.Covered, p.Statements)
+}
+
+func percent(covered, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(covered) / float64(total)
+}
+
+// coverArgs returns the -cover flags of Config.Coverage, unless already in args
+func (h *GoBuild) coverArgs(args []string) []string {
+	c := h.config.Coverage
+	if c == nil || slices.Contains(args, "-cover") {
+		return nil
+	}
+	cover := []string{"-cover"}
+	if c.Mode != "" {
+		cover = append(cover, "-covermode="+c.Mode)
+	}
+	if c.Packages != "" {
+		cover = append(cover, "-coverpkg="+c.Packages)
+	}
+	return cover
+}
+
+// coverageIssues returns the settings Config.Coverage can't build with
+func (h *GoBuild) coverageIssues(add func(field, format string, args ...any)) {
+	c := h.config.Coverage
+	if c.Dir == "" {
+		add("Coverage", "Dir is required, eg: coverage")
+	}
+	switch c.Mode {
+	case "", "set", "count", "atomic":
+	default:
+		add("Coverage", "unknown Mode %q, use set, count or atomic", c.Mode)
+	}
+	if h.tinyGo() {
+		add("Coverage", "not supported by tinygo")
+	}
+}
+
+// CoverDir returns the GOCOVERDIR of Config.Coverage, created if missing
+// Runs of the binary need it in their env, RunAfterBuild apps get it automatically
+// Empty when coverage is off
+func (h *GoBuild) CoverDir() string {
+	if h.config.Coverage == nil || h.config.Coverage.Dir == "" {
+		return ""
+	}
+	dir, err := filepath.Abs(h.resolve(h.config.Coverage.Dir))
+	if err != nil {
+		return ""
+	}
+	os.MkdirAll(dir, 0o755)
+	return dir
+}
+
+// coverageTarget returns the Coverage of a matrix target, with its own Dir
+func coverageTarget(c *Coverage, goos, goarch string) *Coverage {
+	if c == nil {
+		return nil
+	}
+	target := *c
+	target.Dir = filepath.Join(c.Dir, goos+"_"+goarch)
+	return &target
+}
+
+// MergeCoverage merges the GOCOVERDIR of every target into out with go tool covdata
+// eg: to upload one profile from CI, or to read it back with CoverageSummary
+func (m *Matrix) MergeCoverage(ctx context.Context, out string) error {
+	dirs, err := m.coverDirs()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(out, 0o755); err != nil {
+		return err
+	}
+	_, err = m.covdata(ctx, "merge", "-i="+strings.Join(dirs, ","), "-o="+out)
+	return err
+}
+
+// CoverageSummary returns the statement coverage of all the target runs combined
+// A statement counts as covered when any target ran it
+func (m *Matrix) CoverageSummary(ctx context.Context) (CoverageSummary, error) {
+	dirs, err := m.coverDirs()
+	if err != nil {
+		return CoverageSummary{}, err
+	}
+
+	profile, err := os.CreateTemp("", "gobuild-cover-*.txt")
+	if err != nil {
+		return CoverageSummary{}, err
+	}
+	profile.Close()
+	defer os.Remove(profile.Name())
+
+	if _, err := m.covdata(ctx, "textfmt", "-i="+strings.Join(dirs, ","), "-o="+profile.Name()); err != nil {
+		return CoverageSummary{}, err
+	}
+	return readCoverProfile(profile.Name())
+}
+
+// coverDirs returns the GOCOVERDIRs of the targets that already have profiles
+func (m *Matrix) coverDirs() ([]string, error) {
+	var dirs []string
+	for _, gb := range m.list() {
+		dir := gb.CoverDir()
+		if dir == "" {
+			return nil, errors.New("Coverage is not enabled")
+		}
+		if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+			dirs = append(dirs, dir)
+		}
+	}
+	if len(dirs) == 0 {
+		return nil, errors.New("no coverage profiles yet, run the binaries with their CoverDir as GOCOVERDIR")
+	}
+	return dirs, nil
+}
+
+// covdata runs go tool covdata with the first target toolchain and env
+func (m *Matrix) covdata(ctx context.Context, args ...string) ([]byte, error) {
+	gb := m.builders[m.targets[0]]
+	cmd := exec.CommandContext(ctx, gb.goTool(), append([]string{"tool", "covdata"}, args...)...)
+	cmd.Dir = gb.config.WorkDir
+	cmd.Env = gb.maintenanceEnv()
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return output, fmt.Errorf("go tool covdata %s: %v %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return output, nil
+}
+
+// readCoverProfile sums a text coverage profile ("file:12.2,14.3 2 1" lines) per package
+// Blocks repeated by several inputs count once, covered when any of them ran
+func readCoverProfile(name string) (CoverageSummary, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return CoverageSummary{}, err
+	}
+	defer f.Close()
+
+	type block struct {
+		statements int
+		covered    bool
+	}
+	blocks := map[string]*block{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		b := blocks[fields[0]]
+		if b == nil {
+			b = &block{statements: statements}
+			blocks[fields[0]] = b
+		}
+		b.covered = b.covered || count > 0
+	}
+	if err := scanner.Err(); err != nil {
+		return CoverageSummary{}, err
+	}
+
+	var s CoverageSummary
+	packages := map[string]*PackageCoverage{}
+	for pos, b := range blocks {
+		file, _, _ := strings.Cut(pos, ":")
+		pkg := path.Dir(file)
+		p := packages[pkg]
+		if p == nil {
+			p = &PackageCoverage{Package: pkg}
+			packages[pkg] = p
+		}
+		p.Statements += b.statements
+		s.Statements += b.statements
+		if b.covered {
+			p.Covered += b.statements
+			s.Covered += b.statements
+		}
+	}
+	for _, p := range packages {
+		s.Packages = append(s.Packages, *p)
+	}
+	sort.Slice(s.Packages, func(i, j int) bool { return s.Packages[i].Package < s.Packages[j].Package })
+	return s, nil
+}
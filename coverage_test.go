@@ -0,0 +1,90 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestCoverageMatrixSummary(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles with the real toolchain")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	tempDir := t.TempDir()
+	mainGoPath := filepath.Join(tempDir, "main.go")
+	os.WriteFile(mainGoPath, []byte("package main\n\nimport \"os\"\n\nfunc main() {\n\tif len(os.Args) > 1 {\n\t\tprintln(\"flag\")\n\t\treturn\n\t}\n\tprintln(\"plain\")\n}\n"), 0644)
+
+	target := runtime.GOOS + "/" + runtime.GOARCH
+	m, err := NewMatrix(Config{
+		Command:                   "go",
+		MainInputFileRelativePath: mainGoPath,
+		OutName:                   "app",
+		OutFolderRelativePath:     filepath.Join(tempDir, "dist"),
+		Coverage:                  &Coverage{Dir: filepath.Join(tempDir, "coverage")},
+		Timeout:                   60 * time.Second,
+	}, []string{target}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Compile(context.Background()); err != nil {
+		t.Fatalf("Compilation failed: %v", err)
+	}
+
+	gb := m.Builder(target)
+	if want := filepath.Join(tempDir, "coverage", runtime.GOOS+"_"+runtime.GOARCH); gb.CoverDir() != want {
+		t.Errorf("Expected the target GOCOVERDIR %s, got %s", want, gb.CoverDir())
+	}
+	run := exec.Command(gb.FinalOutputPath())
+	run.Env = append(os.Environ(), "GOCOVERDIR="+gb.CoverDir())
+	if out, err := run.CombinedOutput(); err != nil {
+		t.Fatalf("Run failed: %v %s", err, out)
+	}
+
+	s, err := m.CoverageSummary(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Statements == 0 || s.Covered == 0 || s.Covered == s.Statements || len(s.Packages) != 1 {
+		t.Errorf("Expected partial coverage of one package, got %+v", s)
+	}
+
+	merged := filepath.Join(tempDir, "merged")
+	if err := m.MergeCoverage(context.Background(), merged); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(merged); len(entries) == 0 {
+		t.Error("Expected merged profile files")
+	}
+}
+
+func TestReadCoverProfile(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "cover.txt")
+	os.WriteFile(profile, []byte(`mode: set
+example.com/app/main.go:5.13,7.2 2 1
+example.com/app/main.go:7.2,9.3 3 0
+example.com/app/main.go:7.2,9.3 3 1
+example.com/app/lib/lib.go:3.20,5.2 4 0
+`), 0644)
+
+	s, err := readCoverProfile(profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Statements != 9 || s.Covered != 5 || len(s.Packages) != 2 {
+		t.Fatalf("Unexpected summary %+v", s)
+	}
+	if p := s.Packages[0]; p.Package != "example.com/app" || p.Percent() != 100 {
+		t.Errorf("Expected example.com/app fully covered (a block run by any target), got %+v", p)
+	}
+	if p := s.Packages[1]; p.Package != "example.com/app/lib" || p.Covered != 0 {
+		t.Errorf("Expected example.com/app/lib uncovered, got %+v", p)
+	}
+}
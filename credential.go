@@ -0,0 +1,9 @@
+package gobuild
+
+// Credential identifies the user and group the compiler process runs as
+// Only supported on Unix, eg: a root-running dev daemon building tenant code
+type Credential struct {
+	Uid    uint32   // user id, eg: 1000
+	Gid    uint32   // primary group id, eg: 1000
+	Groups []uint32 // optional supplementary group ids
+}
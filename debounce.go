@@ -0,0 +1,77 @@
+package gobuild
+
+import "time"
+
+// debounce holds comp until Config.Debounce passed without a new request, see StartWith
+// Requests arriving meanwhile return the held build instead of a new one, so a burst of
+// saves becomes a single build. Config.DebounceMaxWait bounds the delay of a long burst
+func (h *GoBuild) debounce(opts BuildOptions) *Build {
+	h.mu.Lock()
+	if comp := h.debounced; comp != nil {
+		h.debounceTimer.Reset(h.debounceDelay())
+		h.mu.Unlock()
+		return comp
+	}
+	h.mu.Unlock()
+
+	comp := h.newCompilation()
+	comp.label = opts.Label
+	if comp.label == "" {
+		comp.label = h.config.Label
+	}
+	comp.priority = opts.Priority
+	comp.salted = h.config.BuildSalt
+	comp.labels = h.buildLabels(nil)
+	comp.traceCtx = opts.TraceContext
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if held := h.debounced; held != nil {
+		// Lost a race with another request, join the build it holds
+		h.debounceTimer.Reset(h.debounceDelay())
+		comp.cancel(ErrSuperseded)
+		return held
+	}
+	h.debounced = comp
+	h.debounceSince = h.now()
+	h.debounceTimer = h.clock().AfterFunc(h.config.Debounce, func() { h.releaseDebounced(comp) })
+	return comp
+}
+
+// debounceDelay returns how long the held build waits after a new request
+// Must be called with h.mu held
+func (h *GoBuild) debounceDelay() time.Duration {
+	delay := h.config.Debounce
+	if maxWait := h.config.DebounceMaxWait; maxWait > 0 {
+		if left := maxWait - h.now().Sub(h.debounceSince); left < delay {
+			delay = max(left, 0)
+		}
+	}
+	return delay
+}
+
+// releaseDebounced submits comp once the quiet period is over
+// A timer reset after comp was already released finds another (or no) build held and does nothing
+func (h *GoBuild) releaseDebounced(comp *Build) {
+	h.mu.Lock()
+	if h.debounced != comp {
+		h.mu.Unlock()
+		return
+	}
+	h.debounced = nil
+	h.mu.Unlock()
+
+	h.submit(comp)
+}
+
+// undebounce removes the held build if its id matches and returns it, nil otherwise
+// Must be called with h.mu held
+func (h *GoBuild) undebounce(id uint64) *Build {
+	comp := h.debounced
+	if comp == nil || comp.ID != id {
+		return nil
+	}
+	h.debounceTimer.Stop()
+	h.debounced = nil
+	return comp
+}
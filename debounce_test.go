@@ -0,0 +1,94 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDebounceBuilder(t *testing.T, debounce, maxWait time.Duration) (*GoBuild, string) {
+	t.Helper()
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	return New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo run >> "+runs+"; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Debounce:                  debounce,
+		DebounceMaxWait:           maxWait,
+	}), runs
+}
+
+func countRuns(t *testing.T, runs string) int {
+	t.Helper()
+	data, err := os.ReadFile(runs)
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "run")
+}
+
+func TestDebounceCoalescesBurst(t *testing.T) {
+	gb, runs := newDebounceBuilder(t, 100*time.Millisecond, 0)
+
+	first := gb.Start()
+	for i := 0; i < 4; i++ {
+		time.Sleep(10 * time.Millisecond)
+		if b := gb.Start(); b != first {
+			t.Fatalf("Expected request %d to join build %d, got %d", i+2, first.ID, b.ID)
+		}
+	}
+	if gb.IsCompiling() {
+		t.Error("Build started before the quiet period")
+	}
+
+	if err := first.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if n := countRuns(t, runs); n != 1 {
+		t.Errorf("Expected 1 compiler run, got %d", n)
+	}
+
+	if next := gb.Start(); next == first {
+		t.Error("Expected a new build after the burst")
+	}
+}
+
+func TestDebounceMaxWait(t *testing.T) {
+	gb, _ := newDebounceBuilder(t, 100*time.Millisecond, 150*time.Millisecond)
+
+	first := gb.Start()
+	start := time.Now()
+	for time.Since(start) < 400*time.Millisecond {
+		gb.Start()
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	select {
+	case <-first.Done():
+	default:
+		t.Fatal("Expected DebounceMaxWait to start the build during the burst")
+	}
+	if err := first.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+}
+
+func TestDebounceCancel(t *testing.T) {
+	gb, runs := newDebounceBuilder(t, 50*time.Millisecond, 0)
+
+	b := gb.Start()
+	b.Cancel()
+	if err := b.Wait(); !errors.Is(err, ErrCancelled) {
+		t.Fatalf("Expected ErrCancelled, got %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if n := countRuns(t, runs); n != 0 {
+		t.Errorf("Expected the cancelled build not to run, got %d runs", n)
+	}
+}
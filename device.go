@@ -0,0 +1,70 @@
+package gobuild
+
+import (
+	"sort"
+	"strings"
+)
+
+// DeviceProfile is a named target expanding into the GOOS/GOARCH/GOARM settings
+// and the file extension of a device, see Config.Device
+type DeviceProfile struct {
+	GOOS      string
+	GOARCH    string
+	GOARM     string   // 32 bit arm floating point variant, eg: "6" for the Pi Zero
+	Extension string   // used when Config.Extension is empty, eg: ".exe"
+	Env       []string // extra variables, eg: "CGO_ENABLED=0", "GOAMD64=v3"
+}
+
+// DeviceProfiles lists the profiles available to Config.Device
+// Add entries before calling New for in-house hardware
+var DeviceProfiles = map[string]DeviceProfile{
+	"raspberry-pi-zero":    {GOOS: "linux", GOARCH: "arm", GOARM: "6"},
+	"raspberry-pi-3":       {GOOS: "linux", GOARCH: "arm", GOARM: "7"},
+	"raspberry-pi-4":       {GOOS: "linux", GOARCH: "arm64"},
+	"raspberry-pi-5":       {GOOS: "linux", GOARCH: "arm64"},
+	"beaglebone":           {GOOS: "linux", GOARCH: "arm", GOARM: "7"},
+	"jetson-nano":          {GOOS: "linux", GOARCH: "arm64"},
+	"generic-amd64-server": {GOOS: "linux", GOARCH: "amd64", Env: []string{"CGO_ENABLED=0"}},
+	"generic-arm64-server": {GOOS: "linux", GOARCH: "arm64", Env: []string{"CGO_ENABLED=0"}},
+	"windows-amd64":        {GOOS: "windows", GOARCH: "amd64", Extension: ".exe"},
+}
+
+// env returns the variables of the profile, placed before the user env
+func (d DeviceProfile) env() []string {
+	env := []string{"GOOS=" + d.GOOS, "GOARCH=" + d.GOARCH}
+	if d.GOARM != "" {
+		env = append(env, "GOARM="+d.GOARM)
+	}
+	return append(env, d.Env...)
+}
+
+// deviceEnv returns the env of Config.Device, nil without one or for an unknown name (see Validate)
+func (h *GoBuild) deviceEnv() []string {
+	if h.config.Device == "" {
+		return nil
+	}
+	d, ok := DeviceProfiles[h.config.Device]
+	if !ok {
+		return nil
+	}
+	return d.env()
+}
+
+// deviceIssues returns the settings Config.Device can't build with
+func (h *GoBuild) deviceIssues(add func(field, format string, args ...any)) {
+	c := h.config
+	if _, ok := DeviceProfiles[c.Device]; !ok {
+		names := make([]string, 0, len(DeviceProfiles))
+		for name := range DeviceProfiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		add("Device", "unknown profile %q, available: %s", c.Device, strings.Join(names, ", "))
+	}
+	if c.TargetWASM {
+		add("Device", "can't be combined with TargetWASM")
+	}
+	if c.Mobile != nil {
+		add("Device", "can't be combined with Mobile")
+	}
+}
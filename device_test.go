@@ -0,0 +1,45 @@
+package gobuild
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDeviceProfile(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Device:                    "raspberry-pi-zero",
+		Env:                       []string{"CGO_ENABLED=0"},
+	})
+	env, err := gb.userEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"GOOS=linux", "GOARCH=arm", "GOARM=6", "CGO_ENABLED=0"}; !slices.Equal(env, want) {
+		t.Errorf("Expected %v, got %v", want, env)
+	}
+	if goos, goarch := gb.target(); goos != "linux" || goarch != "arm" {
+		t.Errorf("Expected linux/arm, got %s/%s", goos, goarch)
+	}
+
+	win := New(&Config{Device: "windows-amd64", OutName: "app"})
+	if win.MainOutputFileNameWithExtension() != "app.exe" {
+		t.Errorf("Expected the profile extension, got %q", win.MainOutputFileNameWithExtension())
+	}
+}
+
+func TestDeviceProfileUnknown(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Device:                    "raspberry-pi-9",
+	})
+	err := gb.Validate()
+	if err == nil || !strings.Contains(err.Error(), `unknown profile "raspberry-pi-9"`) || !strings.Contains(err.Error(), "raspberry-pi-4") {
+		t.Errorf("Expected the unknown profile listed with the available ones, got %v", err)
+	}
+}
@@ -0,0 +1,157 @@
+package gobuild
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Severity classifies a Diagnostic
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // made the build fail
+	SeverityWarning Severity = "warning" // eg: cgo C compiler warnings, the build still succeeds
+	SeverityNote    Severity = "note"    // informative, eg: "go: downloading ..."
+)
+
+// Diagnostic is one message parsed from the compiler output
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Package  string   `json:"package,omitempty"` // from the "# pkg" header preceding the message, eg: "runtime/cgo"
+	File     string   `json:"file,omitempty"`    // empty for messages without a position
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Message  string   `json:"message"`
+	Repeated int      `json:"repeated,omitempty"` // with Config.DedupeDiagnostics: >0 on the summary streamed instead of that many diagnostics identical to the previous build's
+}
+
+// diagnosticPosition matches "file.go:12:5: msg" and "file.c:3: msg" (column optional)
+var diagnosticPosition = regexp.MustCompile(`^(.+?\.[A-Za-z]+):(\d+)(?::(\d+))?: (.*)$`)
+
+// parseDiagnostics splits the compiler output into diagnostics
+// Positioned messages are errors when the build failed unless the toolchain labels
+// them "warning:"/"note:", non fatal output of a successful build is never an error
+func parseDiagnostics(output string, failed bool) []Diagnostic {
+	var diags []Diagnostic
+	pkg := ""
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if p, ok := strings.CutPrefix(line, "# "); ok {
+			pkg = p
+			continue
+		}
+
+		d := Diagnostic{Package: pkg, Message: strings.TrimSpace(line)}
+		if m := diagnosticPosition.FindStringSubmatch(line); m != nil {
+			d.File = m[1]
+			d.Line, _ = strconv.Atoi(m[2])
+			d.Column, _ = strconv.Atoi(m[3])
+			d.Message = m[4]
+		}
+		d.Severity, d.Message = classifyDiagnostic(d.Message, failed && d.File != "")
+		diags = append(diags, d)
+	}
+	return diags
+}
+
+// classifyDiagnostic returns the severity of msg and msg without its severity label
+func classifyDiagnostic(msg string, positionedFailure bool) (Severity, string) {
+	switch {
+	case strings.HasPrefix(msg, "warning: "):
+		return SeverityWarning, strings.TrimPrefix(msg, "warning: ")
+	case strings.HasPrefix(msg, "note: "):
+		return SeverityNote, strings.TrimPrefix(msg, "note: ")
+	case strings.HasPrefix(msg, "error: "):
+		return SeverityError, strings.TrimPrefix(msg, "error: ")
+	case positionedFailure:
+		return SeverityError, msg
+	case strings.HasPrefix(msg, "go: downloading "), strings.HasPrefix(msg, "go: finding "):
+		return SeverityNote, msg
+	}
+	return SeverityWarning, msg
+}
+
+// reportDiagnostics parses the compiler output, keeps it on the build and
+// streams it to Config.OnDiagnostic
+// With Config.DedupeDiagnostics a build repeating the previous build's diagnostics
+// streams a single summary instead, eg: "same 3 errors as previous build"
+func (h *GoBuild) reportDiagnostics(comp *Build, output []byte, failed bool) {
+	if len(output) > 0 {
+		comp.diags = parseDiagnostics(h.decodeOutput(output), failed)
+	}
+
+	key := diagnosticsKey(comp.diags)
+	h.mu.Lock()
+	repeated := key != "" && key == h.lastDiags
+	h.lastDiags = key
+	h.mu.Unlock()
+
+	if h.config.OnDiagnostic == nil {
+		return
+	}
+	if repeated && h.config.DedupeDiagnostics {
+		h.config.OnDiagnostic(repeatSummary(comp.diags))
+		return
+	}
+	for _, d := range comp.diags {
+		h.config.OnDiagnostic(d)
+	}
+}
+
+// diagnosticsKey identifies a set of diagnostics, empty when there are none
+func diagnosticsKey(diags []Diagnostic) string {
+	var b strings.Builder
+	for _, d := range diags {
+		fmt.Fprintf(&b, "%s|%s|%s|%d|%d|%s\n", d.Severity, d.Package, d.File, d.Line, d.Column, d.Message)
+	}
+	return b.String()
+}
+
+// repeatSummary returns the diagnostic streamed instead of a repeated set
+// It carries the most severe severity of the set
+func repeatSummary(diags []Diagnostic) Diagnostic {
+	severity, noun := SeverityNote, "notes"
+	errs, warnings := 0, 0
+	for _, d := range diags {
+		switch d.Severity {
+		case SeverityError:
+			errs++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	switch {
+	case errs == len(diags):
+		severity, noun = SeverityError, "errors"
+	case errs > 0:
+		severity, noun = SeverityError, "diagnostics"
+	case warnings == len(diags):
+		severity, noun = SeverityWarning, "warnings"
+	case warnings > 0:
+		severity, noun = SeverityWarning, "diagnostics"
+	}
+	if len(diags) == 1 {
+		noun = strings.TrimSuffix(noun, "s")
+	}
+	return Diagnostic{
+		Severity: severity,
+		Message:  fmt.Sprintf("same %d %s as previous build", len(diags), noun),
+		Repeated: len(diags),
+	}
+}
+
+// Warnings returns the diagnostics that are not errors
+func (r *BuildResult) Warnings() []Diagnostic {
+	var warnings []Diagnostic
+	for _, d := range r.Diagnostics {
+		if d.Severity != SeverityError {
+			warnings = append(warnings, d)
+		}
+	}
+	return warnings
+}
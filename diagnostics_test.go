@@ -0,0 +1,97 @@
+package gobuild
+
+import (
+	"testing"
+)
+
+func TestParseDiagnostics(t *testing.T) {
+	output := "go: downloading example.com/lib v1.0.0\n" +
+		"# example.com/app\n" +
+		"./main.go:12:5: undefined: foo\r\n" +
+		"./cgo.c:3:2: warning: unused variable 'x'\n" +
+		"./cgo.c:3: note: declared here\n"
+
+	diags := parseDiagnostics(output, true)
+	expected := []Diagnostic{
+		{Severity: SeverityNote, Message: "go: downloading example.com/lib v1.0.0"},
+		{Severity: SeverityError, Package: "example.com/app", File: "./main.go", Line: 12, Column: 5, Message: "undefined: foo"},
+		{Severity: SeverityWarning, Package: "example.com/app", File: "./cgo.c", Line: 3, Column: 2, Message: "unused variable 'x'"},
+		{Severity: SeverityNote, Package: "example.com/app", File: "./cgo.c", Line: 3, Message: "declared here"},
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("Expected %d diagnostics, got %d: %+v", len(expected), len(diags), diags)
+	}
+	for i := range expected {
+		if diags[i] != expected[i] {
+			t.Errorf("Diagnostic %d: expected %+v, got %+v", i, expected[i], diags[i])
+		}
+	}
+
+	// the same positioned message after a successful build is not an error
+	if d := parseDiagnostics("./main.go:1:1: something odd", false); d[0].Severity != SeverityWarning {
+		t.Errorf("Expected warning on success, got %s", d[0].Severity)
+	}
+}
+
+func TestWarningsDoNotFailBuild(t *testing.T) {
+	dir := t.TempDir()
+	var streamed []Diagnostic
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo '# runtime/cgo' >&2; echo 'gcc.c:1:1: warning: deprecated' >&2; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		OnDiagnostic:              func(d Diagnostic) { streamed = append(streamed, d) },
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Warnings must not fail the build: %v", err)
+	}
+	warnings := b.Result().Warnings()
+	if len(warnings) != 1 || warnings[0].Package != "runtime/cgo" || warnings[0].Message != "deprecated" {
+		t.Errorf("Expected one cgo warning, got %+v", warnings)
+	}
+	if len(streamed) != 1 {
+		t.Errorf("Expected the warning streamed to OnDiagnostic, got %+v", streamed)
+	}
+}
+
+func TestDedupeDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	var streamed []Diagnostic
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "printf './main.go:3:1: undefined: a\\n./main.go:4:1: undefined: b\\n' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		DedupeDiagnostics:         true,
+		OnDiagnostic:              func(d Diagnostic) { streamed = append(streamed, d) },
+	})
+
+	gb.CompileProgram()
+	if len(streamed) != 2 {
+		t.Fatalf("Expected both errors streamed on the first build, got %+v", streamed)
+	}
+
+	streamed = nil
+	b := gb.Start()
+	b.Wait()
+	if len(streamed) != 1 || streamed[0].Repeated != 2 || streamed[0].Message != "same 2 errors as previous build" {
+		t.Errorf("Expected a single repeat summary, got %+v", streamed)
+	}
+	if len(b.Result().Diagnostics) != 2 {
+		t.Errorf("The result keeps every diagnostic, got %+v", b.Result().Diagnostics)
+	}
+}
+
+func TestRepeatSummary(t *testing.T) {
+	one := repeatSummary([]Diagnostic{{Severity: SeverityWarning}})
+	if one.Message != "same 1 warning as previous build" || one.Severity != SeverityWarning {
+		t.Errorf("Unexpected summary %+v", one)
+	}
+	mixed := repeatSummary([]Diagnostic{{Severity: SeverityError}, {Severity: SeverityWarning}})
+	if mixed.Message != "same 2 diagnostics as previous build" || mixed.Severity != SeverityError {
+		t.Errorf("Unexpected summary %+v", mixed)
+	}
+}
@@ -0,0 +1,42 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+)
+
+// DryRun returns the compiler process a build started now would run: executable,
+// arguments, env and working directory, without spawning it or touching the output folder
+// The temp output name is the fixed one of BuildArguments. Env is nil when the host env
+// is inherited as is. SecretStamps are resolved, so a failing Secrets source shows up, but their
+// generated -overlay is not written and its path reads "***": no secret value is returned
+// Builds delegated to an Agent have no local process and return an error
+func (h *GoBuild) DryRun() (RunRequest, error) {
+	if h.config.Agent != nil {
+		return RunRequest{}, errors.New("DryRun: builds are delegated to an Agent")
+	}
+
+	comp := &Build{
+		gb:       h,
+		exitCode: -1,
+		tempFile: h.outTempFileName,
+		label:    h.config.Label,
+		salted:   h.config.BuildSalt,
+		labels:   h.buildLabels(nil),
+	}
+	userArgs, userEnv, err := h.resolveBuild(context.Background(), comp)
+	if err != nil {
+		return RunRequest{}, err
+	}
+
+	buildArgs := h.buildArgumentsFrom(userArgs, comp.tempFile)
+	if len(h.config.SecretStamps) > 0 {
+		files, err := h.secretFiles(context.Background(), userEnv)
+		if err != nil {
+			return RunRequest{}, err
+		}
+		buildArgs = h.withSecretOverlay(buildArgs, redactedSecret, files)
+	}
+	name, args := h.commandLine(buildArgs)
+	return RunRequest{Name: name, Args: args, Dir: h.config.WorkDir, Env: h.environment(userEnv)}, nil
+}
@@ -0,0 +1,73 @@
+package gobuild
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDryRun(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		CommandLine:               []string{"nix", "develop", "-c", "go"},
+		MainInputFileRelativePath: "cmd/main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "dist",
+		WorkDir:                   dir,
+		Env:                       []string{"GOOS=linux"},
+		CompilingArguments:        func() []string { return []string{"-trimpath", "-X", "main.version=1.0"} },
+	})
+
+	req, err := gb.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"develop", "-c", "go", "build", "-trimpath", "-ldflags=-X main.version=1.0", "-o", filepath.Join("dist", "app_temp"), filepath.Join("cmd", "main.go")}
+	if req.Name != "nix" || !slices.Equal(req.Args, want) {
+		t.Errorf("Unexpected command %s %q", req.Name, req.Args)
+	}
+	if req.Dir != dir || req.Env[len(req.Env)-1] != "GOOS=linux" {
+		t.Errorf("Unexpected dir %q or env tail %q", req.Dir, req.Env[len(req.Env)-1])
+	}
+	if entries, _ := filepath.Glob(filepath.Join(dir, "*")); len(entries) != 0 {
+		t.Errorf("Expected nothing created in WorkDir, found %v", entries)
+	}
+
+	gb.config.OutName = ""
+	if _, err := gb.DryRun(); err == nil || !strings.Contains(err.Error(), "OutName") {
+		t.Errorf("Expected the validation error, got %v", err)
+	}
+}
+
+func TestDryRunRedactsSecrets(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		WorkDir:                   dir,
+		Env:                       []string{"GOOS=linux"},
+		Secrets:                   SecretFunc(func(string) (string, error) { return "hunter2", nil }),
+		SecretStamps:              map[string]string{"main.apiKey": "API_KEY"},
+	})
+
+	req, err := gb.DryRun()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range append(append([]string{req.Name}, req.Args...), req.Env...) {
+		if strings.Contains(s, "hunter2") {
+			t.Errorf("Secret value returned in %q", s)
+		}
+	}
+	if !slices.Contains(req.Args, "-overlay=***") {
+		t.Errorf("Expected the redacted overlay, got %q", req.Args)
+	}
+
+	gb.config.Secrets = SecretFunc(func(string) (string, error) { return "", errors.New("vault sealed") })
+	if _, err := gb.DryRun(); err == nil {
+		t.Error("Expected the Secrets source error")
+	}
+}
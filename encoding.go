@@ -0,0 +1,72 @@
+package gobuild
+
+import (
+	"bytes"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// OutputDecoder converts raw compiler output to a UTF-8 string
+// eg: a code page decoder for localized cgo toolchains (CP936, CP1251...)
+type OutputDecoder func([]byte) string
+
+// decodeOutput returns the compiler output as valid UTF-8 text
+// UTF-8 is kept as is, UTF-16 (BOM or NUL interleaved) is decoded and anything
+// else is read as Windows-1252, the usual code page of localized MSVC/MinGW
+// Config.OutputDecoder overrides the detection for invalid UTF-8 output
+func (h *GoBuild) decodeOutput(raw []byte) string {
+	if utf8.Valid(raw) && !looksUTF16(raw) {
+		return string(raw)
+	}
+	if h.config.OutputDecoder != nil {
+		return h.config.OutputDecoder(raw)
+	}
+	if looksUTF16(raw) {
+		return decodeUTF16LE(raw)
+	}
+	return decodeWindows1252(raw)
+}
+
+// looksUTF16 detects little endian UTF-16 by BOM or by NUL high bytes in ASCII text
+func looksUTF16(b []byte) bool {
+	if bytes.HasPrefix(b, []byte{0xFF, 0xFE}) {
+		return true
+	}
+	if len(b) < 4 || len(b)%2 != 0 {
+		return false
+	}
+	zeros := 0
+	for i := 1; i < len(b); i += 2 {
+		if b[i] == 0 {
+			zeros++
+		}
+	}
+	return zeros > len(b)/4 // more than half of the code units are ASCII
+}
+
+func decodeUTF16LE(b []byte) string {
+	b = bytes.TrimPrefix(b, []byte{0xFF, 0xFE})
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])|uint16(b[i+1])<<8)
+	}
+	return string(utf16.Decode(units))
+}
+
+// windows1252 maps the 0x80-0x9F range, the rest of the code page matches Latin-1
+var windows1252 = [32]rune{
+	'€', '�', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '�', 'Ž', '�',
+	'�', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '�', 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(b []byte) string {
+	runes := make([]rune, 0, len(b))
+	for _, c := range b {
+		if c >= 0x80 && c <= 0x9F {
+			runes = append(runes, windows1252[c-0x80])
+		} else {
+			runes = append(runes, rune(c))
+		}
+	}
+	return string(runes)
+}
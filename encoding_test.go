@@ -0,0 +1,37 @@
+package gobuild
+
+import "testing"
+
+func TestDecodeOutput(t *testing.T) {
+	gb := New(&Config{})
+
+	tests := []struct {
+		name     string
+		raw      []byte
+		expected string
+	}{
+		{"utf8 unchanged", []byte("main.go:3: año inválido"), "main.go:3: año inválido"},
+		{"windows-1252", []byte("error: s\xedmbolo \x93x\x94 no definido"), "error: símbolo “x” no definido"},
+		{"utf16 with bom", []byte{0xFF, 0xFE, 'o', 0, 'k', 0, 0xE9, 0}, "oké"},
+		{"utf16 without bom", []byte{'e', 0, 'r', 0, 'r', 0, 0xF1, 0}, "errñ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := gb.decodeOutput(tt.raw); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestDecodeOutputCustomDecoder(t *testing.T) {
+	gb := New(&Config{OutputDecoder: func(b []byte) string { return "custom" }})
+
+	if got := gb.decodeOutput([]byte("valid")); got != "valid" {
+		t.Errorf("Valid UTF-8 should not use the custom decoder, got %q", got)
+	}
+	if got := gb.decodeOutput([]byte{0xFF, 0x80}); got != "custom" {
+		t.Errorf("Expected custom decoder output, got %q", got)
+	}
+}
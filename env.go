@@ -0,0 +1,35 @@
+package gobuild
+
+import (
+	"strings"
+)
+
+// isolatedEnvAllowlist lists the host variables kept with Config.IsolateEnv
+// The windows entries are required by the go toolchain to locate its caches
+var isolatedEnvAllowlist = []string{
+	"HOME", "PATH", "GO*", "TMPDIR",
+	"SystemRoot", "TEMP", "TMP", "USERPROFILE", "LOCALAPPDATA", "APPDATA",
+}
+
+// environment returns the env of the compiler process, nil inherits the host env
+// With Config.IsolateEnv the host env is reduced to the allowlist (plus EnvAllowlist)
+// userEnv entries (EnvFiles + Env) are always appended last so they take precedence
+func (h *GoBuild) environment(userEnv []string) []string {
+	if !h.config.IsolateEnv {
+		if len(userEnv) == 0 && !h.hasUnsetEnv() {
+			return nil
+		}
+		return append(h.hostEnv(), userEnv...)
+	}
+
+	allow := append(append([]string{}, isolatedEnvAllowlist...), h.config.EnvAllowlist...)
+
+	env := []string{}
+	for _, entry := range h.hostEnv() {
+		key, _, _ := strings.Cut(entry, "=")
+		if matchAny(allow, key) {
+			env = append(env, entry)
+		}
+	}
+	return append(env, userEnv...)
+}
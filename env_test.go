@@ -0,0 +1,38 @@
+package gobuild
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnvironmentInheritsByDefault(t *testing.T) {
+	if env := New(&Config{}).environment(nil); env != nil {
+		t.Errorf("Expected nil env (inherit host), got %d entries", len(env))
+	}
+
+	t.Setenv("STRAY_VAR", "1")
+	env := New(&Config{}).environment([]string{"GOOS=js"})
+	if !slices.Contains(env, "STRAY_VAR=1") || env[len(env)-1] != "GOOS=js" {
+		t.Error("Expected host env with Config.Env appended last")
+	}
+}
+
+func TestEnvironmentIsolated(t *testing.T) {
+	t.Setenv("STRAY_VAR", "1")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("CC", "clang")
+
+	env := New(&Config{
+		IsolateEnv:   true,
+		EnvAllowlist: []string{"CC"},
+	}).environment([]string{"GOOS=js", "GOARCH=wasm"})
+
+	if slices.Contains(env, "STRAY_VAR=1") {
+		t.Error("Stray variables should not reach an isolated build")
+	}
+	for _, expected := range []string{"GOFLAGS=-mod=mod", "CC=clang", "GOOS=js", "GOARCH=wasm"} {
+		if !slices.Contains(env, expected) {
+			t.Errorf("Expected %s in isolated env", expected)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package gobuild
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// userEnv returns the variables configured for the build: the TargetWASM or Device GOOS/GOARCH,
+// every Config.EnvFiles entry in order, Config.Env and the Experiments/GoDebug settings,
+// so later definitions take precedence. SetEnv/UnsetEnv apply last
+// The files are read again on every compile
+func (h *GoBuild) userEnv() ([]string, error) {
+	env, err := h.configEnv()
+	if err != nil {
+		return nil, err
+	}
+	return h.applyEnvOverrides(env), nil
+}
+
+// configEnv returns the variables of userEnv coming from the Config
+func (h *GoBuild) configEnv() ([]string, error) {
+	env := append(h.wasmEnv(), h.deviceEnv()...)
+	if len(h.config.EnvFiles) == 0 {
+		experiments := h.experimentEnv()
+		if len(env) == 0 && len(experiments) == 0 {
+			return h.config.Env, nil
+		}
+		return append(append(env, h.config.Env...), experiments...), nil
+	}
+
+	vars := map[string]string{}
+	for _, file := range h.config.EnvFiles {
+		entries, err := parseEnvFile(h.resolve(file), vars)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, entries...)
+	}
+	env = append(env, h.config.Env...)
+	return append(env, h.experimentEnv()...), nil
+}
+
+// parseEnvFile reads KEY=VALUE lines from a dotenv file
+// Supports comments, "export " prefixes, single quotes (literal), double quotes
+// (escapes) and ${VAR}/$VAR interpolation from vars defined so far or the host env
+// Every parsed variable is also stored in vars for the next lines and files
+func parseEnvFile(path string, vars map[string]string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Join(errors.New("parseEnvFile"), err)
+	}
+	defer f.Close()
+
+	lookup := func(key string) string {
+		if v, ok := vars[key]; ok {
+			return v
+		}
+		return os.Getenv(key)
+	}
+
+	var env []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("parseEnvFile %s:%d: expected KEY=VALUE", path, n)
+		}
+
+		value, err := envValue(strings.TrimSpace(raw), lookup)
+		if err != nil {
+			return nil, fmt.Errorf("parseEnvFile %s:%d: %w", path, n, err)
+		}
+
+		vars[key] = value
+		env = append(env, key+"="+value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Join(errors.New("parseEnvFile"), err)
+	}
+	return env, nil
+}
+
+// envValue unquotes and interpolates a raw dotenv value
+func envValue(raw string, lookup func(string) string) (string, error) {
+	switch {
+	case strings.HasPrefix(raw, "'"):
+		end := strings.Index(raw[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+		return raw[1 : end+1], nil
+
+	case strings.HasPrefix(raw, `"`):
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			if c == '"' {
+				return os.Expand(b.String(), lookup), nil
+			}
+			if c == '\\' && i+1 < len(raw) {
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+				continue
+			}
+			b.WriteByte(c)
+		}
+		return "", errors.New("unterminated double quote")
+
+	default:
+		// inline comments need a space before the #
+		if i := strings.Index(raw, " #"); i >= 0 {
+			raw = strings.TrimSpace(raw[:i])
+		}
+		return os.Expand(raw, lookup), nil
+	}
+}
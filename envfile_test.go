@@ -0,0 +1,66 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUserEnvFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOST_USER", "ana")
+
+	os.WriteFile(filepath.Join(dir, ".env"), []byte(`# base settings
+export GOOS=linux
+API_URL=https://api.example.com
+GREETING="hello ${HOST_USER}\tbye"
+LITERAL='$NOT_EXPANDED'
+NAME=app # inline comment
+`), 0644)
+	os.WriteFile(filepath.Join(dir, ".env.local"), []byte(`GOOS=js
+FULL_URL=${API_URL}/v1
+`), 0644)
+
+	gb := New(&Config{
+		WorkDir:  dir,
+		EnvFiles: []string{".env", ".env.local"},
+		Env:      []string{"GOARCH=wasm"},
+	})
+
+	env, err := gb.userEnv()
+	if err != nil {
+		t.Fatalf("userEnv failed: %v", err)
+	}
+
+	expected := []string{
+		"GOOS=linux",
+		"API_URL=https://api.example.com",
+		"GREETING=hello ana\tbye",
+		"LITERAL=$NOT_EXPANDED",
+		"NAME=app",
+		"GOOS=js",
+		"FULL_URL=https://api.example.com/v1",
+		"GOARCH=wasm",
+	}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %q, got %q", expected, env)
+	}
+}
+
+func TestUserEnvMissingFile(t *testing.T) {
+	gb := New(&Config{EnvFiles: []string{filepath.Join(t.TempDir(), "missing.env")}})
+	if _, err := gb.userEnv(); err == nil {
+		t.Error("Expected error for a missing env file")
+	}
+}
+
+func TestUserEnvInvalidLine(t *testing.T) {
+	file := filepath.Join(t.TempDir(), ".env")
+	os.WriteFile(file, []byte("NOT A VALID LINE\n"), 0644)
+
+	gb := New(&Config{EnvFiles: []string{file}})
+	if _, err := gb.userEnv(); err == nil {
+		t.Error("Expected error for an invalid line")
+	}
+}
@@ -0,0 +1,115 @@
+package gobuild
+
+import (
+	"os"
+	"sort"
+	"strings"
+)
+
+// SetEnv sets key=value for the following builds, overriding Config.Env and EnvFiles
+// Safe to call while builds run, unlike mutating Config.Env after New
+func (h *GoBuild) SetEnv(key, value string) {
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	h.overrideEnv(key, &value)
+}
+
+// UnsetEnv removes key from the env of the following builds, whether it comes from
+// Config.Env, EnvFiles, a previous SetEnv or the host env
+func (h *GoBuild) UnsetEnv(key string) {
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	h.overrideEnv(key, nil)
+}
+
+// WithEnv sets every variable of vars at once (see SetEnv) and returns h for chaining
+// eg: gobuild.New(c).WithEnv(map[string]string{"GOOS": "linux", "GOARCH": "arm64"})
+func (h *GoBuild) WithEnv(vars map[string]string) *GoBuild {
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	for key, value := range vars {
+		h.overrideEnv(key, &value)
+	}
+	return h
+}
+
+// overrideEnv records a SetEnv value, nil for UnsetEnv
+// Must be called with h.envMu held
+func (h *GoBuild) overrideEnv(key string, value *string) {
+	if h.envOverrides == nil {
+		h.envOverrides = map[string]*string{}
+	}
+	h.envOverrides[key] = value
+}
+
+// applyEnvOverrides drops the entries of env replaced or removed by SetEnv/UnsetEnv
+// and appends the SetEnv values sorted by key, env is returned as is without overrides
+func (h *GoBuild) applyEnvOverrides(env []string) []string {
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	if len(h.envOverrides) == 0 {
+		return env
+	}
+
+	out := make([]string, 0, len(env)+len(h.envOverrides))
+	for _, entry := range env {
+		key, _, _ := strings.Cut(entry, "=")
+		if _, ok := h.envOverrides[key]; !ok {
+			out = append(out, entry)
+		}
+	}
+	keys := make([]string, 0, len(h.envOverrides))
+	for key, value := range h.envOverrides {
+		if value != nil {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out = append(out, key+"="+*h.envOverrides[key])
+	}
+	return out
+}
+
+// unsetEnv reports whether UnsetEnv removed key
+func (h *GoBuild) unsetEnv(key string) bool {
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	value, ok := h.envOverrides[key]
+	return ok && value == nil
+}
+
+// hasUnsetEnv reports whether UnsetEnv removed any variable
+func (h *GoBuild) hasUnsetEnv() bool {
+	h.envMu.Lock()
+	defer h.envMu.Unlock()
+	for _, value := range h.envOverrides {
+		if value == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hostEnv returns os.Environ() without the variables removed by UnsetEnv
+func (h *GoBuild) hostEnv() []string {
+	if !h.hasUnsetEnv() {
+		return os.Environ()
+	}
+	var env []string
+	for _, entry := range os.Environ() {
+		key, _, _ := strings.Cut(entry, "=")
+		if !h.unsetEnv(key) {
+			env = append(env, entry)
+		}
+	}
+	return env
+}
+
+// getenv is os.Getenv for the build, empty for variables removed by UnsetEnv
+func (h *GoBuild) getenv(key string) string {
+	if h.unsetEnv(key) {
+		return ""
+	}
+	return os.Getenv(key)
+}
@@ -0,0 +1,46 @@
+package gobuild
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestSetAndUnsetEnv(t *testing.T) {
+	t.Setenv("STRAY_VAR", "1")
+	gb := New(&Config{Env: []string{"GOOS=js", "GOARCH=wasm", "CGO_ENABLED=0"}})
+
+	gb.WithEnv(map[string]string{"GOOS": "linux", "GOARCH": "arm64"}).SetEnv("GOARM", "7")
+	gb.UnsetEnv("CGO_ENABLED")
+	gb.UnsetEnv("STRAY_VAR")
+
+	env, err := gb.userEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"GOARCH=arm64", "GOARM=7", "GOOS=linux"}; !slices.Equal(env, want) {
+		t.Errorf("Expected %v, got %v", want, env)
+	}
+	if goos, goarch := gb.target(); goos != "linux" || goarch != "arm64" {
+		t.Errorf("Expected linux/arm64 target, got %s/%s", goos, goarch)
+	}
+	if slices.Contains(gb.environment(env), "STRAY_VAR=1") {
+		t.Error("Expected the unset host variable removed from the compiler env")
+	}
+
+	gb.SetEnv("STRAY_VAR", "2")
+	if env := gb.environment(nil); !slices.Contains(env, "STRAY_VAR=1") {
+		t.Error("Expected the host variable back once set again")
+	}
+}
+
+func TestSetEnvConcurrent(t *testing.T) {
+	gb := New(&Config{})
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); gb.SetEnv("GOOS", "linux") }()
+		go func() { defer wg.Done(); gb.userEnv() }()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,107 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+)
+
+// ErrorCode is a stable failure category, safe to branch on instead of matching error text
+type ErrorCode string
+
+const (
+	ErrCodeToolchainMissing ErrorCode = "E_TOOLCHAIN_MISSING" // Command or CommandLine binary not found
+	ErrCodeCompile          ErrorCode = "E_COMPILE"           // the compiler ran and reported an error
+	ErrCodeTimeout          ErrorCode = "E_TIMEOUT"           // Config.Timeout elapsed
+	ErrCodeRenameLocked     ErrorCode = "E_RENAME_LOCKED"     // the temp file could not replace the final artifact (eg: running exe on Windows)
+	ErrCodeCancelled        ErrorCode = "E_CANCELLED"         // Cancel, superseded by a newer build, caller context done or a failed dependency
+	ErrCodeValidation       ErrorCode = "E_VALIDATION"        // Validate or Policy rejected the configuration
+	ErrCodeArtifactMismatch ErrorCode = "E_ARTIFACT_MISMATCH" // Config.VerifyArtifact found the final file changed after the rename
+	ErrCodeIO               ErrorCode = "E_IO"                // a file operation failed: output folder, temp file, secrets overlay...
+	ErrCodeInternal         ErrorCode = "E_INTERNAL"          // any other failure not reported by the compiler itself
+)
+
+// BuildError attaches an ErrorCode to a build failure, the message is the wrapped error's
+// eg: if gobuild.CodeOf(err) == gobuild.ErrCodeRenameLocked { // ask the user to close the app }
+// For E_COMPILE, Diagnostics lists the positioned compiler errors, eg: to jump to File:Line
+type BuildError struct {
+	Code        ErrorCode
+	Diagnostics []Diagnostic // SeverityError diagnostics parsed from the compiler output
+	Err         error
+}
+
+func (e *BuildError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BuildError) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the ErrorCode found in the error chain, empty if err is nil or carries none
+func CodeOf(err error) ErrorCode {
+	var be *BuildError
+	if errors.As(err, &be) {
+		return be.Code
+	}
+	return ""
+}
+
+// withCode wraps err in a *BuildError unless it already carries a code
+// The category is inferred from the sentinel and typed errors in the chain
+// E_COMPILE is never inferred, it is set where the compiler ran and failed
+func withCode(err error) error {
+	if err == nil || CodeOf(err) != "" {
+		return err
+	}
+
+	code := ErrCodeInternal
+	var validation *ValidationError
+	var policy *PolicyError
+	var pathErr *fs.PathError
+	var linkErr *os.LinkError
+	var syscallErr *os.SyscallError
+	switch {
+	case errors.Is(err, ErrTimeout):
+		code = ErrCodeTimeout
+	case errors.Is(err, ErrCancelled), errors.Is(err, ErrSuperseded), errors.Is(err, ErrDependencyFailed),
+		errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		code = ErrCodeCancelled
+	case errors.As(err, &validation), errors.As(err, &policy):
+		code = ErrCodeValidation
+	case toolchainMissing(err):
+		code = ErrCodeToolchainMissing
+	case errors.As(err, &pathErr), errors.As(err, &linkErr), errors.As(err, &syscallErr):
+		code = ErrCodeIO
+	}
+	return &BuildError{Code: code, Err: err}
+}
+
+// attachDiagnostics copies the error diagnostics of a failed compile onto its *BuildError
+func attachDiagnostics(err error, diags []Diagnostic) {
+	var be *BuildError
+	if !errors.As(err, &be) || be.Code != ErrCodeCompile {
+		return
+	}
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			be.Diagnostics = append(be.Diagnostics, d)
+		}
+	}
+}
+
+// toolchainMissing reports whether starting a command failed because its binary does not exist:
+// not found in PATH or, for an absolute path, a fork/exec ENOENT. Other missing files don't count
+func toolchainMissing(err error) bool {
+	if errors.Is(err, exec.ErrNotFound) {
+		return true
+	}
+	var execErr *exec.Error
+	if errors.As(err, &execErr) {
+		return errors.Is(execErr.Err, fs.ErrNotExist)
+	}
+	var pathErr *fs.PathError
+	return errors.As(err, &pathErr) && pathErr.Op == "fork/exec" && errors.Is(pathErr.Err, fs.ErrNotExist)
+}
@@ -0,0 +1,188 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestErrorCodes(t *testing.T) {
+	t.Run("timeout", func(t *testing.T) {
+		gb := newSlowBuild(t, 100*time.Millisecond)
+		b := gb.Start()
+		err := b.Wait()
+		if CodeOf(err) != ErrCodeTimeout {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeTimeout, CodeOf(err), err)
+		}
+		if b.Result().Code != ErrCodeTimeout {
+			t.Errorf("Expected result code %s, got %q", ErrCodeTimeout, b.Result().Code)
+		}
+		if !errors.Is(err, ErrTimeout) {
+			t.Errorf("Code wrapping must keep the cause in the chain, got: %v", err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		gb := newSlowBuild(t, 5*time.Second)
+		done := make(chan error, 1)
+		go func() { done <- gb.CompileProgram() }()
+		time.Sleep(100 * time.Millisecond)
+		gb.Cancel()
+		if err := <-done; CodeOf(err) != ErrCodeCancelled {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeCancelled, CodeOf(err), err)
+		}
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		err := New(&Config{Command: "go"}).CompileProgram()
+		if CodeOf(err) != ErrCodeValidation {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeValidation, CodeOf(err), err)
+		}
+		var ve *ValidationError
+		if !errors.As(err, &ve) {
+			t.Errorf("Expected *ValidationError in the chain, got: %T", err)
+		}
+	})
+
+	t.Run("toolchain missing", func(t *testing.T) {
+		err := New(&Config{
+			Command:                   filepath.Join(t.TempDir(), "no-such-go"),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     t.TempDir(),
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeToolchainMissing {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeToolchainMissing, CodeOf(err), err)
+		}
+	})
+
+	t.Run("absolute command missing", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := New(&Config{
+			Command:                   filepath.Join(dir, "bin", "go"),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     dir,
+		}).Compile()
+		if CodeOf(err) != ErrCodeToolchainMissing {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeToolchainMissing, CodeOf(err), err)
+		}
+	})
+
+	t.Run("output folder", func(t *testing.T) {
+		dir := t.TempDir()
+		blocker := filepath.Join(dir, "blocker")
+		os.WriteFile(blocker, nil, 0644)
+		err := New(&Config{
+			Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     filepath.Join(blocker, "out"),
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeIO {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeIO, CodeOf(err), err)
+		}
+	})
+
+	t.Run("temp file", func(t *testing.T) {
+		dir := t.TempDir()
+		// exits 0 without writing the -o file
+		err := New(&Config{
+			Command:                   writeFakeCompiler(t, dir, "exit 0"),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     dir,
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeIO {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeIO, CodeOf(err), err)
+		}
+	})
+
+	t.Run("secrets overlay", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("TMPDIR", filepath.Join(dir, "missing"))
+		err := New(&Config{
+			Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     dir,
+			Secrets:                   SecretFunc(func(string) (string, error) { return "s3cr3t", nil }),
+			SecretStamps:              map[string]string{"main.apiKey": "API_KEY"},
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeIO {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeIO, CodeOf(err), err)
+		}
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		dir := t.TempDir()
+		err := New(&Config{
+			Command:                   "go",
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     dir,
+			Agent:                     failingAgent{},
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeInternal {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeInternal, CodeOf(err), err)
+		}
+	})
+
+	t.Run("compile", func(t *testing.T) {
+		dir := t.TempDir()
+		err := New(&Config{
+			Command:                   writeFakeCompiler(t, dir, "echo 'syntax error' >&2; exit 1"),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     dir,
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeCompile {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeCompile, CodeOf(err), err)
+		}
+	})
+
+	t.Run("rename locked", func(t *testing.T) {
+		dir := t.TempDir()
+		// a non empty directory at the final path makes the rename fail on every platform
+		if err := os.MkdirAll(filepath.Join(dir, "app", "busy"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		err := New(&Config{
+			Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     dir,
+		}).CompileProgram()
+		if CodeOf(err) != ErrCodeRenameLocked {
+			t.Errorf("Expected %s, got %q (%v)", ErrCodeRenameLocked, CodeOf(err), err)
+		}
+	})
+
+	if CodeOf(nil) != "" {
+		t.Error("Expected empty code for nil error")
+	}
+}
+
+func TestBuildErrorDiagnostics(t *testing.T) {
+	dir := t.TempDir()
+	err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo '# example.com/app' >&2; echo './main.go:12:5: undefined: foo' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}).CompileProgram()
+
+	var be *BuildError
+	if !errors.As(err, &be) {
+		t.Fatalf("Expected *BuildError, got %T", err)
+	}
+	if len(be.Diagnostics) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %+v", be.Diagnostics)
+	}
+	d := be.Diagnostics[0]
+	if d.File != "./main.go" || d.Line != 12 || d.Column != 5 || d.Message != "undefined: foo" {
+		t.Errorf("Unexpected diagnostic %+v", d)
+	}
+}
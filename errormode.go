@@ -0,0 +1,100 @@
+package gobuild
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrorMode controls how many compiler errors a failing build reports
+type ErrorMode int
+
+const (
+	// ErrorsDefault keeps the compiler defaults (go stops listing after 10 errors per package)
+	ErrorsDefault ErrorMode = iota
+	// ErrorsFirst kills the compiler as soon as it prints the first error, for the
+	// fastest watch mode feedback. The build fails with that single diagnostic
+	ErrorsFirst
+	// ErrorsAll reports every error (-gcflags=-e), eg: for CI logs. Ignored by tinygo
+	ErrorsAll
+)
+
+// gcflags returns the user -gcflags value with -e added for ErrorsAll
+// ok reports whether a -gcflags argument has to be passed
+func (m ErrorMode) gcflags(tinyGo bool, user string, found bool) (string, bool) {
+	if m != ErrorsAll || tinyGo {
+		return user, found
+	}
+	if !found || user == "" {
+		return "-e", true
+	}
+	return user + " -e", true
+}
+
+// isTinyGo reports whether command runs tinygo
+func isTinyGo(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
+		return false
+	}
+	name := strings.TrimSuffix(fields[0], ".exe")
+	return name == "tinygo" || strings.HasSuffix(name, "/tinygo") || strings.HasSuffix(name, `\tinygo`)
+}
+
+// firstErrorWaitDelay bounds the wait for the output pipe once the compiler was killed
+const firstErrorWaitDelay = 200 * time.Millisecond
+
+// firstErrorWriter collects the compiler output and stops the compiler at the first error line
+type firstErrorWriter struct {
+	mu     sync.Mutex
+	out    io.Writer
+	stop   func() // kills the compiler
+	line   []byte // incomplete last line
+	killed bool
+}
+
+func (w *firstErrorWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n, err := w.out.Write(p)
+	if w.killed {
+		return n, err
+	}
+
+	w.line = append(w.line, p...)
+	for {
+		i := bytes.IndexByte(w.line, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(w.line[:i]), "\r")
+		w.line = w.line[i+1:]
+		if isErrorLine(line) {
+			w.killed = true
+			w.stop()
+			break
+		}
+	}
+	return n, err
+}
+
+// isErrorLine reports whether a compiler output line is a positioned error
+func isErrorLine(line string) bool {
+	m := diagnosticPosition.FindStringSubmatch(line)
+	if m == nil {
+		return false
+	}
+	severity, _ := classifyDiagnostic(m[4], true)
+	return severity == SeverityError
+}
+
+// outputWriter returns where the compiler output is written, stop kills the compiler
+func (h *GoBuild) outputWriter(output io.Writer, stop func()) io.Writer {
+	if h.config.ErrorMode == ErrorsFirst {
+		return &firstErrorWriter{out: output, stop: stop}
+	}
+	return output
+}
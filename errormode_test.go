@@ -0,0 +1,80 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestErrorsAllArguments(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		args    []string
+		want    string
+	}{
+		{"no user gcflags", "go", nil, "-gcflags=-e"},
+		{"merged with user gcflags", "go", []string{"-gcflags=all=-N -l"}, "-gcflags=all=-N -l -e"},
+		{"tinygo", "tinygo", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gb := New(&Config{
+				Command:                   tt.command,
+				MainInputFileRelativePath: "main.go",
+				OutName:                   "app",
+				OutFolderRelativePath:     "out",
+				ErrorMode:                 ErrorsAll,
+				CompilingArguments:        func() []string { return tt.args },
+			})
+			args := gb.BuildArguments()
+			var gcflags []string
+			for _, a := range args {
+				if len(a) > 9 && a[:9] == "-gcflags=" {
+					gcflags = append(gcflags, a)
+				}
+			}
+			if tt.want == "" {
+				if len(gcflags) != 0 {
+					t.Errorf("Expected no -gcflags, got %v", args)
+				}
+				return
+			}
+			if !slices.Equal(gcflags, []string{tt.want}) {
+				t.Errorf("Expected %q once, got %v", tt.want, args)
+			}
+		})
+	}
+}
+
+func TestErrorsFirstStopsAtFirstError(t *testing.T) {
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "finished")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo '# example.com/app' >&2; echo './main.go:3:1: undefined: a' >&2; sleep 2; echo './main.go:4:1: undefined: b' >&2; touch "+marker+"; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		ErrorMode:                 ErrorsFirst,
+	})
+
+	start := time.Now()
+	err := gb.CompileProgram()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the compiler to be stopped at the first error, took %v", elapsed)
+	}
+
+	var be *BuildError
+	if !errors.As(err, &be) || be.Code != ErrCodeCompile {
+		t.Fatalf("Expected E_COMPILE, got %v", err)
+	}
+	if len(be.Diagnostics) != 1 || be.Diagnostics[0].Message != "undefined: a" {
+		t.Errorf("Expected only the first error, got %+v", be.Diagnostics)
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("Compiler was not stopped")
+	}
+}
@@ -0,0 +1,15 @@
+package gobuild
+
+import "errors"
+
+// Cancellation causes attached to the compilation error, check them with errors.Is
+// eg: errors.Is(err, gobuild.ErrSuperseded) to skip showing a failure in the UI
+var (
+	ErrSuperseded = errors.New("compilation superseded by a newer build")
+	ErrCancelled  = errors.New("compilation cancelled")
+	ErrTimeout    = errors.New("compilation timed out")
+)
+
+// ErrDependencyFailed is the cause of a Binaries build skipped because a binary
+// listed in its BinarySpec.DependsOn did not build
+var ErrDependencyFailed = errors.New("dependency failed")
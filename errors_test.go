@@ -0,0 +1,57 @@
+package gobuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newSlowBuild(t *testing.T, timeout time.Duration) *GoBuild {
+	tempDir := t.TempDir()
+	return New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeSlowCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   timeout,
+	})
+}
+
+func TestCancellationCauseTimeout(t *testing.T) {
+	gb := newSlowBuild(t, 100*time.Millisecond)
+
+	err := gb.CompileProgram()
+	if !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout, got: %v", err)
+	}
+}
+
+func TestCancellationCauseCancel(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+
+	done := make(chan error, 1)
+	go func() { done <- gb.CompileProgram() }()
+
+	time.Sleep(100 * time.Millisecond)
+	gb.Cancel()
+
+	if err := <-done; !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled, got: %v", err)
+	}
+}
+
+func TestCancellationCauseSuperseded(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+
+	first := make(chan error, 1)
+	go func() { first <- gb.CompileProgram() }()
+
+	time.Sleep(100 * time.Millisecond)
+	if err := gb.CompileProgram(); err != nil {
+		t.Errorf("Second compilation should succeed, got: %v", err)
+	}
+
+	if err := <-first; !errors.Is(err, ErrSuperseded) {
+		t.Errorf("Expected ErrSuperseded for the first compilation, got: %v", err)
+	}
+}
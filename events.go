@@ -0,0 +1,63 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Event names of Config.JSONEvents
+const (
+	EventBuildStarted  = "build_started"
+	EventBuildOutput   = "build_output"
+	EventBuildFinished = "build_finished"
+	EventLog           = "log" // any other message sent to the Logger (LogWarn and above)
+)
+
+// BuildEvent is one NDJSON line sent to Config.Logger with Config.JSONEvents
+type BuildEvent struct {
+	Event   string       `json:"event"`
+	Time    time.Time    `json:"time"`
+	BuildID uint64       `json:"build_id,omitempty"`
+	Label   string       `json:"label,omitempty"`   // build_started
+	Output  string       `json:"output,omitempty"`  // build_output: compiler stdout and stderr
+	Result  *BuildResult `json:"result,omitempty"`  // build_finished
+	Level   string       `json:"level,omitempty"`   // log
+	Message string       `json:"message,omitempty"` // log
+}
+
+// emitEvent sends e to Config.Logger as a single JSON line
+func (h *GoBuild) emitEvent(e BuildEvent) {
+	if h.config.Logger == nil || !h.config.JSONEvents {
+		return
+	}
+	e.Time = h.now()
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	h.logMu.Lock()
+	defer h.logMu.Unlock()
+	h.config.Logger(string(line))
+}
+
+// eventStarted emits build_started for comp
+func (h *GoBuild) eventStarted(comp *Build) {
+	if !comp.discard {
+		h.emitEvent(BuildEvent{Event: EventBuildStarted, BuildID: comp.ID, Label: comp.label})
+	}
+}
+
+// eventOutput emits build_output with the compiler output of comp, if any
+// With StreamOutput the lines were already emitted while the compiler ran
+func (h *GoBuild) eventOutput(comp *Build) {
+	if comp.output != "" && !comp.discard && !h.config.StreamOutput {
+		h.emitEvent(BuildEvent{Event: EventBuildOutput, BuildID: comp.ID, Output: comp.output})
+	}
+}
+
+// eventFinished emits build_finished with the result of a build that started
+func (h *GoBuild) eventFinished(comp *Build) {
+	if !comp.startTime.IsZero() && !comp.discard {
+		h.emitEvent(BuildEvent{Event: EventBuildFinished, BuildID: comp.ID, Result: comp.result})
+	}
+}
@@ -0,0 +1,56 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestJSONEvents(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	var events []map[string]any
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'go: downloading example.com/lib v1.0.0' >&2; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Label:                     "api",
+		JSONEvents:                true,
+		Logger: func(message ...any) {
+			if len(message) != 1 {
+				t.Errorf("Expected a single JSON line, got %d values", len(message))
+				return
+			}
+			var e map[string]any
+			if err := json.Unmarshal([]byte(message[0].(string)), &e); err != nil {
+				t.Errorf("Expected NDJSON, got %q: %v", message[0], err)
+			}
+			mu.Lock()
+			events = append(events, e)
+			mu.Unlock()
+		},
+	})
+
+	result, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var names []string
+	for _, e := range events {
+		names = append(names, e["event"].(string))
+	}
+	if len(events) != 3 || names[0] != EventBuildStarted || names[1] != EventBuildOutput || names[2] != EventBuildFinished {
+		t.Fatalf("Unexpected events %v", names)
+	}
+	if events[0]["label"] != "api" || events[0]["build_id"] != float64(result.ID) {
+		t.Errorf("Unexpected build_started %v", events[0])
+	}
+	finished := events[2]["result"].(map[string]any)
+	if finished["sha256"] != result.Hash || finished["exit_code"] != float64(0) {
+		t.Errorf("Unexpected build_finished result %v", finished)
+	}
+}
@@ -0,0 +1,44 @@
+package gobuild
+
+import "fmt"
+
+// ExitClass is how an exit code of the compiler command counts, see Config.ExitCodes
+type ExitClass string
+
+const (
+	ExitSuccess ExitClass = "success" // the build succeeded
+	ExitWarning ExitClass = "warning" // the build succeeded, the command reported warnings
+	ExitFailure ExitClass = "failure" // the build failed
+)
+
+// ExitCodes maps exit codes of a wrapped Command to their class, for wrappers returning
+// nonzero codes for warnings, eg: gobuild.ExitCodes{2: gobuild.ExitWarning}
+// 0 is a success and unlisted codes a failure
+type ExitCodes map[int]ExitClass
+
+// classify returns the class of code, empty when the process didn't exit on its own (-1)
+func (e ExitCodes) classify(code int) ExitClass {
+	switch {
+	case code < 0:
+		return ""
+	case code == 0:
+		return ExitSuccess
+	}
+	if class, ok := e[code]; ok {
+		return class
+	}
+	return ExitFailure
+}
+
+// applyExitCodes records the exit class of comp and clears err when Config.ExitCodes
+// accepts the exit code, a warning is logged with the command output
+func (h *GoBuild) applyExitCodes(comp *Build, err error) error {
+	comp.exitClass = h.config.ExitCodes.classify(comp.exitCode)
+	if err == nil || comp.exitClass == ExitFailure || comp.exitClass == "" {
+		return err
+	}
+	if comp.exitClass == ExitWarning {
+		h.logf(LogWarn, comp.ID, fmt.Sprintf("Build %d: exit code %d counted as a warning", comp.ID, comp.exitCode), comp.output)
+	}
+	return nil
+}
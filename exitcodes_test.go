@@ -0,0 +1,37 @@
+package gobuild
+
+import "testing"
+
+func TestExitCodesWarning(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler+"\necho 'lint: 3 warnings' >&2; exit 2"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		ExitCodes:                 ExitCodes{2: ExitWarning, 3: ExitSuccess},
+	})
+
+	result, err := gb.Compile()
+	if err != nil {
+		t.Fatalf("Expected exit code 2 to count as a warning, got %v", err)
+	}
+	if result.ExitCode != 2 || result.ExitClass != ExitWarning || result.OutputPath == "" {
+		t.Errorf("Unexpected result: exit %d (%s), output %q", result.ExitCode, result.ExitClass, result.OutputPath)
+	}
+
+	gb.config.ExitCodes = nil
+	result, err = gb.Compile()
+	if CodeOf(err) != ErrCodeCompile || result.ExitClass != ExitFailure {
+		t.Errorf("Expected unlisted exit codes to fail, got %v (%s)", err, result.ExitClass)
+	}
+}
+
+func TestExitCodesClassify(t *testing.T) {
+	codes := ExitCodes{1: ExitWarning}
+	for code, want := range map[int]ExitClass{-1: "", 0: ExitSuccess, 1: ExitWarning, 2: ExitFailure} {
+		if got := codes.classify(code); got != want {
+			t.Errorf("classify(%d) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// experimentEnv returns the GOEXPERIMENT and GODEBUG entries for Config.Experiments and GoDebug
+func (h *GoBuild) experimentEnv() []string {
+	var env []string
+	if len(h.config.Experiments) > 0 {
+		env = append(env, "GOEXPERIMENT="+strings.Join(h.config.Experiments, ","))
+	}
+	if len(h.config.GoDebug) > 0 {
+		env = append(env, "GODEBUG="+strings.Join(h.config.GoDebug, ","))
+	}
+	return env
+}
+
+// checkExperiments asks the toolchain whether it knows Config.Experiments, names
+// come and go between Go releases (eg: loopvar became the default in 1.22)
+// Only go binaries are checked, each distinct value once per GoBuild
+func (h *GoBuild) checkExperiments(ctx context.Context) error {
+	if len(h.config.Experiments) == 0 || h.goTool() != h.config.Command {
+		return nil
+	}
+	value := strings.Join(h.config.Experiments, ",")
+
+	h.mu.RLock()
+	checked := h.experimentsOK == value
+	h.mu.RUnlock()
+	if checked {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, h.config.Command, "env", "GOEXPERIMENT")
+	cmd.Dir = h.config.WorkDir
+	env := h.environment(nil)
+	if env == nil {
+		env = os.Environ()
+	}
+	cmd.Env = append(env, "GOEXPERIMENT="+value)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return &BuildError{
+			Code: ErrCodeValidation,
+			Err:  fmt.Errorf("Experiments %q rejected by %s: %s", value, h.config.Command, strings.TrimSpace(h.decodeOutput(output))),
+		}
+	}
+
+	h.mu.Lock()
+	h.experimentsOK = value
+	h.mu.Unlock()
+	return nil
+}
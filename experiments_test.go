@@ -0,0 +1,56 @@
+package gobuild
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+func TestExperimentEnv(t *testing.T) {
+	gb := New(&Config{
+		Env:         []string{"GOOS=js"},
+		Experiments: []string{"loopvar", "aliastypeparams"},
+		GoDebug:     []string{"gotypesalias=1", "http2client=0"},
+	})
+
+	env, err := gb.userEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"GOOS=js", "GOEXPERIMENT=loopvar,aliastypeparams", "GODEBUG=gotypesalias=1,http2client=0"}
+	if !reflect.DeepEqual(env, expected) {
+		t.Errorf("Expected %q, got %q", expected, env)
+	}
+	if len(gb.config.Env) != 1 {
+		t.Errorf("Config.Env must not be modified, got %q", gb.config.Env)
+	}
+}
+
+func TestExperimentsValidation(t *testing.T) {
+	base := Config{Command: "go", MainInputFileRelativePath: "main.go", OutName: "app"}
+
+	bad := base
+	bad.Experiments = []string{"loopvar,arenas"}
+	bad.GoDebug = []string{"http2client"}
+	err := New(&bad).Validate()
+	ve, ok := err.(*ValidationError)
+	if !ok || len(ve.Issues) != 2 {
+		t.Errorf("Expected 2 validation issues, got: %v", err)
+	}
+}
+
+func TestExperimentsCheckedByToolchain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping toolchain check in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	gb := New(&Config{Command: goBin, Experiments: []string{"nosuchexperiment"}})
+	if err := gb.checkExperiments(context.Background()); CodeOf(err) != ErrCodeValidation {
+		t.Errorf("Expected %s for an unknown experiment, got %q (%v)", ErrCodeValidation, CodeOf(err), err)
+	}
+}
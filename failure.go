@@ -0,0 +1,89 @@
+package gobuild
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+)
+
+// FailureKind explains why a build failed, finer than ErrorCode for compile failures
+// eg: chart failures per kind on a dashboard
+type FailureKind string
+
+const (
+	FailureSyntax            FailureKind = "syntax"             // the parser rejected a source file
+	FailureType              FailureKind = "type"               // the sources parse but don't type-check (undefined, mismatched types...)
+	FailureMissingDependency FailureKind = "missing-dependency" // a package or module could not be resolved (go.mod/go.sum out of date, offline)
+	FailureToolchainMissing  FailureKind = "toolchain-missing"  // the compiler binary was not found
+	FailureTimeout           FailureKind = "timeout"            // Config.Timeout elapsed
+	FailureDiskFull          FailureKind = "disk-full"          // no space left writing the artifact, cache or temp files
+	FailureCancelled         FailureKind = "cancelled"          // Cancel, superseded or caller context done
+	FailureOther             FailureKind = "other"              // validation, promotion and anything not listed above
+)
+
+// missingDependencyMarkers are go command messages for unresolved packages and modules
+var missingDependencyMarkers = []string{
+	"no required module provides package",
+	"cannot find module providing package",
+	"cannot find package",
+	"missing go.sum entry",
+	"is not in std",
+	"updates to go.mod needed",
+	"go: module lookup disabled",
+}
+
+// diskFullMarkers are the "no space" messages of unix and windows
+var diskFullMarkers = []string{
+	"no space left on device",
+	"not enough space on the disk",
+	"disk quota exceeded",
+}
+
+// classifyFailure returns the FailureKind of a build error, empty on success
+// output is the compiler output, diags its parsed diagnostics
+func classifyFailure(err error, output string, diags []Diagnostic) FailureKind {
+	if err == nil {
+		return ""
+	}
+
+	switch CodeOf(err) {
+	case ErrCodeCancelled:
+		return FailureCancelled
+	case ErrCodeTimeout:
+		return FailureTimeout
+	case ErrCodeToolchainMissing:
+		return FailureToolchainMissing
+	}
+
+	if errors.Is(err, syscall.ENOSPC) || containsAny(strings.ToLower(output+"\n"+err.Error()), diskFullMarkers) {
+		return FailureDiskFull
+	}
+	if CodeOf(err) != ErrCodeCompile {
+		return FailureOther
+	}
+	if containsAny(output, missingDependencyMarkers) {
+		return FailureMissingDependency
+	}
+
+	kind := FailureOther
+	for _, d := range diags {
+		if d.Severity != SeverityError {
+			continue
+		}
+		if strings.Contains(d.Message, "syntax error") || strings.HasPrefix(d.Message, "expected ") {
+			return FailureSyntax
+		}
+		kind = FailureType
+	}
+	return kind
+}
+
+// containsAny reports whether s contains one of the markers
+func containsAny(s string, markers []string) bool {
+	for _, m := range markers {
+		if strings.Contains(s, m) {
+			return true
+		}
+	}
+	return false
+}
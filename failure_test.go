@@ -0,0 +1,58 @@
+package gobuild
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+	"testing"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	compile := &BuildError{Code: ErrCodeCompile, Err: fmt.Errorf("exit status 1")}
+	diag := func(msg string) []Diagnostic {
+		return []Diagnostic{{Severity: SeverityError, File: "main.go", Line: 1, Message: msg}}
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		output string
+		diags  []Diagnostic
+		want   FailureKind
+	}{
+		{"success", nil, "", nil, ""},
+		{"syntax", compile, "", diag("syntax error: unexpected }"), FailureSyntax},
+		{"type", compile, "", diag("undefined: foo"), FailureType},
+		{"missing dependency", compile, "main.go:3:2: no required module provides package example.com/lib", diag("no required module provides package example.com/lib"), FailureMissingDependency},
+		{"go.sum", compile, "missing go.sum entry for module providing package example.com/lib", nil, FailureMissingDependency},
+		{"toolchain", withCode(exec.ErrNotFound), "", nil, FailureToolchainMissing},
+		{"timeout", withCode(ErrTimeout), "", nil, FailureTimeout},
+		{"cancelled", withCode(ErrSuperseded), "", nil, FailureCancelled},
+		{"disk full output", compile, "write /tmp/go-build123/b001/_pkg_.a: no space left on device", nil, FailureDiskFull},
+		{"disk full errno", withCode(&BuildError{Code: ErrCodeRenameLocked, Err: syscall.ENOSPC}), "", nil, FailureDiskFull},
+		{"validation", withCode(&ValidationError{}), "", nil, FailureOther},
+		{"no diagnostics", compile, "signal: killed", nil, FailureOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyFailure(tt.err, tt.output, tt.diags); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBuildResultFailure(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo './main.go:3:1: syntax error: unexpected newline' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}).Compile()
+
+	if err == nil || r.Failure != FailureSyntax {
+		t.Errorf("Expected a syntax failure, got %q (%v)", r.Failure, err)
+	}
+}
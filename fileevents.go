@@ -0,0 +1,74 @@
+package gobuild
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// FileEventHandler adapts a GoBuild to watcher pipelines that forward fsnotify events
+// to handlers (eg: tinywasm/devwatch), so consumers register it without glue code:
+//
+//	watcher.Handlers = append(watcher.Handlers, gb.FileEventHandler(".go"))
+type FileEventHandler struct {
+	gb         *GoBuild
+	extensions []string
+}
+
+// FileEventHandler returns the watcher adapter of h, rebuilding on changes to files
+// with one of extensions (".go" when none are given)
+func (h *GoBuild) FileEventHandler(extensions ...string) *FileEventHandler {
+	if len(extensions) == 0 {
+		extensions = []string{".go"}
+	}
+	return &FileEventHandler{gb: h, extensions: extensions}
+}
+
+// MainInputFileRelativePath is the main file of the build, eg: for the watcher to find its module
+func (a *FileEventHandler) MainInputFileRelativePath() string {
+	return a.gb.MainInputFileRelativePath()
+}
+
+// SupportedExtensions are the file extensions the watcher should forward
+func (a *FileEventHandler) SupportedExtensions() []string {
+	return append([]string(nil), a.extensions...)
+}
+
+// UnobservedFiles are the build outputs the watcher must ignore, see GoBuild.UnobservedFiles
+func (a *FileEventHandler) UnobservedFiles() []string {
+	return a.gb.UnobservedFiles()
+}
+
+// NewFileEvent rebuilds after a create, write, remove or rename event on filePath and
+// returns the build error, chmod events, unsupported extensions and build outputs are ignored
+// A build superseded or cancelled by a newer event returns nil, the newer one reports
+func (a *FileEventHandler) NewFileEvent(fileName, extension, filePath, event string) error {
+	if fileName == "" {
+		fileName = filepath.Base(filePath)
+	}
+	if extension == "" {
+		extension = filepath.Ext(fileName)
+	}
+	if strings.EqualFold(event, "chmod") || !a.supported(extension) {
+		return nil
+	}
+	if !a.gb.observed(fileName) {
+		return nil
+	}
+
+	a.gb.logf(LogDebug, 0, "File event:", event, filePath)
+	err := a.gb.Start().Wait()
+	if CodeOf(err) == ErrCodeCancelled {
+		return nil
+	}
+	return err
+}
+
+// supported reports whether extension is one of SupportedExtensions
+func (a *FileEventHandler) supported(extension string) bool {
+	for _, e := range a.extensions {
+		if strings.EqualFold(e, extension) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileEventHandler(t *testing.T) {
+	dir := t.TempDir()
+	runs := filepath.Join(dir, "runs")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo run >> "+runs+"\n"+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+	handler := gb.FileEventHandler()
+
+	if exts := handler.SupportedExtensions(); len(exts) != 1 || exts[0] != ".go" {
+		t.Errorf("Expected .go by default, got %v", exts)
+	}
+	events := []struct{ name, ext, event string }{
+		{"main.go", ".go", "write"},    // builds
+		{"main.go", ".go", "chmod"},    // ignored
+		{"style.css", ".css", "write"}, // unsupported extension
+		{"app", "", "create"},          // the build output
+		{"util.go", "", "remove"},      // extension from the name, builds
+	}
+	for _, e := range events {
+		if err := handler.NewFileEvent(e.name, e.ext, filepath.Join(dir, e.name), e.event); err != nil {
+			t.Fatalf("%s %s: %v", e.event, e.name, err)
+		}
+	}
+
+	if data, _ := os.ReadFile(runs); strings.Count(string(data), "run") != 2 {
+		t.Errorf("Expected 2 builds, got %q", data)
+	}
+}
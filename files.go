@@ -1,33 +1,230 @@
 package gobuild
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
+// resolve returns p (slash or OS separated) as an OS path relative to the
+// compiler working directory (Config.WorkDir)
+// Absolute paths and an empty WorkDir (current process directory) are only cleaned
+func (h *GoBuild) resolve(p string) string {
+	p = filepath.FromSlash(p)
+	if h.config.WorkDir == "" || filepath.IsAbs(p) {
+		return filepath.Clean(p)
+	}
+	return filepath.Join(filepath.FromSlash(h.config.WorkDir), p)
+}
+
+// outPath returns the OS path of fileName inside the output folder
+func (h *GoBuild) outPath(fileName string) string {
+	return h.resolve(filepath.Join(h.outFolder(), fileName))
+}
+
+// outputDirs returns the absolute folders builds write into: the output folder,
+// StagingDir, QuarantineDir, CacheDir and the Coverage folder
+// Their content changes with every build, the cache key and Watch skip them
+func (h *GoBuild) outputDirs() []string {
+	var dirs []string
+	for _, dir := range []string{h.config.OutFolderRelativePath, h.outFolder(), h.config.StagingDir, h.config.QuarantineDir, h.config.CacheDir} {
+		if dir == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(h.resolve(dir)); err == nil && !slices.Contains(dirs, abs) {
+			dirs = append(dirs, abs)
+		}
+	}
+	if c := h.config.Coverage; c != nil && c.Dir != "" {
+		if abs, err := filepath.Abs(h.resolve(c.Dir)); err == nil {
+			dirs = append(dirs, abs)
+		}
+	}
+	return dirs
+}
+
+// generatedFile reports whether the file named name is written by gobuild itself: the
+// artifact, its temp files (OutName_temp_<pid>_<id>_<time> included), sidecars and the
+// FinalNameFunc versioned files published so far
+func (h *GoBuild) generatedFile(name string) bool {
+	if strings.HasPrefix(name, h.config.OutName+"_temp_") || slices.Contains(h.UnobservedFiles(), name) {
+		return true
+	}
+	h.archiveMu.Lock()
+	defer h.archiveMu.Unlock()
+	return h.namedFiles[name]
+}
+
 // UnobservedFiles returns the list of files that should not be tracked by file watchers
 // eg: main.exe, main_temp.exe
 func (h *GoBuild) UnobservedFiles() []string {
-	return []string{
+	files := []string{
 		h.outFileName,
 		h.outTempFileName,
 	}
+	if h.config.TinyGoWasm != nil && h.config.TinyGoWasm.Gzip {
+		files = append(files, h.outFileName+".gz")
+	}
+	if h.config.Compress != nil {
+		files = append(files, h.config.Compress.compressedFiles(h.outFileName)...)
+	}
+	if h.config.Manifest {
+		files = append(files, h.outFileName+manifestSuffix)
+	}
+	if h.config.CopyWasmExec {
+		files = append(files, wasmExecFile)
+	}
+	return files
+}
+
+// promote hashes the freshly built temp file and renames it to the final output
+// The hash is recorded on the build and as the GoBuild ArtifactHash, the size
+// along with its delta against the previous promoted artifact
+func (h *GoBuild) promote(comp *Build) error {
+	tempPath := h.tempPath(comp.tempFile)
+	hash, err := hashFile(tempPath)
+	if err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return errors.Join(errors.New("promote"), diagnoseInterference(tempPath, err))
+	}
+
+	// the running binary may lock the file (windows) and serves the old code anyway
+	if h.config.RunAfterBuild && !comp.discard {
+		if err := h.StopApp(); err != nil {
+			h.logf(LogWarn, comp.ID, err)
+		}
+	}
+
+	rename := h.renameOutputFile
+	switch {
+	case h.isStaging():
+		rename = h.publishStaged
+	case h.config.OutputFS != nil:
+		rename = h.promoteTo
+	case h.config.Fsync:
+		rename = h.durablePromote
+	}
+	if err := rename(comp.tempFile); err != nil {
+		return err
+	}
+
+	if h.config.VerifyArtifact {
+		if err := h.verifyArtifact(hash); err != nil {
+			return err
+		}
+	}
+
+	comp.hash = hash
+	comp.size = h.artifactSizeOf(h.FinalOutputPath())
+	h.mu.Lock()
+	h.artifactHash = hash
+	if h.artifactSize > 0 {
+		comp.sizeDelta = comp.size - h.artifactSize
+	}
+	h.artifactSize = comp.size
+	h.mu.Unlock()
+	return nil
+}
+
+// verifyArtifact re-hashes the final file and compares it with the temp file hash
+// Antivirus and file-sync tools on Windows occasionally rewrite fresh binaries
+func (h *GoBuild) verifyArtifact(want string) error {
+	finalPath := h.FinalOutputPath()
+	got, err := h.hashArtifact(finalPath)
+	if err != nil {
+		return &BuildError{Code: ErrCodeArtifactMismatch, Err: errors.Join(fmt.Errorf("verify %q", finalPath), err)}
+	}
+	if got != want {
+		return &BuildError{
+			Code: ErrCodeArtifactMismatch,
+			Err:  fmt.Errorf("verify %q: sha256 %s after rename, %s before", finalPath, got, want),
+		}
+	}
+	return nil
+}
+
+// ArtifactHash returns the hex encoded SHA-256 of the last promoted artifact
+// Empty if no build succeeded yet
+func (h *GoBuild) ArtifactHash() string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.artifactHash
+}
+
+// hashFile returns the hex encoded SHA-256 of the file content
+// Directory artifacts (eg: gomobile .xcframework bundles) hash every file path and content
+func hashFile(filePath string) (string, error) {
+	f, err := os.Open(fixLongPath(filePath))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if info, err := f.Stat(); err == nil && info.IsDir() {
+		err := filepath.WalkDir(fixLongPath(filePath), func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			rel, _ := filepath.Rel(fixLongPath(filePath), p)
+			io.WriteString(sum, filepath.ToSlash(rel)+"\x00")
+			entry, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer entry.Close()
+			_, err = io.Copy(sum, entry)
+			return err
+		})
+		if err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(sum.Sum(nil)), nil
+	}
+
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
 }
 
 // renameOutputFile renames the temporary output file to the final output file
 func (h *GoBuild) renameOutputFile(tempFileName string) error {
-	tempPath := path.Join(h.config.OutFolderRelativePath, tempFileName)
+	tempPath := h.tempPath(tempFileName)
 	finalPath := h.FinalOutputPath()
 
 	// fmt.Fprintf(h.config.Logger, "Renaming %s to %s\n", tempPath, finalPath)
 
-	err := os.Rename(tempPath, finalPath)
+	// A directory can't replace a non-empty one, eg: a previous .xcframework bundle
+	if info, err := os.Stat(fixLongPath(tempPath)); err == nil && info.IsDir() {
+		os.RemoveAll(fixLongPath(finalPath))
+	}
+
+	err := os.Rename(fixLongPath(tempPath), fixLongPath(finalPath))
 	if err != nil {
-		if h.config.Logger != nil {
-			h.config.Logger("Rename failed:", err)
+		// paths are quoted so spaces and non-ASCII names stay readable in logs
+		cause := err
+		if linkErr, ok := err.(*os.LinkError); ok {
+			cause = linkErr.Err
+		}
+		if errors.Is(cause, fs.ErrNotExist) {
+			cause = diagnoseInterference(tempPath, cause)
+		} else {
+			cause = diagnoseInterference(finalPath, cause)
+		}
+		h.logf(LogError, 0, "Rename failed:", fmt.Sprintf("%q -> %q:", tempPath, finalPath), cause)
+		return &BuildError{
+			Code: ErrCodeRenameLocked,
+			Err:  errors.Join(fmt.Errorf("renameOutputFile %q -> %q", tempPath, finalPath), cause),
 		}
-		return errors.Join(errors.New("renameOutputFile"), err)
 	}
 
 	// fmt.Fprintf(h.config.Logger, "Rename successful\n")
@@ -35,13 +232,38 @@ func (h *GoBuild) renameOutputFile(tempFileName string) error {
 	return nil
 }
 
+// newTempFileName returns a temp file name no other build can be using
+// eg: app_temp_4242_7_1712345678901234567.exe (PID, build ID, time)
+// The PID and build ID keep instances and processes sharing an output folder apart,
+// the existence check covers clock collisions and leftovers from a crashed process
+func (h *GoBuild) newTempFileName(id uint64) string {
+	base := fmt.Sprintf("%s_temp_%d_%d_%d", h.config.OutName, os.Getpid(), id, time.Now().UnixNano())
+	name := base + h.config.Extension
+	for n := 1; ; n++ {
+		if _, err := os.Lstat(fixLongPath(h.tempPath(name))); errors.Is(err, fs.ErrNotExist) {
+			return name
+		} else if err != nil {
+			return name // can't tell, the compiler reports unusable folders
+		}
+		name = fmt.Sprintf("%s_%d%s", base, n, h.config.Extension)
+	}
+}
+
+// discardTempFile removes the temp file of a failed build
+// It runs whatever the failure, a cancelled build may have lost the active slot
+// to a newer one before reaching its own cleanup. The compiler and its children
+// (see runCommand) have exited by now, nothing writes the file afterwards
+func (h *GoBuild) discardTempFile(comp *Build) {
+	h.cleanupTempFile(comp.tempFile)
+}
+
 // cleanupTempFile removes the temporary output file if it exists
 // This is called when compilation fails to ensure no partial files remain
 func (h *GoBuild) cleanupTempFile(tempFileName string) {
-	tempFilePath := path.Join(h.config.OutFolderRelativePath, tempFileName)
-	if _, err := os.Stat(tempFilePath); err == nil {
-		// File exists, try to remove it
-		os.Remove(tempFilePath)
+	tempFilePath := h.tempPath(tempFileName)
+	if _, err := os.Stat(fixLongPath(tempFilePath)); err == nil {
+		// File (or bundle directory) exists, try to remove it
+		os.RemoveAll(fixLongPath(tempFilePath))
 		// We don't handle the error here as it's a cleanup operation
 		// and the main error (compilation failure) is more important
 	}
@@ -1,6 +1,8 @@
 package gobuild
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"testing"
@@ -149,3 +151,40 @@ func TestRenameOutputFileInvalidDestination(t *testing.T) {
 		t.Error("Expected error when renaming to invalid destination, got nil")
 	}
 }
+
+// fakeEchoCompiler writes "artifact" into the -o output file
+const fakeEchoCompiler = `prev=""
+for arg; do
+	if [ "$prev" = "-o" ]; then printf artifact > "$arg"; fi
+	prev="$arg"
+done`
+
+func TestArtifactHash(t *testing.T) {
+	tempDir := t.TempDir()
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+	})
+
+	if gb.ArtifactHash() != "" {
+		t.Error("ArtifactHash should be empty before the first build")
+	}
+
+	b := gb.Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	sum := sha256.Sum256([]byte("artifact"))
+	expected := hex.EncodeToString(sum[:])
+
+	if gb.ArtifactHash() != expected {
+		t.Errorf("Expected ArtifactHash %s, got %s", expected, gb.ArtifactHash())
+	}
+	if b.Result().Hash != expected {
+		t.Errorf("Expected BuildResult.Hash %s, got %s", expected, b.Result().Hash)
+	}
+}
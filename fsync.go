@@ -0,0 +1,54 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// syncFile flushes the file contents to stable storage
+func syncFile(path string) error {
+	f, err := os.OpenFile(fixLongPath(path), os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// syncDir flushes the directory entry so a completed rename survives a power loss
+// Windows has no directory fsync, NTFS journals the rename itself
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+	d, err := os.Open(fixLongPath(dir))
+	if err != nil {
+		return err
+	}
+	if err := d.Sync(); err != nil {
+		d.Close()
+		return err
+	}
+	return d.Close()
+}
+
+// durablePromote wraps the rename with the fsync calls of Config.Fsync
+// The temp file is synced before the rename, its folder after it
+func (h *GoBuild) durablePromote(tempFileName string) error {
+	if err := syncFile(h.tempPath(tempFileName)); err != nil {
+		h.cleanupTempFile(tempFileName)
+		return errors.Join(errors.New("fsync temp file"), err)
+	}
+	if err := h.renameOutputFile(tempFileName); err != nil {
+		return err
+	}
+	if err := syncDir(filepath.Dir(h.FinalOutputPath())); err != nil {
+		return errors.Join(errors.New("fsync output folder"), err)
+	}
+	return nil
+}
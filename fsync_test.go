@@ -0,0 +1,35 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFsyncPromotion(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Fsync:                     true,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("CompileProgram failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "app"))
+	if err != nil || string(data) != "artifact" {
+		t.Errorf("Expected promoted artifact, got %q (%v)", data, err)
+	}
+}
+
+func TestSyncDir(t *testing.T) {
+	if err := syncDir(t.TempDir()); err != nil {
+		t.Errorf("syncDir failed: %v", err)
+	}
+	if err := syncFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error syncing a missing file")
+	}
+}
@@ -0,0 +1,43 @@
+package gobuild
+
+import (
+	"context"
+	"strings"
+)
+
+// Variables set by Config.StampGitInfo, declare them in the main package:
+//
+//	var version, commit, date string
+const (
+	gitVersionVar = "main.version" // git describe --tags --always --dirty, eg: v1.4.2-3-gabc1234-dirty
+	gitCommitVar  = "main.commit"  // full commit hash
+	gitDateVar    = "main.date"    // commit date (RFC 3339), stable across rebuilds so the cache keeps working
+)
+
+// gitStampArgs returns the -X flags describing the checkout in WorkDir
+// They come before CompilingArguments so a user -X of the same variable wins
+// A missing git or a folder outside a repository is logged and builds unstamped
+func (h *GoBuild) gitStampArgs(ctx context.Context, comp *Build) []string {
+	if !h.config.StampGitInfo {
+		return nil
+	}
+
+	version, err := h.git(ctx, h.config.WorkDir, "describe", "--tags", "--always", "--dirty")
+	if err != nil {
+		h.logf(LogWarn, comp.ID, "StampGitInfo:", err)
+		return nil
+	}
+	info, err := h.git(ctx, h.config.WorkDir, "log", "-1", "--format=%H %cI")
+	if err != nil {
+		h.logf(LogWarn, comp.ID, "StampGitInfo:", err)
+		return nil
+	}
+	commit, date, _ := strings.Cut(info, " ")
+	comp.version, comp.commit = version, commit
+
+	return []string{
+		"-X", gitVersionVar + "=" + version,
+		"-X", gitCommitVar + "=" + commit,
+		"-X", gitDateVar + "=" + date,
+	}
+}
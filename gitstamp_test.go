@@ -0,0 +1,76 @@
+package gobuild
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStampGitInfo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+		{"tag", "v1.4.2"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v %s", args, err, out)
+		}
+	}
+
+	log := filepath.Join(repo, "args")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, t.TempDir(), "echo \"$@\" >> "+log+"; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "out",
+		WorkDir:                   repo,
+		StampGitInfo:              true,
+		CompilingArguments:        func() []string { return []string{"-X", "main.date=override"} },
+	})
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	args := string(data)
+	for _, want := range []string{"-X main.version=v1.4.2", "-X main.commit=", "-X main.date="} {
+		if !strings.Contains(args, want) {
+			t.Errorf("Expected %q in %q", want, args)
+		}
+	}
+	if strings.Index(args, "main.date=override") < strings.Index(args, "-X main.date=") {
+		t.Errorf("User -X must come after the stamp to win, got %q", args)
+	}
+}
+
+func TestStampGitInfoOutsideRepository(t *testing.T) {
+	dir := t.TempDir()
+	var warnings []string
+	err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		WorkDir:                   dir,
+		StampGitInfo:              true,
+		LogSinks:                  []LogSink{{Level: LogWarn, Func: func(e LogEntry) { warnings = append(warnings, e.Message) }}},
+	}).CompileProgram()
+
+	if err != nil {
+		t.Fatalf("Expected an unstamped build, got %v", err)
+	}
+	if len(warnings) != 1 || !strings.HasPrefix(warnings[0], "StampGitInfo:") {
+		t.Errorf("Expected a StampGitInfo warning, got %q", warnings)
+	}
+}
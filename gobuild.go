@@ -2,20 +2,66 @@ package gobuild
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
-	"path"
 	"sync"
 	"time"
 )
 
-// compilation represents an active compilation process
-type compilation struct {
+// Build is a handle to a single compilation started with Start or CompileProgram
+// Several builds may overlap, each one can be waited on or cancelled on its own
+type Build struct {
+	ID        uint64 // unique per GoBuild instance, increases with each request
+	gb        *GoBuild
 	cmd       *exec.Cmd
-	cancel    context.CancelFunc
-	done      chan error
+	ctx       context.Context
+	cancel    context.CancelCauseFunc
+	done      chan struct{} // closed once err is set
+	err       error
+	result    *BuildResult
+	hash      string          // SHA-256 of the promoted artifact
+	size      int64           // size of the promoted artifact
+	sizeDelta int64           // size minus the previous promoted artifact
+	cacheKey  string          // source+flags hash, empty when caching is disabled
+	restored  bool            // artifact restored from the cache instead of compiled
+	argv      []string        // executed command line, for the audit record
+	envHash   string          // hash of the user env, for the audit record
+	diags     []Diagnostic    // parsed compiler output
+	output    string          // combined compiler stdout/stderr
+	exitCode  int             // compiler exit code, -1 if it didn't run to completion
+	firstErr  bool            // ErrorsFirst stopped the compiler at its first error
+	exitClass ExitClass       // exitCode per Config.ExitCodes
+	wasmSizes *WasmSizeReport // TinyGoWasm pipeline sizes
+	compress  *CompressReport // Config.Compress copy sizes
+	recovery  string          // Config.CacheRecovery remediation applied before the retry
+	modules   *ModuleChanges  // go.mod requirements changed since the previous build
+	failedDir string          // Config.QuarantineDir folder of the failed build
+	traceCtx  context.Context // BuildOptions.TraceContext
+	span      Span            // Config.TracerProvider span, nil when tracing is off
+	discard   bool            // Prewarm with Config.PrewarmDiscard: delete the artifact instead of promoting it
 	tempFile  string
+	label     string    // BuildOptions.Label, defaults to Config.Label
+	priority  int       // BuildOptions.Priority, orders the queue
+	args      []string  // BuildOptions.Args
+	salted    bool      // Config.BuildSalt or BuildOptions.Salt
+	salt      string    // random value linked into the artifact
+	version   string    // git describe with StampGitInfo, for FinalNameFunc
+	commit    string    // commit hash with StampGitInfo
+	namedPath string    // FinalNameFunc file
+	labels    Labels    // Config.Labels and BuildOptions.Labels
+	enqueued  time.Time // when the build was requested
 	startTime time.Time
+	deadline  time.Time      // moves forward with ExtendTimeout
+	timer     Timer          // cancels the compilation when the deadline is reached
+	paused    bool           // timer stopped while waiting for a Limiter slot, see waitSlot
+	remaining time.Duration  // timeout left when paused, ExtendTimeout adds to it
+	pid       int            // process started with Config.RunAfterBuild
+	progress  *buildProgress // phase and output tail for Config.Watchdog, nil without it
+
+	timings   BuildTimings // per phase durations, see BuildResult.Timings
+	lapStart  time.Time    // end of the previous timed phase
+	fetchedAt time.Time    // last "go: downloading" line of the compiler output
 }
 
 // GoBuild represents a Go compiler instance
@@ -24,10 +70,41 @@ type GoBuild struct {
 
 	// Thread-safe state
 	mu              sync.RWMutex
-	active          *compilation
-	outFileName     string // eg: main.exe, app
-	outTempFileName string // eg: app_temp.exe
-
+	lastID          uint64
+	active          *Build
+	cancelling      *Build     // detached by Cancel, still shutting down
+	queue           []*Build   // builds waiting for the active one to finish (CancelSoft keeps one, CancelQueue all)
+	outFileName     string     // eg: main.exe, app
+	outTempFileName string     // eg: app_temp.exe
+	artifactHash    string     // SHA-256 of the last promoted artifact
+	artifactSize    int64      // size of the last promoted artifact, for BuildResult.SizeDelta
+	vendorStamp     string     // hash of the module files at the last vendor sync
+	experimentsOK   string     // GOEXPERIMENT value the toolchain last accepted
+	lastDiags       string     // diagnosticsKey of the last reported build, for DedupeDiagnostics
+	lastFinish      time.Time  // when the last build ended, for idle cache maintenance
+	debounced       *Build     // request held by Config.Debounce, not submitted yet
+	debounceTimer   Timer      // submits debounced once the quiet period is over
+	debounceSince   time.Time  // first request of the current burst, for Config.DebounceMaxWait
+	logMu           sync.Mutex // serializes LogSink.Writer writes
+	wasmExecPath    string     // wasm_exec.js of the toolchain, for Config.CopyWasmExec
+	staging         bool       // read-only output folder, builds go through Config.StagingDir
+	appMu           sync.Mutex
+	app             *appProcess // binary started with Config.RunAfterBuild
+	archiveMu       sync.Mutex
+	archived        []string           // FinalNameFunc files of this process, oldest first, for Config.KeepArtifacts
+	pids            map[int]bool       // RegisterPID processes
+	namedFiles      map[string]bool    // FinalNameFunc file names published by this process
+	envMu           sync.Mutex         // guards envOverrides
+	envOverrides    map[string]*string // SetEnv values, nil for UnsetEnv
+	requires        map[string]string  // go.mod requirements at the last build, for ModuleChanges, guarded by mu
+	historyMu       sync.Mutex
+	history         []BuildSummary // Config.HistorySize ring
+	historyNext     int            // next slot to overwrite once the ring is full
+	lastResult      *BuildResult   // last build that ran, for Status, guarded by mu
+	hookMu          sync.Mutex
+	hooks           map[string]*webhookQueue // Config.Webhooks deliveries by URL
+	hookCtx         context.Context          // cancelled by Close, stops the deliveries
+	hookStop        context.CancelFunc
 }
 
 // New creates a new GoBuild instance with the given configuration
@@ -36,6 +113,12 @@ func New(c *Config) *GoBuild {
 	if c.Timeout == 0 {
 		c.Timeout = 5 * time.Second
 	}
+	if (c.TargetWASM || c.TinyGoWasm != nil) && c.Extension == "" {
+		c.Extension = wasmExtension
+	}
+	if d, ok := DeviceProfiles[c.Device]; ok && c.Extension == "" {
+		c.Extension = d.Extension
+	}
 
 	return &GoBuild{
 		config:          c,
@@ -47,63 +130,186 @@ func New(c *Config) *GoBuild {
 // CompileProgram compiles the Go program
 // If a callback is configured, it runs asynchronously and returns immediately
 // Otherwise, it runs synchronously and returns the compilation result
-// Thread-safe: cancels any previous compilation automatically
+// Thread-safe: cancels any previous compilation automatically (see Config.CancelMode)
 func (h *GoBuild) CompileProgram() error {
-	h.mu.Lock()
+	comp := h.Start()
 
-	// Cancel any active compilation
-	if h.active != nil {
-		h.active.cancel()
-		// Don't wait for it to finish, just move on
-		h.active = nil
+	// If callback is defined, the result is delivered asynchronously
+	if h.config.Callback != nil {
+		return nil
 	}
 
-	// Create new compilation context
-	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	return comp.Wait()
+}
+
+// Compile compiles the Go program and waits for it, even if a Callback is configured
+// The result is returned on failure too, with the compiler output and exit code
+func (h *GoBuild) Compile() (*BuildResult, error) {
+	comp := h.Start()
+	err := comp.Wait()
+	return comp.Result(), err
+}
 
-	// Generate unique temp file name to avoid conflicts
-	tempFileName := fmt.Sprintf("%s_temp_%d%s",
-		h.config.OutName,
-		time.Now().UnixNano(),
-		h.config.Extension)
+// Start requests a new compilation and returns its handle without waiting for it
+// The configured Callback (if any) is still invoked when the build finishes
+func (h *GoBuild) Start() *Build {
+	return h.StartWith(BuildOptions{})
+}
 
-	comp := &compilation{
-		cancel:    cancel,
-		done:      make(chan error, 1),
-		tempFile:  tempFileName,
-		startTime: time.Now(),
+// StartWith is Start with a label and priority for the queue, see PendingBuilds
+// With Config.Debounce the build waits for the quiet period, requests made meanwhile share it
+// (requests with BuildOptions.Args, Salt or Labels are never coalesced, they start right away)
+func (h *GoBuild) StartWith(opts BuildOptions) *Build {
+	if h.config.Debounce > 0 && len(opts.Args) == 0 && !opts.Salt && len(opts.Labels) == 0 {
+		return h.debounce(opts)
+	}
+	comp := h.newCompilation()
+	comp.label = opts.Label
+	if comp.label == "" {
+		comp.label = h.config.Label
 	}
+	comp.priority = opts.Priority
+	comp.args = append([]string(nil), opts.Args...)
+	comp.salted = h.config.BuildSalt || opts.Salt
+	comp.labels = h.buildLabels(opts.Labels)
+	comp.traceCtx = opts.TraceContext
+	h.submit(comp)
+	return comp
+}
 
-	h.active = comp
+// newCompilation prepares a compilation that has not started yet
+func (h *GoBuild) newCompilation() *Build {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	h.mu.Lock()
+	h.lastID++
+	id := h.lastID
 	h.mu.Unlock()
 
-	// If callback is defined, run asynchronously
-	if h.config.Callback != nil {
-		go func() {
-			err := h.compileSync(ctx, comp)
-			h.config.Callback(err)
-
-			// Clean up active compilation
-			h.mu.Lock()
-			if h.active == comp {
-				h.active = nil
-			}
-			h.mu.Unlock()
-		}()
-		return nil
+	// Generate unique temp file name to avoid conflicts
+	tempFileName := h.newTempFileName(id)
+
+	return &Build{
+		ID:       id,
+		gb:       h,
+		ctx:      ctx,
+		cancel:   cancel,
+		done:     make(chan struct{}),
+		exitCode: -1,
+		tempFile: tempFileName,
+		enqueued: h.now(),
 	}
+}
 
-	// Run synchronously
-	err := h.compileSync(ctx, comp)
+// submit starts comp or, when another compilation is running, applies the cancel mode
+func (h *GoBuild) submit(comp *Build) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.active == nil {
+		h.start(comp)
+		return
+	}
+
+	switch h.config.CancelMode {
+	case CancelSoft:
+		// Keep the running compilation, only the latest request waits for it
+		for _, queued := range h.queue {
+			h.drop(queued, ErrSuperseded)
+		}
+		h.queue = []*Build{comp}
+	case CancelQueue:
+		h.enqueue(comp)
+	default:
+		// Don't wait for the previous one to finish, just move on
+		h.active.cancel(ErrSuperseded)
+		h.start(comp)
+	}
+}
+
+// start launches comp as the active compilation
+// Must be called with h.mu held
+func (h *GoBuild) start(comp *Build) {
+	h.active = comp
+	comp.startTime = h.now()
+	comp.lapStart = comp.startTime
+	comp.timings.Queued = comp.startTime.Sub(comp.enqueued)
+	// The deadline is enforced by a timer so it can be extended
+	comp.deadline = comp.startTime.Add(h.config.Timeout)
+	comp.timer = h.clock().AfterFunc(h.config.Timeout, func() { comp.cancel(ErrTimeout) })
+	h.startWatchdog(comp)
+
+	go h.run(comp)
+}
+
+// run compiles and then hands the active slot to the next queued compilation, if any
+func (h *GoBuild) run(comp *Build) {
+	h.hookStart(comp)
+	h.eventStarted(comp)
+	h.startSpan(comp)
+	err := withCode(h.compileSync(comp.ctx, comp))
+	// a superseded build would start an app the next build replaces right away
+	if err == nil && h.config.RunAfterBuild && !comp.discard && !errors.Is(context.Cause(comp.ctx), ErrSuperseded) {
+		if rerr := h.startApp(comp); rerr != nil {
+			h.logf(LogError, comp.ID, rerr)
+		}
+	}
+	attachDiagnostics(err, comp.diags)
+	comp.stop()
+	if err != nil {
+		h.quarantine(comp, err)
+		h.discardTempFile(comp)
+	}
+	h.audit(comp, err)
+	h.logFinished(comp, err)
 
-	// Clean up
 	h.mu.Lock()
+	h.lastFinish = h.now()
+	if h.cancelling == comp {
+		h.cancelling = nil
+	}
 	if h.active == comp {
 		h.active = nil
+		if len(h.queue) > 0 {
+			next := h.queue[0]
+			h.queue = h.queue[1:]
+			h.start(next)
+		}
 	}
 	h.mu.Unlock()
 
-	return err
+	h.notify(comp, err)
+}
+
+// drop discards a compilation that never started
+// Must be called with h.mu held, the caller is notified without the lock
+func (h *GoBuild) drop(comp *Build, cause error) {
+	comp.cancel(cause)
+	go h.notify(comp, fmt.Errorf("%w: dropped before start", cause))
+}
+
+// notify publishes the compilation result to waiters and the callback
+func (h *GoBuild) notify(comp *Build, err error) {
+	err = withCode(err)
+	comp.result = h.newBuildResult(comp, err)
+	comp.err = err
+	// Hooks run before waiters are released so Wait observes their side effects
+	h.hookResult(comp)
+	h.eventFinished(comp)
+	h.observeMetrics(comp)
+	h.endSpan(comp)
+	h.recordHistory(comp)
+	if !comp.startTime.IsZero() && !comp.discard {
+		h.mu.Lock()
+		h.lastResult = comp.result
+		h.mu.Unlock()
+	}
+	h.sendWebhooks(comp)
+	close(comp.done)
+
+	if h.config.Callback != nil && !comp.discard {
+		h.config.Callback(err)
+	}
 }
 
 // Cancel cancels any active compilation
@@ -111,8 +317,17 @@ func (h *GoBuild) Cancel() error {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	for _, queued := range h.queue {
+		h.drop(queued, ErrCancelled)
+	}
+	h.queue = nil
+	if h.debounced != nil {
+		h.drop(h.undebounce(h.debounced.ID), ErrCancelled)
+	}
+
 	if h.active != nil {
-		h.active.cancel()
+		h.active.cancel(ErrCancelled)
+		h.cancelling = h.active
 		h.active = nil
 		return nil
 	}
@@ -120,6 +335,14 @@ func (h *GoBuild) Cancel() error {
 	return nil // No active compilation to cancel
 }
 
+// Close cancels the compilations (see Cancel) and the webhook deliveries still
+// retrying or waiting for their turn, call it when shutting the builder down
+func (h *GoBuild) Close() error {
+	h.webhookContext()
+	h.hookStop()
+	return h.Cancel()
+}
+
 // IsCompiling returns true if there's an active compilation
 func (h *GoBuild) IsCompiling() bool {
 	h.mu.RLock()
@@ -156,7 +379,7 @@ func (h *GoBuild) MainInputFileRelativePath() string {
 }
 
 // FinalOutputPath returns the full path to the final output file
-// eg: web/build/main.wasm
+// eg: web/build/main.wasm, or myproject/web/build/main.wasm with WorkDir "myproject"
 func (h *GoBuild) FinalOutputPath() string {
-	return path.Join(h.config.OutFolderRelativePath, h.outFileName)
+	return h.outPath(h.outFileName)
 }
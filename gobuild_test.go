@@ -3,6 +3,9 @@ package gobuild
 import (
 	"bytes"
 	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 )
@@ -121,3 +124,18 @@ func TestCompileProgramAsync(t *testing.T) {
 		t.Error("Callback was not called within timeout")
 	}
 }
+
+// writeFakeCompiler creates an executable shell script acting as the compiler
+// The script receives the same arguments as "go" would: build ... -o <out> <main>
+// Tests using it are skipped on windows
+func writeFakeCompiler(t *testing.T, dir, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake compiler scripts require a unix shell")
+	}
+	path := filepath.Join(dir, "fakecompiler.sh")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatalf("Failed to create fake compiler: %v", err)
+	}
+	return path
+}
@@ -0,0 +1,196 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CacheMaintenance keeps the go build cache (GOCACHE) of long-lived build daemons
+// within a byte budget, see StartCacheMaintenance
+type CacheMaintenance struct {
+	MaxBytes  int64             // GOCACHE budget, eg: 5 << 30 (5 GiB)
+	CleanAll  bool              // run go clean -cache when over budget instead of trimming the oldest entries
+	IdleAfter time.Duration     // time without builds before maintaining, defaults to 1 minute
+	Report    func(CacheReport) // optional, receives the outcome of each pass
+}
+
+// CacheUsage is the size of the go build cache
+type CacheUsage struct {
+	Dir   string // eg: /home/user/.cache/go-build
+	Bytes int64
+	Files int
+}
+
+// CacheReport describes one maintenance pass
+type CacheReport struct {
+	Before  CacheUsage
+	After   CacheUsage
+	Cleaned bool  // go clean -cache was run
+	Err     error // nil on success
+}
+
+// GoCacheUsage returns the location and size of the go build cache used by the builds
+func (h *GoBuild) GoCacheUsage(ctx context.Context) (CacheUsage, error) {
+	dir, err := h.goCacheDir(ctx)
+	if err != nil {
+		return CacheUsage{}, err
+	}
+	usage := CacheUsage{Dir: dir}
+	err = filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		if info, err := d.Info(); err == nil {
+			usage.Bytes += info.Size()
+			usage.Files++
+		}
+		return nil
+	})
+	return usage, err
+}
+
+// TrimGoCache brings the go build cache under maxBytes, removing the least
+// recently used entries first (the go command refreshes the mtime of used entries)
+// With cleanAll it runs go clean -cache instead once the budget is exceeded
+func (h *GoBuild) TrimGoCache(ctx context.Context, maxBytes int64, cleanAll bool) CacheReport {
+	var r CacheReport
+	r.Before, r.Err = h.GoCacheUsage(ctx)
+	if r.Err != nil || r.Before.Bytes <= maxBytes {
+		r.After = r.Before
+		return r
+	}
+
+	if cleanAll {
+		cmd := exec.CommandContext(ctx, h.goTool(), "clean", "-cache")
+		cmd.Env = h.maintenanceEnv()
+		if output, err := cmd.CombinedOutput(); err != nil {
+			r.Err = fmt.Errorf("go clean -cache: %v %s", err, strings.TrimSpace(h.decodeOutput(output)))
+		}
+		r.Cleaned = r.Err == nil
+	} else {
+		r.Err = trimOldest(r.Before, maxBytes)
+	}
+
+	after, err := h.GoCacheUsage(ctx)
+	r.After = after
+	if r.Err == nil {
+		r.Err = err
+	}
+	return r
+}
+
+// trimOldest removes cache entries oldest first until usage fits in maxBytes
+// Only the hashed entry folders (eg: 3f/) are touched, not README or trim.txt
+func trimOldest(usage CacheUsage, maxBytes int64) error {
+	type entry struct {
+		path string
+		size int64
+		mod  time.Time
+	}
+	var entries []entry
+	dirs, err := os.ReadDir(usage.Dir)
+	if err != nil {
+		return err
+	}
+	for _, d := range dirs {
+		if !d.IsDir() || len(d.Name()) != 2 {
+			continue
+		}
+		files, _ := os.ReadDir(filepath.Join(usage.Dir, d.Name()))
+		for _, f := range files {
+			if info, err := f.Info(); err == nil && !f.IsDir() {
+				entries = append(entries, entry{filepath.Join(usage.Dir, d.Name(), f.Name()), info.Size(), info.ModTime()})
+			}
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].mod.Before(entries[j].mod) })
+
+	size := usage.Bytes
+	var errs []error
+	for _, e := range entries {
+		if size <= maxBytes {
+			break
+		}
+		if err := os.Remove(e.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+		size -= e.size
+	}
+	return errors.Join(errs...)
+}
+
+// StartCacheMaintenance runs Config.CacheMaintenance in the background until ctx is done
+// A pass runs once no build has finished for IdleAfter and none is running,
+// then again only after new builds
+func (h *GoBuild) StartCacheMaintenance(ctx context.Context) error {
+	m := h.config.CacheMaintenance
+	if m == nil || m.MaxBytes <= 0 {
+		return errors.New("StartCacheMaintenance: Config.CacheMaintenance.MaxBytes required")
+	}
+	idle := m.IdleAfter
+	if idle <= 0 {
+		idle = time.Minute
+	}
+
+	go func() {
+		ticker := h.clock().NewTicker(idle / 2)
+		defer ticker.Stop()
+		var maintained time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C():
+			}
+
+			h.mu.RLock()
+			busy := h.active != nil || len(h.queue) > 0
+			last := h.lastFinish
+			h.mu.RUnlock()
+			if busy || h.now().Sub(last) < idle || (!maintained.IsZero() && !last.After(maintained)) {
+				continue
+			}
+
+			r := h.TrimGoCache(ctx, m.MaxBytes, m.CleanAll)
+			maintained = h.now()
+			if m.Report != nil {
+				m.Report(r)
+			}
+		}
+	}()
+	return nil
+}
+
+// goCacheDir asks the go command for GOCACHE with the build environment
+func (h *GoBuild) goCacheDir(ctx context.Context) (string, error) {
+	cmd := exec.CommandContext(ctx, h.goTool(), "env", "GOCACHE")
+	cmd.Env = h.maintenanceEnv()
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("go env GOCACHE: %w", err)
+	}
+	dir := strings.TrimSpace(string(output))
+	if dir == "" || dir == "off" {
+		return "", errors.New("go env GOCACHE: build cache disabled")
+	}
+	return dir, nil
+}
+
+// maintenanceEnv returns the build environment for go maintenance commands
+func (h *GoBuild) maintenanceEnv() []string {
+	userEnv, _ := h.userEnv()
+	env := h.environment(userEnv)
+	if env == nil {
+		env = h.hostEnv()
+	}
+	return env
+}
@@ -0,0 +1,92 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newFakeGoCache creates a GOCACHE with n entries of 1 KiB, entry i is i minutes old
+func newFakeGoCache(t *testing.T, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+	for i := 0; i < n; i++ {
+		sub := filepath.Join(dir, "0"+string(rune('a'+i)))
+		if err := os.MkdirAll(sub, 0755); err != nil {
+			t.Fatal(err)
+		}
+		p := filepath.Join(sub, "entry-d")
+		if err := os.WriteFile(p, make([]byte, 1024), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-time.Duration(i) * time.Minute)
+		os.Chtimes(p, old, old)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("cache"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestTrimGoCache(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	cache := newFakeGoCache(t, 4)
+	gb := New(&Config{Command: goBin, Env: []string{"GOCACHE=" + cache}})
+
+	r := gb.TrimGoCache(context.Background(), 2*1024+100, false)
+	if r.Err != nil {
+		t.Fatalf("TrimGoCache failed: %v", r.Err)
+	}
+	if r.Before.Dir != cache || r.Before.Files != 5 || r.After.Files != 3 {
+		t.Errorf("Expected 5 files trimmed to 3 in %s, got %+v", cache, r)
+	}
+	// the oldest entries go first, the README stays
+	for _, p := range []string{"0a/entry-d", "0b/entry-d", "README"} {
+		if _, err := os.Stat(filepath.Join(cache, p)); err != nil {
+			t.Errorf("Expected %s kept: %v", p, err)
+		}
+	}
+}
+
+func TestStartCacheMaintenance(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+	cache := newFakeGoCache(t, 3)
+	reports := make(chan CacheReport, 1)
+	gb := New(&Config{
+		Command: goBin,
+		Env:     []string{"GOCACHE=" + cache},
+		CacheMaintenance: &CacheMaintenance{
+			MaxBytes:  1024,
+			IdleAfter: 100 * time.Millisecond,
+			Report:    func(r CacheReport) { reports <- r },
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := gb.StartCacheMaintenance(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case r := <-reports:
+		if r.Err != nil || r.After.Bytes > 1024 {
+			t.Errorf("Expected cache within budget, got %+v", r)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected a maintenance pass while idle")
+	}
+
+	if err := New(&Config{}).StartCacheMaintenance(ctx); err == nil {
+		t.Error("Expected error without CacheMaintenance")
+	}
+}
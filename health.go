@@ -0,0 +1,66 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// HealthStatus is the JSON body of the HealthHandler endpoints
+type HealthStatus struct {
+	Status    string `json:"status"`               // "ok" or "unavailable"
+	Toolchain string `json:"toolchain"`            // executable of Command/CommandLine, eg: "go"
+	Reason    string `json:"reason,omitempty"`     // why /readyz failed
+	Compiling bool   `json:"compiling"`            // a build is running
+	Queued    int    `json:"queued"`               // builds waiting to start, see PendingBuilds
+	MaxQueued int    `json:"max_queued,omitempty"` // readiness limit, 0 when unlimited
+}
+
+// HealthHandler serves /healthz (the process is up) and /readyz (the toolchain is on the
+// PATH and at most maxQueued builds are waiting, 0 for no limit) for orchestrators
+// supervising a build service, any path not ending in /healthz is a readiness check
+// eg: health := gb.HealthHandler(10); mux.Handle("/healthz", health); mux.Handle("/readyz", health)
+// Failed checks answer 503 so a saturated or broken instance is taken out of rotation
+func (h *GoBuild) HealthHandler(maxQueued int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, _ := h.commandLine(nil)
+		h.mu.RLock()
+		s := HealthStatus{
+			Status:    "ok",
+			Toolchain: name,
+			Compiling: h.active != nil,
+			Queued:    len(h.queue),
+			MaxQueued: maxQueued,
+		}
+		h.mu.RUnlock()
+
+		code := http.StatusOK
+		if !strings.HasSuffix(r.URL.Path, "/healthz") {
+			if reason := h.unready(name, s.Queued, maxQueued); reason != "" {
+				s.Status, s.Reason = "unavailable", reason
+				code = http.StatusServiceUnavailable
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		json.NewEncoder(w).Encode(s)
+	})
+}
+
+// unready returns why the builder can't take builds, empty when it can
+func (h *GoBuild) unready(toolchain string, queued, maxQueued int) string {
+	if h.config.Agent == nil && h.config.Runner == nil {
+		if toolchain == "" {
+			return "no Command configured"
+		}
+		if _, err := exec.LookPath(toolchain); err != nil {
+			return "toolchain not available: " + err.Error()
+		}
+	}
+	if maxQueued > 0 && queued > maxQueued {
+		return "queue full"
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHealthHandler(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "sleep 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Timeout:                   5 * time.Second,
+		CancelMode:                CancelQueue,
+	})
+	health := gb.HealthHandler(1)
+
+	get := func(path string) (int, HealthStatus) {
+		rec := httptest.NewRecorder()
+		health.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var s HealthStatus
+		if err := json.NewDecoder(rec.Body).Decode(&s); err != nil {
+			t.Fatal(err)
+		}
+		return rec.Code, s
+	}
+
+	if code, s := get("/readyz"); code != http.StatusOK || s.Status != "ok" || s.Compiling {
+		t.Errorf("Expected an idle ready builder, got %d %+v", code, s)
+	}
+
+	builds := []*Build{gb.Start(), gb.Start(), gb.Start()}
+	defer gb.Cancel()
+	if code, s := get("/readyz"); code != http.StatusServiceUnavailable || s.Reason != "queue full" || s.Queued != 2 {
+		t.Errorf("Expected the full queue reported, got %d %+v", code, s)
+	}
+	if code, _ := get("/healthz"); code != http.StatusOK {
+		t.Errorf("Expected liveness regardless of the queue, got %d", code)
+	}
+	gb.Cancel()
+	for _, b := range builds {
+		b.Wait()
+	}
+
+	health = New(&Config{Command: "nonexistent-toolchain"}).HealthHandler(0)
+	if code, s := get("/readyz"); code != http.StatusServiceUnavailable || s.Toolchain != "nonexistent-toolchain" {
+		t.Errorf("Expected the missing toolchain reported, got %d %+v", code, s)
+	}
+}
@@ -0,0 +1,107 @@
+package gobuild
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// BuildSummary is the History record of a finished build, small enough to keep many
+type BuildSummary struct {
+	ID        uint64        `json:"id"`
+	Label     string        `json:"label,omitempty"`
+	StartTime time.Time     `json:"start_time"`
+	EndTime   time.Time     `json:"end_time"`
+	Duration  time.Duration `json:"duration"`
+	Success   bool          `json:"success"`
+	Code      ErrorCode     `json:"code,omitempty"`
+	Failure   FailureKind   `json:"failure,omitempty"`
+	Error     string        `json:"error,omitempty"` // first compiler error or first line of Err, eg: "main.go:3:1: undefined: a"
+	Size      int64         `json:"size,omitempty"`
+	Hash      string        `json:"sha256,omitempty"`
+	Restored  bool          `json:"restored,omitempty"`
+}
+
+// HistoryQuery selects History entries, the zero value matches everything
+type HistoryQuery struct {
+	Limit  int       // newest entries returned, 0 for all
+	Failed bool      // only failed builds
+	Label  string    // only builds with this label
+	Since  time.Time // only builds finished after this time
+}
+
+// History returns the last Config.HistorySize builds that ran, oldest first
+// eg: rendering "last 10 builds" in a dev dashboard
+func (h *GoBuild) History() []BuildSummary {
+	return h.QueryHistory(HistoryQuery{})
+}
+
+// QueryHistory returns the History entries matching q, oldest first
+func (h *GoBuild) QueryHistory(q HistoryQuery) []BuildSummary {
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+
+	var out []BuildSummary
+	n := len(h.history)
+	for i := 0; i < n; i++ {
+		s := h.history[(h.historyNext+i)%n] // historyNext is the oldest once the ring is full
+		if (q.Failed && s.Success) || (q.Label != "" && s.Label != q.Label) || (!q.Since.IsZero() && !s.EndTime.After(q.Since)) {
+			continue
+		}
+		out = append(out, s)
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[len(out)-q.Limit:]
+	}
+	return out
+}
+
+// recordHistory adds comp to the Config.HistorySize ring, builds dropped before
+// starting and discarded prewarm builds are left out
+func (h *GoBuild) recordHistory(comp *Build) {
+	size := h.config.HistorySize
+	r := comp.result
+	if size <= 0 || comp.discard || r.StartTime.IsZero() {
+		return
+	}
+	s := BuildSummary{
+		ID:        r.ID,
+		Label:     comp.label,
+		StartTime: r.StartTime,
+		EndTime:   r.EndTime,
+		Duration:  r.Duration,
+		Success:   r.Err == nil,
+		Code:      r.Code,
+		Failure:   r.Failure,
+		Size:      r.Size,
+		Hash:      r.Hash,
+		Restored:  r.RestoredFromCache,
+	}
+	if r.Err != nil {
+		s.Error = errorSummary(r)
+	}
+
+	h.historyMu.Lock()
+	defer h.historyMu.Unlock()
+	if len(h.history) < size {
+		h.history = append(h.history, s)
+		return
+	}
+	h.history[h.historyNext] = s
+	h.historyNext = (h.historyNext + 1) % size
+}
+
+// errorSummary returns the first compiler error of r, or the first line of its Err
+func errorSummary(r *BuildResult) string {
+	for _, d := range r.Diagnostics {
+		if d.Severity != SeverityError {
+			continue
+		}
+		if d.File == "" {
+			return d.Message
+		}
+		return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+	}
+	line, _, _ := strings.Cut(r.Err.Error(), "\n")
+	return line
+}
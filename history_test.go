@@ -0,0 +1,41 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHistoryRing(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, `if [ -e `+broken+` ]; then echo './main.go:3:1: undefined: a' >&2; exit 1; fi`+"\n"+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		HistorySize:               2,
+	})
+
+	gb.CompileProgram()
+	os.WriteFile(broken, nil, 0o644)
+	gb.CompileProgram()
+	os.Remove(broken)
+	gb.CompileProgram()
+
+	history := gb.History()
+	if len(history) != 2 || history[0].ID != 2 || history[1].ID != 3 {
+		t.Fatalf("Expected builds 2 and 3, got %+v", history)
+	}
+	if !history[1].Success || history[1].Size != int64(len("artifact")) {
+		t.Errorf("Unexpected summary of build 3: %+v", history[1])
+	}
+
+	failed := gb.QueryHistory(HistoryQuery{Failed: true})
+	if len(failed) != 1 || failed[0].ID != 2 || failed[0].Error != "./main.go:3:1: undefined: a" {
+		t.Errorf("Expected build 2 with its first error, got %+v", failed)
+	}
+	if last := gb.QueryHistory(HistoryQuery{Limit: 1}); len(last) != 1 || last[0].ID != 3 {
+		t.Errorf("Expected only build 3, got %+v", last)
+	}
+}
@@ -0,0 +1,35 @@
+package gobuild
+
+import "time"
+
+// StartHook is called when a build leaves the queue and the compiler pipeline begins
+type StartHook func(id uint64, start time.Time)
+
+// ResultHook receives the result of a finished build: ID, StartTime, EndTime and Duration included
+type ResultHook func(*BuildResult)
+
+// hookStart runs Config.OnStart for comp
+func (h *GoBuild) hookStart(comp *Build) {
+	if h.config.OnStart != nil && !comp.discard {
+		h.config.OnStart(comp.ID, comp.startTime)
+	}
+}
+
+// hookResult runs the terminal hook matching the build outcome
+// Builds dropped from the queue never started, they only get OnCancel
+func (h *GoBuild) hookResult(comp *Build) {
+	if comp.discard {
+		return
+	}
+	r := comp.result
+	hook := h.config.OnFailure
+	switch {
+	case r.Err == nil:
+		hook = h.config.OnSuccess
+	case r.Code == ErrCodeCancelled:
+		hook = h.config.OnCancel
+	}
+	if hook != nil {
+		hook(r)
+	}
+}
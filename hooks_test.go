@@ -0,0 +1,109 @@
+package gobuild
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// hookRecorder collects lifecycle hook calls
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+	starts map[uint64]time.Time
+	last   *BuildResult
+}
+
+func (r *hookRecorder) install(c *Config) {
+	r.starts = map[uint64]time.Time{}
+	c.OnStart = func(id uint64, start time.Time) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, "start")
+		r.starts[id] = start
+	}
+	record := func(name string) ResultHook {
+		return func(res *BuildResult) {
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			r.events = append(r.events, name)
+			r.last = res
+		}
+	}
+	c.OnSuccess = record("success")
+	c.OnFailure = record("failure")
+	c.OnCancel = record("cancel")
+}
+
+func (r *hookRecorder) snapshot() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestLifecycleHooksSuccess(t *testing.T) {
+	dir := t.TempDir()
+	c := &Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}
+	var rec hookRecorder
+	rec.install(c)
+
+	b := New(c).Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if got := rec.snapshot(); len(got) != 2 || got[0] != "start" || got[1] != "success" {
+		t.Fatalf("Expected start, success; got %v", got)
+	}
+	if rec.last.ID != b.ID || !rec.last.StartTime.Equal(rec.starts[b.ID]) || rec.last.Duration <= 0 {
+		t.Errorf("Unexpected hook timing: %+v (start %v)", rec.last, rec.starts[b.ID])
+	}
+}
+
+func TestLifecycleHooksFailure(t *testing.T) {
+	dir := t.TempDir()
+	c := &Config{
+		Command:                   writeFakeCompiler(t, dir, "exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}
+	var rec hookRecorder
+	rec.install(c)
+
+	if err := New(c).CompileProgram(); err == nil {
+		t.Fatal("Expected compile error")
+	}
+	if got := rec.snapshot(); len(got) != 2 || got[1] != "failure" {
+		t.Fatalf("Expected start, failure; got %v", got)
+	}
+	if rec.last.Code != ErrCodeCompile {
+		t.Errorf("Expected E_COMPILE, got %q", rec.last.Code)
+	}
+}
+
+func TestLifecycleHooksCancel(t *testing.T) {
+	dir := t.TempDir()
+	c := &Config{
+		Command:                   writeFakeCompiler(t, dir, "exec sleep 5"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}
+	var rec hookRecorder
+	rec.install(c)
+	gb := New(c)
+
+	b := gb.Start()
+	gb.Cancel()
+	b.Wait()
+
+	if got := rec.snapshot(); len(got) != 2 || got[1] != "cancel" {
+		t.Fatalf("Expected start, cancel; got %v", got)
+	}
+}
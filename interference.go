@@ -0,0 +1,85 @@
+package gobuild
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// Interference kinds reported by InterferenceError
+const (
+	InterferenceAccessDenied = "access-denied" // permission error on a file we just wrote
+	InterferenceVanished     = "file-vanished" // the freshly written file disappeared
+	InterferenceLocked       = "locked"        // another process holds the file open (Windows sharing violation)
+)
+
+// syncFolderMarkers are path segments of folders kept in sync by cloud clients
+var syncFolderMarkers = []string{"OneDrive", "Dropbox", "Google Drive", "iCloud Drive", "iCloudDrive"}
+
+// InterferenceError is a promotion failure matching the signature of another program
+// touching the fresh artifact (antivirus scan/quarantine, OneDrive/Dropbox sync)
+// Hint is a remediation suggestion meant to be shown to the user as is
+type InterferenceError struct {
+	Kind       string // eg: InterferenceLocked
+	Path       string
+	SyncFolder string // cloud client owning the output folder, eg: "OneDrive", empty if none
+	Hint       string
+	Err        error
+}
+
+func (e *InterferenceError) Error() string {
+	return e.Err.Error() + " (" + e.Kind + ": " + e.Hint + ")"
+}
+
+func (e *InterferenceError) Unwrap() error {
+	return e.Err
+}
+
+// diagnoseInterference wraps err in an *InterferenceError when it matches a known
+// signature for path, otherwise err is returned unchanged
+func diagnoseInterference(path string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	ie := &InterferenceError{Path: path, SyncFolder: syncFolder(path), Err: err}
+	var errno syscall.Errno
+	switch {
+	case runtime.GOOS == "windows" && errors.As(err, &errno) && (errno == 32 || errno == 33):
+		// ERROR_SHARING_VIOLATION, ERROR_LOCK_VIOLATION
+		ie.Kind = InterferenceLocked
+		ie.Hint = "close the running app; if it isn't running an antivirus or sync client is scanning the file, retry or exclude the output folder"
+	case errors.Is(err, fs.ErrPermission):
+		ie.Kind = InterferenceAccessDenied
+		ie.Hint = "an antivirus (eg: Windows Defender) may be scanning the new binary, add the output folder to its exclusions"
+	case errors.Is(err, fs.ErrNotExist):
+		ie.Kind = InterferenceVanished
+		ie.Hint = "the file was removed right after being written, check the antivirus quarantine history"
+	default:
+		return err
+	}
+
+	if ie.SyncFolder != "" {
+		ie.Hint = "the output folder is synced by " + ie.SyncFolder + ", move OutFolderRelativePath out of it or pause syncing; " + ie.Hint
+	}
+	return ie
+}
+
+// syncFolder returns the cloud client whose folder contains path, empty if none
+func syncFolder(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	for _, segment := range strings.Split(filepath.ToSlash(path), "/") {
+		for _, marker := range syncFolderMarkers {
+			// eg: "OneDrive", "OneDrive - Contoso", "Dropbox (Personal)"
+			if strings.HasPrefix(segment, marker) {
+				return marker
+			}
+		}
+	}
+	return ""
+}
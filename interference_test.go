@@ -0,0 +1,63 @@
+package gobuild
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+func TestDiagnoseInterference(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		err  error
+		kind string
+		sync string
+	}{
+		{"access denied", "/home/u/app/build/app", syscall.EACCES, InterferenceAccessDenied, ""},
+		{"vanished", "/home/u/app/build/app_temp", fs.ErrNotExist, InterferenceVanished, ""},
+		{"onedrive", "/Users/u/OneDrive - Contoso/app/build/app", fs.ErrPermission, InterferenceAccessDenied, "OneDrive"},
+		{"dropbox", "/home/u/Dropbox (Personal)/app/app", fs.ErrNotExist, InterferenceVanished, "Dropbox"},
+		{"unrelated", "/home/u/app/build/app", errors.New("disk full"), "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := diagnoseInterference(filepath.FromSlash(tt.path), tt.err)
+			var ie *InterferenceError
+			if !errors.As(err, &ie) {
+				if tt.kind != "" {
+					t.Fatalf("Expected *InterferenceError, got %v", err)
+				}
+				if err != tt.err {
+					t.Errorf("Unrelated errors must be returned unchanged, got %v", err)
+				}
+				return
+			}
+			if ie.Kind != tt.kind || ie.SyncFolder != tt.sync || ie.Hint == "" {
+				t.Errorf("Expected kind %q sync %q with a hint, got %+v", tt.kind, tt.sync, ie)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("Original error must stay in the chain, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPromoteVanishedTempFile(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "exit 0"), // "succeeds" but the file is gone
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+
+	err := gb.CompileProgram()
+	var ie *InterferenceError
+	if !errors.As(err, &ie) || ie.Kind != InterferenceVanished {
+		t.Errorf("Expected %s diagnostic, got %v", InterferenceVanished, err)
+	}
+}
@@ -0,0 +1,105 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"time"
+)
+
+// manifestSuffix is appended to the artifact name for Config.Manifest, eg: app.exe.json
+const manifestSuffix = ".json"
+
+// Labels are key/value build metadata, eg: {"pipeline": "4711", "pr": "42"}
+type Labels map[string]string
+
+// Manifest is the JSON sidecar written next to the artifact with Config.Manifest
+type Manifest struct {
+	BuildID  uint64    `json:"build_id"`
+	Label    string    `json:"label,omitempty"`
+	Labels   Labels    `json:"labels,omitempty"` // Config.Labels and BuildOptions.Labels
+	Artifact string    `json:"artifact"`         // file name, eg: "app.exe"
+	Named    string    `json:"named,omitempty"`  // Config.FinalNameFunc file name
+	Hash     string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	GOOS     string    `json:"goos"`
+	GOARCH   string    `json:"goarch"`
+	Version  string    `json:"version,omitempty"`
+	Commit   string    `json:"commit,omitempty"`
+	Salt     string    `json:"salt,omitempty"`
+	Restored bool      `json:"restored,omitempty"` // served from the artifact cache
+	Time     time.Time `json:"time"`
+}
+
+// buildLabels returns Config.Labels with extra taking precedence, nil when empty
+func (h *GoBuild) buildLabels(extra Labels) Labels {
+	if len(h.config.Labels) == 0 && len(extra) == 0 {
+		return nil
+	}
+	labels := make(Labels, len(h.config.Labels)+len(extra))
+	for k, v := range h.config.Labels {
+		labels[k] = v
+	}
+	for k, v := range extra {
+		labels[k] = v
+	}
+	return labels
+}
+
+// labelArgs stamps the build labels into Config.LabelsVar as a query string
+// eg: "pipeline=4711&pr=42", read back in the binary with url.ParseQuery
+func (h *GoBuild) labelArgs(comp *Build) []string {
+	if h.config.LabelsVar == "" || len(comp.labels) == 0 {
+		return nil
+	}
+	values := url.Values{}
+	for k, v := range comp.labels {
+		values.Set(k, v)
+	}
+	return []string{"-X", h.config.LabelsVar + "=" + values.Encode()}
+}
+
+// writeManifest writes the Config.Manifest sidecar of the promoted artifact
+func (h *GoBuild) writeManifest(comp *Build) error {
+	if !h.config.Manifest {
+		return nil
+	}
+
+	finalPath := h.FinalOutputPath()
+	goos, goarch := h.target()
+	m := Manifest{
+		BuildID:  comp.ID,
+		Label:    comp.label,
+		Labels:   comp.labels,
+		Artifact: h.outFileName,
+		Hash:     comp.hash,
+		Size:     h.artifactSizeOf(finalPath),
+		GOOS:     goos,
+		GOARCH:   goarch,
+		Version:  h.config.Version,
+		Commit:   comp.commit,
+		Salt:     comp.salt,
+		Restored: comp.restored,
+		Time:     h.now(),
+	}
+	if m.Version == "" {
+		m.Version = comp.version
+	}
+	if comp.namedPath != "" {
+		m.Named = filepath.Base(comp.namedPath)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	out, err := h.createArtifact(finalPath + manifestSuffix)
+	if err != nil {
+		return err
+	}
+	if _, err := out.Write(append(data, '\n')); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
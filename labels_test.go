@@ -0,0 +1,48 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildLabels(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo \"$@\" >> "+log+"; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Labels:                    Labels{"pipeline": "4711", "branch": "main"},
+		LabelsVar:                 "main.buildLabels",
+		Manifest:                  true,
+	})
+
+	b := gb.StartWith(BuildOptions{Labels: Labels{"pr": "42", "branch": "feature/x y"}})
+	if err := b.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(log)
+	if want := "-X main.buildLabels=branch=feature%2Fx+y&pipeline=4711&pr=42"; !strings.Contains(string(data), want) {
+		t.Errorf("Expected %q in the compiler arguments, got %q", want, data)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.BuildID != b.ID || m.Artifact != "app" || m.Hash != b.Result().Hash || m.Size == 0 {
+		t.Errorf("Unexpected manifest %+v", m)
+	}
+	if len(m.Labels) != 3 || m.Labels["branch"] != "feature/x y" || m.Labels["pipeline"] != "4711" {
+		t.Errorf("Expected the merged labels, got %v", m.Labels)
+	}
+}
@@ -0,0 +1,67 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Layout selects how artifacts are arranged inside OutFolderRelativePath
+type Layout int
+
+const (
+	// LayoutFlat writes the artifact directly in the output folder (default)
+	LayoutFlat Layout = iota
+	// LayoutByTarget writes into a GOOS_GOARCH subfolder, eg: build/linux_arm64/app
+	LayoutByTarget
+	// LayoutByVersion writes into a Config.Version subfolder, eg: build/v1.2.0/app
+	LayoutByVersion
+)
+
+// outFolder returns the output folder including the layout subfolder
+// Relative to the compiler working directory like OutFolderRelativePath
+func (h *GoBuild) outFolder() string {
+	dir := filepath.FromSlash(h.config.OutFolderRelativePath)
+	switch h.config.Layout {
+	case LayoutByTarget:
+		goos, goarch := h.target()
+		return filepath.Join(dir, goos+"_"+goarch)
+	case LayoutByVersion:
+		return filepath.Join(dir, h.config.Version)
+	}
+	return dir
+}
+
+// target returns the GOOS and GOARCH the compiler builds for
+// Config.Env and EnvFiles take precedence over the process env, then the host platform
+func (h *GoBuild) target() (goos, goarch string) {
+	env, _ := h.userEnv() // a broken env file fails the build later with a proper error
+	return targetOf(func(key string) string {
+		value := h.getenv(key)
+		for _, entry := range env {
+			if v, ok := strings.CutPrefix(entry, key+"="); ok {
+				value = v
+			}
+		}
+		return value
+	})
+}
+
+// targetOf returns GOOS and GOARCH read with lookup, defaulting to the host platform
+func targetOf(lookup func(string) string) (goos, goarch string) {
+	goos, goarch = lookup("GOOS"), lookup("GOARCH")
+	if goos == "" {
+		goos = runtime.GOOS
+	}
+	if goarch == "" {
+		goarch = runtime.GOARCH
+	}
+	return goos, goarch
+}
+
+// ensureOutFolder creates the output folder and its layout subfolder (the
+// StagingDir when it is read-only), the compiler and the rename expect it to exist
+func (h *GoBuild) ensureOutFolder() error {
+	return os.MkdirAll(fixLongPath(h.resolve(h.tempFolder())), 0755)
+}
@@ -0,0 +1,60 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputLayout(t *testing.T) {
+	tests := []struct {
+		name    string
+		layout  Layout
+		env     []string
+		version string
+		want    string
+	}{
+		{"flat", LayoutFlat, nil, "", "app"},
+		{"by target", LayoutByTarget, []string{"GOOS=js", "GOARCH=wasm"}, "", filepath.Join("js_wasm", "app")},
+		{"by version", LayoutByVersion, nil, "v1.2.0", filepath.Join("v1.2.0", "app")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			gb := New(&Config{
+				Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+				MainInputFileRelativePath: "main.go",
+				OutName:                   "app",
+				OutFolderRelativePath:     filepath.Join(dir, "build"),
+				Env:                       tt.env,
+				Layout:                    tt.layout,
+				Version:                   tt.version,
+			})
+
+			if err := gb.CompileProgram(); err != nil {
+				t.Fatalf("CompileProgram failed: %v", err)
+			}
+
+			want := filepath.Join(dir, "build", tt.want)
+			if got := gb.FinalOutputPath(); got != want {
+				t.Errorf("Expected final path %q, got %q", want, got)
+			}
+			if _, err := os.Stat(want); err != nil {
+				t.Errorf("Expected artifact at %q: %v", want, err)
+			}
+		})
+	}
+}
+
+func TestLayoutByVersionRequiresVersion(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Layout:                    LayoutByVersion,
+	})
+	if CodeOf(gb.CompileProgram()) != ErrCodeValidation {
+		t.Error("Expected a validation error without Version")
+	}
+}
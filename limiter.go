@@ -0,0 +1,83 @@
+package gobuild
+
+import (
+	"context"
+)
+
+// Limiter caps how many compiler processes run at once across the GoBuild
+// instances sharing it, eg: one per binary of a project where a "save all"
+// would otherwise launch a go build for each of them at the same time
+//
+//	limit := gobuild.NewLimiter(2)
+//	server := gobuild.New(&gobuild.Config{Limiter: limit, ...})
+//	wasm := gobuild.New(&gobuild.Config{Limiter: limit, ...})
+type Limiter struct {
+	slots chan struct{}
+}
+
+// NewLimiter returns a Limiter allowing n simultaneous compiles, n < 1 is treated as 1
+func NewLimiter(n int) *Limiter {
+	if n < 1 {
+		n = 1
+	}
+	return &Limiter{slots: make(chan struct{}, n)}
+}
+
+// InUse returns how many compiles currently hold a slot
+func (l *Limiter) InUse() int {
+	return len(l.slots)
+}
+
+// acquire blocks until a slot is free or ctx is done, a nil Limiter never blocks
+func (l *Limiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// release frees the slot taken by acquire
+func (l *Limiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// waitSlot takes a Limiter slot for comp, the Config.Timeout clock is paused while waiting
+func (h *GoBuild) waitSlot(comp *Build) error {
+	l := h.config.Limiter
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil // free slot, no wait
+	default:
+	}
+
+	h.mu.Lock()
+	if comp.timer.Stop() {
+		comp.paused = true
+		comp.remaining = comp.deadline.Sub(h.now())
+	}
+	h.mu.Unlock()
+
+	if err := l.acquire(comp.ctx); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	if comp.paused {
+		comp.paused = false
+		comp.deadline = h.now().Add(comp.remaining)
+		comp.timer.Reset(comp.remaining)
+	}
+	h.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,110 @@
+package gobuild
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLimiterSerializesCompiles(t *testing.T) {
+	limit := NewLimiter(1)
+
+	var builds []*Build
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		gb := newSlowBuild(t, time.Second) // each compile takes 0.6s, waiting must not count
+		gb.config.Limiter = limit
+		builds = append(builds, gb.Start())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if n := limit.InUse(); n != 1 {
+		t.Errorf("Expected 1 compile holding a slot, got %d", n)
+	}
+
+	for i, b := range builds {
+		if err := b.Wait(); err != nil {
+			t.Errorf("Build %d failed: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 1800*time.Millisecond {
+		t.Errorf("Expected compiles to run one at a time, all done in %v", elapsed)
+	}
+	if n := limit.InUse(); n != 0 {
+		t.Errorf("Expected every slot released, got %d in use", n)
+	}
+}
+
+func TestLimiterCancelWhileWaiting(t *testing.T) {
+	limit := NewLimiter(1)
+
+	first := newSlowBuild(t, 5*time.Second)
+	first.config.Limiter = limit
+	running := first.Start()
+	time.Sleep(100 * time.Millisecond)
+
+	second := newSlowBuild(t, 5*time.Second)
+	second.config.Limiter = limit
+	waiting := second.Start()
+	time.Sleep(50 * time.Millisecond)
+	waiting.Cancel()
+
+	if err := waiting.Wait(); !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled while waiting for a slot, got: %v", err)
+	}
+	if err := running.Wait(); err != nil {
+		t.Errorf("Running build should not be affected, got: %v", err)
+	}
+}
+
+func TestLimiterExtendTimeoutWhileWaiting(t *testing.T) {
+	dir := t.TempDir()
+	clock := NewFakeClock(time.Now())
+	limit := NewLimiter(1)
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "exec sleep 5"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Timeout:                   time.Minute,
+		Clock:                     clock,
+		Limiter:                   limit,
+	})
+
+	limit.slots <- struct{}{} // another compile holds the only slot
+	b := gb.Start()
+	for deadline := time.Now().Add(3 * time.Second); clock.Pending() != 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the timeout paused while waiting for a slot")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := gb.ExtendTimeout(30 * time.Second); err != nil {
+		t.Fatalf("Expected ExtendTimeout to work while waiting for a slot, got %v", err)
+	}
+	limit.release()
+	for deadline := time.Now().Add(3 * time.Second); clock.Pending() != 1; {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the timeout re-armed once the slot was taken")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	clock.Advance(time.Minute)
+	select {
+	case <-b.Done():
+		t.Fatal("Expected the extension to survive the wait for a slot")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	clock.Advance(30 * time.Second)
+	select {
+	case <-b.Done():
+	case <-time.After(3 * time.Second):
+		t.Fatal("Expected the build to time out after the extended minute and a half")
+	}
+	if code := CodeOf(b.Wait()); code != ErrCodeTimeout {
+		t.Errorf("Expected %s, got %s", ErrCodeTimeout, code)
+	}
+}
@@ -0,0 +1,142 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LogLevel orders log entries by importance, sinks receive their level and above
+type LogLevel int
+
+const (
+	LogDebug LogLevel = iota // command lines
+	LogInfo                  // build started/finished
+	LogWarn                  // recoverable problems, eg: cache or audit failures
+	LogError                 // failures that affect the build, eg: the final rename
+)
+
+var logLevelNames = [...]string{"DEBUG", "INFO", "WARN", "ERROR"}
+
+func (l LogLevel) String() string {
+	if l < 0 || int(l) >= len(logLevelNames) {
+		return fmt.Sprintf("LogLevel(%d)", int(l))
+	}
+	return logLevelNames[l]
+}
+
+// slogLevel maps l to the matching slog level
+func (l LogLevel) slogLevel() slog.Level {
+	switch {
+	case l <= LogDebug:
+		return slog.LevelDebug
+	case l == LogInfo:
+		return slog.LevelInfo
+	case l == LogWarn:
+		return slog.LevelWarn
+	}
+	return slog.LevelError
+}
+
+// LogEntry is one log message
+type LogEntry struct {
+	Time    time.Time
+	Level   LogLevel
+	BuildID uint64 // 0 when not tied to a build
+	Message string
+}
+
+// String formats the entry as written to LogSink.Writer, without the trailing newline
+func (e LogEntry) String() string {
+	return fmt.Sprintf("%s %-5s %s", e.Time.Format(time.RFC3339), e.Level, e.Message)
+}
+
+// LogSink receives the entries at or above Level through any of its outputs
+// eg: {Level: LogInfo, Writer: os.Stderr}, {Level: LogDebug, Writer: logFile}, {Level: LogError, Events: ch}
+type LogSink struct {
+	Level  LogLevel
+	Writer io.Writer       // one line per entry, writes are serialized
+	Events chan<- LogEntry // never blocks: entries are dropped while the channel is full
+	Func   func(LogEntry)  // called synchronously
+}
+
+// logf sends a message to Config.Logger (LogWarn and above, as before levels existed),
+// to Config.Slog and to every Config.LogSinks entry accepting level
+func (h *GoBuild) logf(level LogLevel, buildID uint64, message ...any) {
+	if h.config.Logger == nil && h.config.Slog == nil && len(h.config.LogSinks) == 0 {
+		return
+	}
+	entry := LogEntry{
+		Time:    h.now(),
+		Level:   level,
+		BuildID: buildID,
+		Message: strings.TrimSuffix(fmt.Sprintln(message...), "\n"),
+	}
+	if h.config.Logger != nil && level >= LogWarn {
+		if h.config.JSONEvents {
+			h.emitEvent(BuildEvent{Event: EventLog, BuildID: buildID, Level: level.String(), Message: entry.Message})
+		} else {
+			h.config.Logger(message...)
+		}
+	}
+
+	if h.config.Slog != nil {
+		h.logSlog(entry)
+	}
+
+	for _, sink := range h.config.LogSinks {
+		if level < sink.Level {
+			continue
+		}
+		if sink.Writer != nil {
+			h.logMu.Lock()
+			fmt.Fprintln(sink.Writer, entry)
+			h.logMu.Unlock()
+		}
+		if sink.Events != nil {
+			select {
+			case sink.Events <- entry:
+			default:
+			}
+		}
+		if sink.Func != nil {
+			sink.Func(entry)
+		}
+	}
+}
+
+// logSlog hands entry to Config.Slog, keeping its time so a Config.Clock applies
+func (h *GoBuild) logSlog(entry LogEntry) {
+	handler := h.config.Slog.Handler()
+	level := entry.Level.slogLevel()
+	ctx := context.Background()
+	if !handler.Enabled(ctx, level) {
+		return
+	}
+	r := slog.NewRecord(entry.Time, level, entry.Message, 0)
+	if entry.BuildID != 0 {
+		r.AddAttrs(slog.Uint64("build_id", entry.BuildID))
+	}
+	handler.Handle(ctx, r)
+}
+
+// logFinished reports the outcome of a build: LogInfo on success or cancellation, LogError on failure
+func (h *GoBuild) logFinished(comp *Build, err error) {
+	elapsed := h.now().Sub(comp.startTime).Round(time.Millisecond)
+	if err != nil {
+		level := LogError
+		if CodeOf(err) == ErrCodeCancelled {
+			level = LogInfo
+		}
+		h.logf(level, comp.ID, fmt.Sprintf("Build %d failed (%s) in %v", comp.ID, CodeOf(err), elapsed))
+		return
+	}
+	if comp.hash == "" {
+		h.logf(LogInfo, comp.ID, fmt.Sprintf("Build %d succeeded in %v", comp.ID, elapsed))
+		return
+	}
+	h.logf(LogInfo, comp.ID, fmt.Sprintf("Build %d succeeded in %v (%s, %s)", comp.ID, elapsed, formatBytes(comp.size), formatSizeDelta(comp.sizeDelta)))
+}
@@ -0,0 +1,105 @@
+package gobuild
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLogSinksLevels(t *testing.T) {
+	var terminal, file bytes.Buffer
+	events := make(chan LogEntry, 10)
+	var legacy []string
+	gb := New(&Config{
+		Logger: func(message ...any) { legacy = append(legacy, strings.TrimSpace(fmt.Sprintln(message...))) },
+		LogSinks: []LogSink{
+			{Level: LogInfo, Writer: &terminal},
+			{Level: LogDebug, Writer: &file},
+			{Level: LogError, Events: events},
+		},
+	})
+
+	gb.logf(LogDebug, 1, "Running:", "go build")
+	gb.logf(LogInfo, 1, "Build 1 succeeded")
+	gb.logf(LogWarn, 0, "Cache put failed:", errors.New("disk"))
+	gb.logf(LogError, 0, "Rename failed")
+
+	if got := strings.Count(file.String(), "\n"); got != 4 {
+		t.Errorf("Expected 4 lines in the debug sink, got %q", file.String())
+	}
+	if strings.Contains(terminal.String(), "Running:") || !strings.Contains(terminal.String(), "INFO  Build 1 succeeded") {
+		t.Errorf("Unexpected info sink output %q", terminal.String())
+	}
+	if len(events) != 1 {
+		t.Fatalf("Expected 1 error event, got %d", len(events))
+	}
+	if e := <-events; e.Level != LogError || e.Message != "Rename failed" {
+		t.Errorf("Unexpected event %+v", e)
+	}
+	if len(legacy) != 2 || legacy[0] != "Cache put failed: disk" {
+		t.Errorf("Expected Logger to receive warnings and errors only, got %q", legacy)
+	}
+}
+
+func TestLogSinkEventsNeverBlock(t *testing.T) {
+	events := make(chan LogEntry, 1)
+	gb := New(&Config{LogSinks: []LogSink{{Events: events}}})
+
+	gb.logf(LogInfo, 0, "first")
+	gb.logf(LogInfo, 0, "dropped")
+
+	if e := <-events; e.Message != "first" {
+		t.Errorf("Expected first entry, got %q", e.Message)
+	}
+}
+
+func TestLogSinkBuildFinished(t *testing.T) {
+	dir := t.TempDir()
+	var entries []LogEntry
+	err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		LogSinks:                  []LogSink{{Level: LogDebug, Func: func(e LogEntry) { entries = append(entries, e) }}},
+	}).CompileProgram()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Level != LogDebug || entries[1].Level != LogInfo || entries[1].BuildID != 1 {
+		t.Fatalf("Expected a debug command line and an info result, got %+v", entries)
+	}
+	if !strings.HasPrefix(entries[1].Message, "Build 1 succeeded") {
+		t.Errorf("Unexpected message %q", entries[1].Message)
+	}
+}
+
+func TestSlogLevels(t *testing.T) {
+	dir := t.TempDir()
+	var out bytes.Buffer
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'syntax error' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Slog:                      slog.New(slog.NewTextHandler(&out, &slog.HandlerOptions{Level: slog.LevelDebug})),
+	})
+	if err := gb.CompileProgram(); err == nil {
+		t.Fatal("Expected the build to fail")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a command line and a failure, got %q", out.String())
+	}
+	if !strings.Contains(lines[0], "level=DEBUG") || !strings.Contains(lines[0], "Running:") || !strings.Contains(lines[0], "build_id=1") {
+		t.Errorf("Unexpected command line record %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "level=ERROR") || !strings.Contains(lines[1], "Build 1 failed") {
+		t.Errorf("Unexpected failure record %q", lines[1])
+	}
+}
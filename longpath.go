@@ -0,0 +1,27 @@
+package gobuild
+
+import "strings"
+
+// windowsMaxPath is the length from which plain win32 paths fail (MAX_PATH minus
+// room for an 8.3 file name, as required when creating directories)
+const windowsMaxPath = 248
+
+// winLongPath converts an absolute windows path to its extended-length form
+// eg: C:\very\deep\app.exe => \\?\C:\very\deep\app.exe
+// UNC shares: \\server\share\app.exe => \\?\UNC\server\share\app.exe
+// Short, relative or already prefixed paths are returned unchanged
+func winLongPath(p string) string {
+	if len(p) < windowsMaxPath || strings.HasPrefix(p, `\\?\`) || strings.HasPrefix(p, `\\.\`) {
+		return p
+	}
+
+	p = strings.ReplaceAll(p, "/", `\`)
+
+	if strings.HasPrefix(p, `\\`) {
+		return `\\?\UNC\` + p[2:]
+	}
+	if len(p) >= 3 && p[1] == ':' && p[2] == '\\' {
+		return `\\?\` + p
+	}
+	return p
+}
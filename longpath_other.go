@@ -0,0 +1,8 @@
+//go:build !windows
+
+package gobuild
+
+// fixLongPath is only needed on windows
+func fixLongPath(p string) string {
+	return p
+}
@@ -0,0 +1,31 @@
+package gobuild
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWinLongPath(t *testing.T) {
+	deep := strings.Repeat(`node_modules\pkg\`, 20)
+
+	tests := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{"short path unchanged", `C:\app\main.exe`, `C:\app\main.exe`},
+		{"long drive path", `C:\` + deep + `app.exe`, `\\?\C:\` + deep + `app.exe`},
+		{"long UNC share", `\\server\share\` + deep + `app.exe`, `\\?\UNC\server\share\` + deep + `app.exe`},
+		{"already prefixed", `\\?\C:\` + deep + `app.exe`, `\\?\C:\` + deep + `app.exe`},
+		{"forward slashes", `C:/` + strings.ReplaceAll(deep, `\`, "/") + `app.exe`, `\\?\C:\` + deep + `app.exe`},
+		{"relative path unchanged", deep + `app.exe`, deep + `app.exe`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := winLongPath(tt.in); got != tt.expected {
+				t.Errorf("Expected %s, got %s", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+//go:build windows
+
+package gobuild
+
+import "path/filepath"
+
+// fixLongPath makes p usable by file operations beyond MAX_PATH, including UNC shares
+func fixLongPath(p string) string {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return p
+	}
+	if long := winLongPath(abs); long != abs {
+		return long
+	}
+	return p
+}
@@ -0,0 +1,176 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Target build states reported by Matrix.Status
+const (
+	TargetIdle      = "idle"      // not requested yet
+	TargetRunning   = "running"   // compiling or waiting for a Limiter slot
+	TargetSucceeded = "succeeded" // artifact promoted
+	TargetFailed    = "failed"
+	TargetCancelled = "cancelled" // CancelTarget, Cancel or the Compile context
+)
+
+// TargetStatus is a snapshot of one matrix target
+type TargetStatus struct {
+	Target string       // eg: "linux/arm64"
+	State  string       // eg: TargetRunning
+	Result *BuildResult // nil until the target finishes
+}
+
+// Matrix builds the same program for several GOOS/GOARCH targets, each target
+// can be cancelled on its own while the others continue
+type Matrix struct {
+	targets  []string
+	builders map[string]*GoBuild
+
+	mu     sync.Mutex
+	builds map[string]*Build // latest build of each target
+}
+
+// NewMatrix creates one builder per target ("goos/goarch") from a copy of base
+// GOOS/GOARCH are appended to Env, a flat Layout becomes LayoutByTarget so the
+// artifacts don't overwrite each other, windows targets get ".exe" when Extension is empty
+// A Coverage Dir gets one <goos>_<goarch> folder per target
+// maxParallel caps simultaneous compiles (<= 0 means no limit) unless base.Limiter is set
+func NewMatrix(base Config, targets []string, maxParallel int) (*Matrix, error) {
+	if base.Limiter == nil && maxParallel > 0 {
+		base.Limiter = NewLimiter(maxParallel)
+	}
+	if base.Layout == LayoutFlat {
+		base.Layout = LayoutByTarget
+	}
+
+	m := &Matrix{builders: map[string]*GoBuild{}, builds: map[string]*Build{}}
+	for _, target := range targets {
+		goos, goarch, ok := strings.Cut(target, "/")
+		if !ok || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("NewMatrix: target %q must be goos/goarch, eg: linux/arm64", target)
+		}
+		if _, dup := m.builders[target]; dup {
+			return nil, fmt.Errorf("NewMatrix: duplicate target %q", target)
+		}
+
+		c := base
+		c.Env = append(append([]string{}, base.Env...), "GOOS="+goos, "GOARCH="+goarch)
+		c.Coverage = coverageTarget(base.Coverage, goos, goarch)
+		if goos == "windows" && c.Extension == "" {
+			c.Extension = ".exe"
+		}
+		m.targets = append(m.targets, target)
+		m.builders[target] = New(&c)
+	}
+	return m, nil
+}
+
+// Builder returns the GoBuild of target, nil if the matrix doesn't contain it
+func (m *Matrix) Builder(target string) *GoBuild {
+	return m.builders[target]
+}
+
+// Validate checks every target config and returns an *OutputConflictError
+// when a custom Layout makes several targets share a final path
+func (m *Matrix) Validate() error {
+	var errs []error
+	for _, target := range m.targets {
+		if err := m.builders[target].Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(append(errs, checkOutputs(m.targets, m.list()))...)
+}
+
+// list returns the builders in target order
+func (m *Matrix) list() []*GoBuild {
+	builders := make([]*GoBuild, len(m.targets))
+	for i, target := range m.targets {
+		builders[i] = m.builders[target]
+	}
+	return builders
+}
+
+// Compile builds every target and waits for all of them, results are in target order
+// Cancelling ctx cancels the targets still running. The returned error joins every failure.
+// Nothing is started when targets share a final path, see Validate
+func (m *Matrix) Compile(ctx context.Context) ([]BuildResult, error) {
+	if err := checkOutputs(m.targets, m.list()); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	for _, target := range m.targets {
+		m.builds[target] = m.builders[target].Start()
+	}
+	builds := make([]*Build, len(m.targets))
+	for i, target := range m.targets {
+		builds[i] = m.builds[target]
+	}
+	m.mu.Unlock()
+
+	stop := context.AfterFunc(ctx, m.Cancel)
+	defer stop()
+
+	results := make([]BuildResult, len(builds))
+	var errs []error
+	for i, b := range builds {
+		if err := b.Wait(); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.targets[i], err))
+		}
+		results[i] = *b.Result()
+	}
+	return results, errors.Join(errs...)
+}
+
+// CancelTarget stops a single target, the other targets keep building
+func (m *Matrix) CancelTarget(target string) error {
+	gb := m.builders[target]
+	if gb == nil {
+		return fmt.Errorf("CancelTarget: unknown target %q", target)
+	}
+	return gb.Cancel()
+}
+
+// Cancel stops every target
+func (m *Matrix) Cancel() {
+	for _, target := range m.targets {
+		m.builders[target].Cancel()
+	}
+}
+
+// Status returns the state of every target in matrix order
+func (m *Matrix) Status() []TargetStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	status := make([]TargetStatus, 0, len(m.targets))
+	for _, target := range m.targets {
+		status = append(status, buildStatus(target, m.builds[target]))
+	}
+	return status
+}
+
+// buildStatus returns the state of the latest build of target, idle when b is nil
+func buildStatus(target string, b *Build) TargetStatus {
+	s := TargetStatus{Target: target, State: TargetIdle}
+	if b == nil {
+		return s
+	}
+	s.Result = b.Result()
+	switch {
+	case s.Result == nil:
+		s.State = TargetRunning
+	case s.Result.Success():
+		s.State = TargetSucceeded
+	case s.Result.Code == ErrCodeCancelled:
+		s.State = TargetCancelled
+	default:
+		s.State = TargetFailed
+	}
+	return s
+}
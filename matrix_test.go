@@ -0,0 +1,79 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMatrixCancelTarget(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewMatrix(Config{
+		Command:                   writeFakeCompiler(t, dir, fakeSlowCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}, []string{"linux/amd64", "linux/arm64", "windows/amd64"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s := m.Status(); s[0].State != TargetIdle {
+		t.Errorf("Expected idle before Compile, got %s", s[0].State)
+	}
+
+	type compiled struct {
+		results []BuildResult
+		err     error
+	}
+	done := make(chan compiled, 1)
+	go func() {
+		r, err := m.Compile(context.Background())
+		done <- compiled{r, err}
+	}()
+
+	time.Sleep(150 * time.Millisecond)
+	if s := m.Status(); s[1].State != TargetRunning {
+		t.Errorf("Expected linux/arm64 running, got %s", s[1].State)
+	}
+	if err := m.CancelTarget("linux/arm64"); err != nil {
+		t.Fatal(err)
+	}
+
+	c := <-done
+	if !errors.Is(c.err, ErrCancelled) {
+		t.Errorf("Expected the cancelled target in the joined error, got: %v", c.err)
+	}
+	if !c.results[0].Success() || !c.results[2].Success() {
+		t.Errorf("Other targets should finish, got %v / %v", c.results[0].Err, c.results[2].Err)
+	}
+
+	states := map[string]string{}
+	for _, s := range m.Status() {
+		states[s.Target] = s.State
+	}
+	if states["linux/amd64"] != TargetSucceeded || states["linux/arm64"] != TargetCancelled || states["windows/amd64"] != TargetSucceeded {
+		t.Errorf("Unexpected states: %v", states)
+	}
+
+	for _, p := range []string{"linux_amd64/app", "windows_amd64/app.exe"} {
+		if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(p))); err != nil {
+			t.Errorf("Expected artifact %s: %v", p, err)
+		}
+	}
+	if err := m.CancelTarget("plan9/386"); err == nil {
+		t.Error("Expected error for an unknown target")
+	}
+}
+
+func TestNewMatrixInvalidTarget(t *testing.T) {
+	if _, err := NewMatrix(Config{}, []string{"linux"}, 0); err == nil {
+		t.Error("Expected error for a target without goarch")
+	}
+	if _, err := NewMatrix(Config{}, []string{"linux/amd64", "linux/amd64"}, 0); err == nil {
+		t.Error("Expected error for a duplicate target")
+	}
+}
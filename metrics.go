@@ -0,0 +1,156 @@
+package gobuild
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultDurationBuckets are the Metrics duration histogram upper bounds in seconds
+var DefaultDurationBuckets = []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300}
+
+// Metrics counts finished builds for Prometheus, one value per output (OutName+Extension)
+// so several GoBuild instances can share it through Config.Metrics
+// It is an http.Handler serving the text exposition format, eg: mux.Handle("/metrics", metrics)
+//
+//	gobuild_builds_total{output="app",result="success"}      success, failure or cancelled
+//	gobuild_build_duration_seconds{output="app"}              histogram of the builds that ran
+//	gobuild_binary_size_bytes{output="app"}                   size of the last promoted artifact
+//
+// The zero value is ready to use
+type Metrics struct {
+	Namespace string    // metric name prefix, default "gobuild"
+	Buckets   []float64 // duration histogram upper bounds in seconds, default DefaultDurationBuckets. Outputs keep the bounds of their first build
+
+	mu      sync.Mutex
+	outputs map[string]*outputMetrics
+}
+
+// outputMetrics are the series of one output
+type outputMetrics struct {
+	results map[string]uint64 // by result label
+	bounds  []float64         // copy of Metrics.Buckets when the output was first observed
+	buckets []uint64          // cumulative counts, one per bound
+	count   uint64
+	sum     float64 // seconds
+	size    int64
+	sized   bool // size set by a successful build
+}
+
+// Observe records r as a build of output, Config.Metrics calls it for every build
+// Dropped builds that never started count as cancelled without a duration
+func (m *Metrics) Observe(output string, r *BuildResult) {
+	result := "success"
+	switch {
+	case r.Code == ErrCodeCancelled:
+		result = "cancelled"
+	case r.Err != nil:
+		result = "failure"
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.outputs == nil {
+		m.outputs = make(map[string]*outputMetrics)
+	}
+	o := m.outputs[output]
+	if o == nil {
+		bounds := append([]float64(nil), m.buckets()...)
+		o = &outputMetrics{results: make(map[string]uint64), bounds: bounds, buckets: make([]uint64, len(bounds))}
+		m.outputs[output] = o
+	}
+	o.results[result]++
+
+	if !r.StartTime.IsZero() {
+		seconds := r.Duration.Seconds()
+		for i, bound := range o.bounds {
+			if seconds <= bound {
+				o.buckets[i]++
+			}
+		}
+		o.count++
+		o.sum += seconds
+	}
+	if r.Err == nil {
+		o.size, o.sized = r.Size, true
+	}
+}
+
+// WriteTo writes every series in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	ns := m.Namespace
+	if ns == "" {
+		ns = "gobuild"
+	}
+
+	m.mu.Lock()
+	outputs := make([]string, 0, len(m.outputs))
+	for name := range m.outputs {
+		outputs = append(outputs, name)
+	}
+	sort.Strings(outputs)
+
+	fmt.Fprintf(&b, "# HELP %s_builds_total Finished builds by result.\n# TYPE %s_builds_total counter\n", ns, ns)
+	for _, name := range outputs {
+		o := m.outputs[name]
+		for _, result := range []string{"success", "failure", "cancelled"} {
+			fmt.Fprintf(&b, "%s_builds_total{output=%s,result=%q} %d\n", ns, metricLabel(name), result, o.results[result])
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP %s_build_duration_seconds Duration of the builds that ran.\n# TYPE %s_build_duration_seconds histogram\n", ns, ns)
+	for _, name := range outputs {
+		o := m.outputs[name]
+		for i, bound := range o.bounds {
+			fmt.Fprintf(&b, "%s_build_duration_seconds_bucket{output=%s,le=%q} %d\n", ns, metricLabel(name), strconv.FormatFloat(bound, 'g', -1, 64), o.buckets[i])
+		}
+		fmt.Fprintf(&b, "%s_build_duration_seconds_bucket{output=%s,le=\"+Inf\"} %d\n", ns, metricLabel(name), o.count)
+		fmt.Fprintf(&b, "%s_build_duration_seconds_sum{output=%s} %s\n", ns, metricLabel(name), strconv.FormatFloat(o.sum, 'g', -1, 64))
+		fmt.Fprintf(&b, "%s_build_duration_seconds_count{output=%s} %d\n", ns, metricLabel(name), o.count)
+	}
+
+	fmt.Fprintf(&b, "# HELP %s_binary_size_bytes Size of the last promoted artifact.\n# TYPE %s_binary_size_bytes gauge\n", ns, ns)
+	for _, name := range outputs {
+		if o := m.outputs[name]; o.sized {
+			fmt.Fprintf(&b, "%s_binary_size_bytes{output=%s} %d\n", ns, metricLabel(name), o.size)
+		}
+	}
+	m.mu.Unlock()
+
+	return b.WriteTo(w)
+}
+
+// ServeHTTP answers a Prometheus scrape
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// buckets returns the configured bounds or DefaultDurationBuckets
+func (m *Metrics) buckets() []float64 {
+	if len(m.Buckets) > 0 {
+		return m.Buckets
+	}
+	return DefaultDurationBuckets
+}
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricLabel quotes a label value as the exposition format expects
+func metricLabel(v string) string {
+	return `"` + metricLabelEscaper.Replace(v) + `"`
+}
+
+// observeMetrics records the result of comp in Config.Metrics, discarded prewarm builds excluded
+func (h *GoBuild) observeMetrics(comp *Build) {
+	if h.config.Metrics == nil || comp.discard {
+		return
+	}
+	h.config.Metrics.Observe(h.outFileName, comp.result)
+}
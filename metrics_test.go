@@ -0,0 +1,81 @@
+package gobuild
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	dir := t.TempDir()
+	metrics := &Metrics{Buckets: []float64{60}}
+	ok := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Metrics:                   metrics,
+	})
+	failing := New(&Config{
+		Command:                   writeFakeCompiler(t, t.TempDir(), "echo 'syntax error' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "worker",
+		OutFolderRelativePath:     dir,
+		Metrics:                   metrics,
+	})
+
+	for i := 0; i < 2; i++ {
+		if err := ok.CompileProgram(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := failing.CompileProgram(); err == nil {
+		t.Fatal("Expected the worker build to fail")
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, want := range []string{
+		`gobuild_builds_total{output="app",result="success"} 2`,
+		`gobuild_builds_total{output="worker",result="failure"} 1`,
+		`gobuild_build_duration_seconds_bucket{output="app",le="60"} 2`,
+		`gobuild_build_duration_seconds_count{output="worker"} 1`,
+		`gobuild_binary_size_bytes{output="app"} 8`, // fakeEchoCompiler writes "artifact"
+		"# TYPE gobuild_build_duration_seconds histogram",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `gobuild_binary_size_bytes{output="worker"}`) {
+		t.Error("Expected no size for an output that never built")
+	}
+}
+
+func TestMetricsBucketsChangedAfterObserve(t *testing.T) {
+	metrics := &Metrics{Buckets: []float64{60}}
+	r := &BuildResult{StartTime: time.Now(), Duration: time.Second}
+	metrics.Observe("app", r)
+
+	// outputs keep the bounds they were created with, new ones take the current
+	metrics.Buckets = []float64{1, 10, 60}
+	metrics.Observe("app", r)
+	metrics.Observe("worker", r)
+
+	var b strings.Builder
+	metrics.WriteTo(&b)
+	body := b.String()
+	for _, want := range []string{
+		`gobuild_build_duration_seconds_bucket{output="app",le="60"} 2`,
+		`gobuild_build_duration_seconds_bucket{output="worker",le="1"} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected %q in:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, `{output="app",le="1"}`) {
+		t.Errorf("Expected app to keep its original bounds:\n%s", body)
+	}
+}
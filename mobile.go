@@ -0,0 +1,71 @@
+package gobuild
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Mobile drives gomobile instead of go build, for Go cores shared with Android/iOS apps
+// Use it with Command "gomobile" and MainInputFileRelativePath set to the package, eg: "./mobile"
+// The output goes through the usual temp file and promotion, bundles included
+//
+//	bind:  .aar (android) or .xcframework (ios, iossimulator, macos, maccatalyst)
+//	build: .apk (android) or .app (ios)
+type Mobile struct {
+	Bind       bool   // gomobile bind (library) instead of gomobile build (app)
+	Target     string // gomobile -target, eg: "android", "android/arm64,ios", "ios,iossimulator"
+	AndroidAPI int    // minimum Android API level (-androidapi), 0 uses the gomobile default
+	IOSVersion string // minimum iOS version (-iosversion), eg: "13.0"
+	BundleID   string // Apple bundle id (-bundleid), eg: "com.example.app"
+	JavaPkg    string // bind only: Java package prefix (-javapkg), eg: "com.example"
+	Prefix     string // bind only: Objective-C name prefix (-prefix), eg: "EX"
+}
+
+// subcommand returns the compiler subcommand: "build", or "bind" for gomobile bind
+func (h *GoBuild) subcommand() string {
+	if h.config.Mobile != nil && h.config.Mobile.Bind {
+		return "bind"
+	}
+	return "build"
+}
+
+// args returns the gomobile flags
+func (m *Mobile) args() []string {
+	args := []string{"-target=" + m.Target}
+	if m.AndroidAPI > 0 {
+		args = append(args, "-androidapi="+strconv.Itoa(m.AndroidAPI))
+	}
+	if m.IOSVersion != "" {
+		args = append(args, "-iosversion="+m.IOSVersion)
+	}
+	if m.BundleID != "" {
+		args = append(args, "-bundleid="+m.BundleID)
+	}
+	if m.Bind && m.JavaPkg != "" {
+		args = append(args, "-javapkg="+m.JavaPkg)
+	}
+	if m.Bind && m.Prefix != "" {
+		args = append(args, "-prefix="+m.Prefix)
+	}
+	return args
+}
+
+// extension returns the artifact extension gomobile produces for the mode and target
+func (m *Mobile) extension() string {
+	android := strings.HasPrefix(m.Target, "android")
+	switch {
+	case m.Bind && android:
+		return ".aar"
+	case m.Bind:
+		return ".xcframework"
+	case android:
+		return ".apk"
+	}
+	return ".app"
+}
+
+// bundle reports whether the artifact is a directory
+func (m *Mobile) bundle() bool {
+	ext := m.extension()
+	return ext == ".xcframework" || ext == ".app"
+}
@@ -0,0 +1,76 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMobileBindArguments(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "./mobile",
+		OutName:                   "Core",
+		Extension:                 ".xcframework",
+		OutFolderRelativePath:     "dist",
+		Mobile:                    &Mobile{Bind: true, Target: "ios,iossimulator", IOSVersion: "13.0", Prefix: "EX"},
+	})
+
+	expected := []string{"bind", "-target=ios,iossimulator", "-iosversion=13.0", "-prefix=EX", "-o", filepath.Join("dist", "Core_temp.xcframework"), filepath.FromSlash("./mobile")}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+}
+
+func TestMobileValidation(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "gomobile",
+		MainInputFileRelativePath: "./mobile",
+		OutName:                   "core",
+		Extension:                 ".jar",
+		Mobile:                    &Mobile{Bind: true, Target: "android"},
+	})
+	if err := gb.Validate(); err == nil {
+		t.Error("Expected an error for a .jar output of gomobile bind -target=android")
+	}
+}
+
+// fakeGomobileBundle writes an .xcframework-like directory to the -o path
+const fakeGomobileBundle = `prev=""
+for arg; do
+	if [ "$prev" = "-o" ]; then mkdir -p "$arg/ios-arm64" && printf lib > "$arg/ios-arm64/Core"; fi
+	prev="$arg"
+done`
+
+func TestMobileBundlePromotion(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeGomobileBundle),
+		MainInputFileRelativePath: "./mobile",
+		OutName:                   "Core",
+		Extension:                 ".xcframework",
+		OutFolderRelativePath:     dir,
+		Mobile:                    &Mobile{Bind: true, Target: "ios"},
+	})
+
+	// a previous bundle must be replaced, not merged into
+	stale := filepath.Join(dir, "Core.xcframework", "stale")
+	if err := os.MkdirAll(stale, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := gb.CompileProgram(); err != nil {
+			t.Fatalf("Build %d failed: %v", i, err)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "Core.xcframework", "ios-arm64", "Core")); err != nil || string(data) != "lib" {
+		t.Errorf("Expected promoted bundle, got %q (%v)", data, err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("Expected the previous bundle replaced, stale entry still there: %v", err)
+	}
+	if gb.ArtifactHash() == "" {
+		t.Error("Expected a bundle hash")
+	}
+}
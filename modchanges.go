@@ -0,0 +1,116 @@
+package gobuild
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleChange is one module requirement that differs from the previous build
+type ModuleChange struct {
+	Path string `json:"path"`           // eg: golang.org/x/net
+	From string `json:"from,omitempty"` // previous version, empty when added
+	To   string `json:"to,omitempty"`   // current version, empty when removed
+}
+
+// ModuleChanges lists the go.mod requirements added, removed or updated since the
+// previous build, eg: to attribute a sudden slow or bloated build to a dependency bump
+type ModuleChanges struct {
+	Added   []ModuleChange `json:"added,omitempty"`
+	Removed []ModuleChange `json:"removed,omitempty"`
+	Updated []ModuleChange `json:"updated,omitempty"`
+}
+
+// String summarizes the changes, eg: "+example.com/a v1.0.0, golang.org/x/net v0.20.0 => v0.21.0"
+func (d *ModuleChanges) String() string {
+	var parts []string
+	for _, c := range d.Added {
+		parts = append(parts, "+"+c.Path+" "+c.To)
+	}
+	for _, c := range d.Removed {
+		parts = append(parts, "-"+c.Path+" "+c.From)
+	}
+	for _, c := range d.Updated {
+		parts = append(parts, c.Path+" "+c.From+" => "+c.To)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diffDependencies compares the requirements of the module being built with the
+// previous build and records the changes on comp, nil on the first build or without go.mod
+func (h *GoBuild) diffDependencies(comp *Build) {
+	modFile := filepath.Join(moduleRoot(filepath.Dir(h.resolve(h.config.MainInputFileRelativePath))), "go.mod")
+	if h.config.ModFile != "" {
+		modFile = h.resolve(h.config.ModFile)
+	}
+	data, err := os.ReadFile(modFile)
+	if err != nil {
+		return
+	}
+	cur := parseRequires(data)
+
+	h.mu.Lock()
+	prev := h.requires
+	h.requires = cur
+	h.mu.Unlock()
+	if prev == nil {
+		return
+	}
+
+	changes := &ModuleChanges{}
+	for path, to := range cur {
+		from, ok := prev[path]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, ModuleChange{Path: path, To: to})
+		case from != to:
+			changes.Updated = append(changes.Updated, ModuleChange{Path: path, From: from, To: to})
+		}
+	}
+	for path, from := range prev {
+		if _, ok := cur[path]; !ok {
+			changes.Removed = append(changes.Removed, ModuleChange{Path: path, From: from})
+		}
+	}
+	if len(changes.Added)+len(changes.Removed)+len(changes.Updated) == 0 {
+		return
+	}
+	for _, list := range [][]ModuleChange{changes.Added, changes.Removed, changes.Updated} {
+		sort.Slice(list, func(i, j int) bool { return list[i].Path < list[j].Path })
+	}
+	comp.modules = changes
+	h.logf(LogInfo, comp.ID, "Dependencies changed:", changes)
+}
+
+// parseRequires returns the module versions of the require directives of a go.mod,
+// single line and block forms, comments (eg: // indirect) stripped
+func parseRequires(data []byte) map[string]string {
+	requires := map[string]string{}
+	inBlock := false
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		if len(fields) == 2 {
+			requires[strings.Trim(fields[0], `"`)] = fields[1]
+		}
+	}
+	return requires
+}
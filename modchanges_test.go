@@ -0,0 +1,69 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModuleChanges(t *testing.T) {
+	dir := t.TempDir()
+	goMod := filepath.Join(dir, "go.mod")
+	os.WriteFile(goMod, []byte(`module example.com/app
+
+go 1.22
+
+require github.com/old/lib v1.0.0
+
+require (
+	golang.org/x/net v0.20.0
+	golang.org/x/text v0.14.0 // indirect
+)
+`), 0644)
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: filepath.Join(dir, "main.go"),
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+
+	first, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Modules != nil {
+		t.Errorf("Expected no changes on the first build, got %v", first.Modules)
+	}
+
+	os.WriteFile(goMod, []byte(`module example.com/app
+
+go 1.22
+
+require (
+	github.com/new/lib v0.3.0
+	golang.org/x/net v0.21.0
+	golang.org/x/text v0.14.0 // indirect
+)
+`), 0644)
+	second, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &ModuleChanges{
+		Added:   []ModuleChange{{Path: "github.com/new/lib", To: "v0.3.0"}},
+		Removed: []ModuleChange{{Path: "github.com/old/lib", From: "v1.0.0"}},
+		Updated: []ModuleChange{{Path: "golang.org/x/net", From: "v0.20.0", To: "v0.21.0"}},
+	}
+	if !reflect.DeepEqual(second.Modules, want) {
+		t.Errorf("Expected %v, got %v", want, second.Modules)
+	}
+
+	third, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.Modules != nil {
+		t.Errorf("Expected no changes for an untouched go.mod, got %v", third.Modules)
+	}
+}
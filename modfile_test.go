@@ -0,0 +1,48 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestModFileArguments(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		Mod:                       "readonly",
+		ModFile:                   "go.legacy.mod",
+		CompilingArguments: func() []string {
+			return []string{"-tags", "legacy"}
+		},
+	})
+
+	expected := []string{"build", "-mod=readonly", "-modfile=go.legacy.mod", "-tags", "legacy", "-o", filepath.Join("build", "app_temp"), "main.go"}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+}
+
+func TestModFileValidation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.legacy.mod"), []byte("module example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := &Config{Command: "go", WorkDir: dir, MainInputFileRelativePath: "main.go", OutName: "app", ModFile: "go.legacy.mod", Mod: "mod"}
+	if err := New(valid).Validate(); err != nil {
+		t.Errorf("Expected valid config, got: %v", err)
+	}
+
+	for _, c := range []*Config{
+		{Command: "go", WorkDir: dir, MainInputFileRelativePath: "main.go", OutName: "app", ModFile: "go.missing.mod"},
+		{Command: "go", WorkDir: dir, MainInputFileRelativePath: "main.go", OutName: "app", ModFile: "go.legacy.txt"},
+		{Command: "go", WorkDir: dir, MainInputFileRelativePath: "main.go", OutName: "app", Mod: "offline"},
+	} {
+		if err := New(c).Validate(); err == nil {
+			t.Errorf("Expected validation error for Mod %q ModFile %q", c.Mod, c.ModFile)
+		}
+	}
+}
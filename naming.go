@@ -0,0 +1,111 @@
+package gobuild
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// BuildInfo describes a promoted build to Config.FinalNameFunc
+type BuildInfo struct {
+	ID        uint64
+	OutName   string    // Config.OutName, eg: "app"
+	Extension string    // Config.Extension, eg: ".exe"
+	Version   string    // Config.Version, or git describe with StampGitInfo
+	Commit    string    // commit hash with StampGitInfo
+	Hash      string    // hex SHA-256 of the artifact
+	Time      time.Time // when the artifact was promoted
+	GOOS      string
+	GOARCH    string
+}
+
+// ShortHash returns the first 7 characters of Hash, eg: for app-v1.4.2+abc1234.exe
+func (i BuildInfo) ShortHash() string {
+	if len(i.Hash) < 7 {
+		return i.Hash
+	}
+	return i.Hash[:7]
+}
+
+// NameFunc returns the file name (no directories) of a promoted build
+type NameFunc func(BuildInfo) string
+
+// publishNamed links the promoted artifact under the Config.FinalNameFunc name
+// The OutName+Extension file stays as the stable alias to the latest build
+// A hard link is used when possible, a copy otherwise (other volume, OutputFS)
+func (h *GoBuild) publishNamed(comp *Build) error {
+	if h.config.FinalNameFunc == nil {
+		return nil
+	}
+
+	goos, goarch := h.target()
+	info := BuildInfo{
+		ID:        comp.ID,
+		OutName:   h.config.OutName,
+		Extension: h.config.Extension,
+		Version:   h.config.Version,
+		Commit:    comp.commit,
+		Hash:      comp.hash,
+		Time:      h.now(),
+		GOOS:      goos,
+		GOARCH:    goarch,
+	}
+	if info.Version == "" {
+		info.Version = comp.version
+	}
+
+	name := h.config.FinalNameFunc(info)
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return fmt.Errorf("FinalNameFunc: %q must be a file name", name)
+	}
+	if name == h.outFileName {
+		return nil
+	}
+
+	finalPath, namedPath := h.FinalOutputPath(), h.outPath(name)
+	if err := h.linkArtifact(finalPath, namedPath); err != nil {
+		return errors.Join(fmt.Errorf("FinalNameFunc %q", namedPath), err)
+	}
+	comp.namedPath = namedPath
+	h.recordNamed(name)
+	h.archiveNamed(comp)
+	return nil
+}
+
+// recordNamed remembers a FinalNameFunc file name, see generatedFile
+func (h *GoBuild) recordNamed(name string) {
+	h.archiveMu.Lock()
+	defer h.archiveMu.Unlock()
+	if h.namedFiles == nil {
+		h.namedFiles = map[string]bool{}
+	}
+	h.namedFiles[name] = true
+}
+
+// linkArtifact makes dst a hard link (or copy) of the promoted src
+func (h *GoBuild) linkArtifact(src, dst string) error {
+	if h.config.OutputFS == nil {
+		os.Remove(fixLongPath(dst))
+		if os.Link(fixLongPath(src), fixLongPath(dst)) == nil {
+			return nil
+		}
+	}
+
+	in, err := h.openArtifact(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := h.createArtifact(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,67 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFinalNameFunc(t *testing.T) {
+	dir := t.TempDir()
+	var got BuildInfo
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Extension:                 ".exe",
+		OutFolderRelativePath:     dir,
+		Version:                   "v1.4.2",
+		FinalNameFunc: func(i BuildInfo) string {
+			got = i
+			return i.OutName + "-" + i.Version + "+" + i.ShortHash() + i.Extension
+		},
+	})
+
+	result, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := filepath.Join(dir, "app-v1.4.2+"+result.Hash[:7]+".exe")
+	if result.NamedPath != want {
+		t.Errorf("Expected NamedPath %q, got %q", want, result.NamedPath)
+	}
+	if got.ID != result.ID || got.Hash != result.Hash || got.Time.IsZero() {
+		t.Errorf("Unexpected BuildInfo %+v", got)
+	}
+	named, err := os.ReadFile(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alias, err := os.ReadFile(result.OutputPath)
+	if err != nil {
+		t.Fatal("Expected the stable alias to remain:", err)
+	}
+	if string(named) != string(alias) {
+		t.Error("Expected the named file and the alias to hold the same artifact")
+	}
+}
+
+func TestFinalNameFuncRejectsPaths(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		FinalNameFunc:             func(BuildInfo) string { return "../app" },
+	})
+
+	if _, err := gb.Compile(); err == nil {
+		t.Fatal("Expected an error for a name with directories")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "..", "app")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("Expected nothing written outside the output folder")
+	}
+}
@@ -0,0 +1,117 @@
+package gobuild
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+)
+
+// OutputFS receives the promoted artifact and its sidecar files (eg: main.wasm.gz)
+// The compiler always writes the temp file to the local disk, Config.OutputFS takes
+// over from the rename on, eg: to keep artifacts in memory for tests, inside a zip
+// or in an object store. Names are the paths FinalOutputPath would use on disk
+type OutputFS interface {
+	Promote(tempPath, name string) error        // move the finished local temp file (or bundle folder) to name
+	Open(name string) (io.ReadCloser, error)    // read back an artifact (verify, gzip, cache store)
+	Create(name string) (io.WriteCloser, error) // write a sidecar file
+	Stat(name string) (fs.FileInfo, error)      // artifact size for BuildResult
+}
+
+// OSFS is the local disk implementation of OutputFS
+// Unlike the built-in promotion used when Config.OutputFS is nil it has no
+// Windows lock diagnostics and ignores Config.Fsync
+type OSFS struct{}
+
+func (OSFS) Promote(tempPath, name string) error {
+	if info, err := os.Stat(fixLongPath(tempPath)); err == nil && info.IsDir() {
+		if err := os.RemoveAll(fixLongPath(name)); err != nil {
+			return err
+		}
+	}
+	return os.Rename(fixLongPath(tempPath), fixLongPath(name))
+}
+
+func (OSFS) Open(name string) (io.ReadCloser, error) {
+	return os.Open(fixLongPath(name))
+}
+
+func (OSFS) Create(name string) (io.WriteCloser, error) {
+	return os.Create(fixLongPath(name))
+}
+
+func (OSFS) Stat(name string) (fs.FileInfo, error) {
+	return os.Stat(fixLongPath(name))
+}
+
+// promoteTo moves the temp file into Config.OutputFS
+func (h *GoBuild) promoteTo(tempFileName string) error {
+	tempPath := h.tempPath(tempFileName)
+	finalPath := h.FinalOutputPath()
+	if err := h.config.OutputFS.Promote(tempPath, finalPath); err != nil {
+		h.cleanupTempFile(tempFileName)
+		h.logf(LogError, 0, "Promote failed:", fmt.Sprintf("%q -> %q:", tempPath, finalPath), err)
+		return &BuildError{
+			Code: ErrCodeRenameLocked,
+			Err:  errors.Join(fmt.Errorf("promote %q -> %q", tempPath, finalPath), err),
+		}
+	}
+	return nil
+}
+
+// openArtifact opens a promoted file from Config.OutputFS or the local disk
+func (h *GoBuild) openArtifact(name string) (io.ReadCloser, error) {
+	if h.config.OutputFS != nil {
+		return h.config.OutputFS.Open(name)
+	}
+	return os.Open(fixLongPath(name))
+}
+
+// createArtifact creates a sidecar file in Config.OutputFS or on the local disk
+func (h *GoBuild) createArtifact(name string) (io.WriteCloser, error) {
+	if h.config.OutputFS != nil {
+		return h.config.OutputFS.Create(name)
+	}
+	return os.Create(fixLongPath(name))
+}
+
+// statArtifact returns the file info of a promoted file
+func (h *GoBuild) statArtifact(name string) (fs.FileInfo, error) {
+	if h.config.OutputFS != nil {
+		return h.config.OutputFS.Stat(name)
+	}
+	return os.Stat(fixLongPath(name))
+}
+
+// hashArtifact returns the SHA-256 of a promoted file, bundle folders included on the local disk
+func (h *GoBuild) hashArtifact(name string) (string, error) {
+	if h.config.OutputFS == nil {
+		return hashFile(name)
+	}
+	f, err := h.config.OutputFS.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+// artifactSizeOf returns the size of the promoted artifact, see artifactSize
+func (h *GoBuild) artifactSizeOf(name string) int64 {
+	if h.config.OutputFS == nil {
+		return artifactSize(name)
+	}
+	info, err := h.config.OutputFS.Stat(name)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
@@ -0,0 +1,119 @@
+package gobuild
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// memFS is an in-memory OutputFS
+type memFS struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func newMemFS() *memFS { return &memFS{files: map[string][]byte{}} }
+
+func (m *memFS) Promote(tempPath, name string) error {
+	data, err := os.ReadFile(tempPath)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	m.files[name] = data
+	m.mu.Unlock()
+	return os.Remove(tempPath)
+}
+
+func (m *memFS) Open(name string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *memFS) Create(name string) (io.WriteCloser, error) {
+	return &memFile{fs: m, name: name}, nil
+}
+
+func (m *memFS) Stat(name string) (fs.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.files[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return fstest.MapFS{filepath.Base(name): {Data: data, ModTime: time.Now()}}.Stat(filepath.Base(name))
+}
+
+type memFile struct {
+	bytes.Buffer
+	fs   *memFS
+	name string
+}
+
+func (f *memFile) Close() error {
+	f.fs.mu.Lock()
+	f.fs.files[f.name] = f.Bytes()
+	f.fs.mu.Unlock()
+	return nil
+}
+
+func TestOutputFSInMemory(t *testing.T) {
+	dir := t.TempDir()
+	mem := newMemFS()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		OutputFS:                  mem,
+		VerifyArtifact:            true,
+	})
+
+	r, err := gb.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if got := string(mem.files[gb.FinalOutputPath()]); got != "artifact" {
+		t.Errorf("Expected the artifact in memory, got %q", got)
+	}
+	if r.Size != int64(len("artifact")) || r.Hash == "" {
+		t.Errorf("Unexpected result %+v", r)
+	}
+	if _, err := os.Stat(gb.FinalOutputPath()); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing on disk, got %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 { // only the fake compiler
+		t.Errorf("Expected no temp files left, got %v", entries)
+	}
+}
+
+func TestOSFSPromote(t *testing.T) {
+	dir := t.TempDir()
+	temp, final := filepath.Join(dir, "app_temp"), filepath.Join(dir, "app")
+	os.WriteFile(temp, []byte("new"), 0644)
+	os.WriteFile(final, []byte("old"), 0644)
+
+	var fsys OSFS
+	if err := fsys.Promote(temp, final); err != nil {
+		t.Fatal(err)
+	}
+	f, err := fsys.Open(final)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if data, _ := io.ReadAll(f); string(data) != "new" {
+		t.Errorf("Expected the promoted content, got %q", data)
+	}
+}
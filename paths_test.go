@@ -0,0 +1,101 @@
+package gobuild
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestQuoteLdflagValue(t *testing.T) {
+	tests := []struct{ in, expected string }{
+		{"main.version=v1.0.0", "main.version=v1.0.0"},
+		{"main.name=My App", "'main.name=My App'"},
+		{"main.name=It's mine", `"main.name=It's mine"`},
+		{"'main.name=My App'", "'main.name=My App'"},
+		{"main.greeting=¡Hola, señor!", "'main.greeting=¡Hola, señor!'"},
+	}
+	for _, tt := range tests {
+		if got := quoteLdflagValue(tt.in); got != tt.expected {
+			t.Errorf("quoteLdflagValue(%q): expected %q, got %q", tt.in, tt.expected, got)
+		}
+	}
+}
+
+func TestBuildArgumentsWithSpacesAndUnicode(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "cmd/mi app/main.go",
+		OutName:                   "aplicación",
+		Extension:                 ".exe",
+		OutFolderRelativePath:     "salida con espacios",
+		CompilingArguments: func() []string {
+			return []string{"-X", "main.name=Mi App", "-X main.city=São Paulo"}
+		},
+	})
+
+	expected := []string{
+		"build",
+		"-ldflags=-X 'main.name=Mi App' -X 'main.city=São Paulo'",
+		"-o", filepath.Join("salida con espacios", "aplicación_temp.exe"),
+		filepath.Join("cmd", "mi app", "main.go"),
+	}
+
+	args := gb.BuildArguments()
+	if len(args) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, args)
+	}
+	for i := range expected {
+		if args[i] != expected[i] {
+			t.Errorf("Argument %d: expected %q, got %q", i, expected[i], args[i])
+		}
+	}
+}
+
+func TestCompileWithSpacesAndUnicodePaths(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles with the real toolchain")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	tempDir := t.TempDir()
+	srcDir := filepath.Join(tempDir, "código fuente")
+	outDir := filepath.Join(tempDir, "salida con espacios")
+	os.MkdirAll(srcDir, 0755)
+	os.MkdirAll(outDir, 0755)
+
+	mainGoPath := filepath.Join(srcDir, "main.go")
+	mainGoContent := `package main
+
+var name = "default"
+
+func main() { println(name) }
+`
+	if err := os.WriteFile(mainGoPath, []byte(mainGoContent), 0644); err != nil {
+		t.Fatalf("Failed to create main.go: %v", err)
+	}
+
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: mainGoPath,
+		OutName:                   "aplicación ñ",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     outDir,
+		CompilingArguments:        func() []string { return []string{"-X", "main.name=Mi App"} },
+		Timeout:                   60 * time.Second,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Compilation failed: %v", err)
+	}
+
+	out, err := exec.Command(gb.FinalOutputPath()).CombinedOutput()
+	if err != nil {
+		t.Fatalf("Failed to run output: %v", err)
+	}
+	if string(out) != "Mi App\n" {
+		t.Errorf("Expected stamped name 'Mi App', got %q", out)
+	}
+}
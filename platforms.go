@@ -0,0 +1,59 @@
+package gobuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Platform is one GOOS/GOARCH pair known to the go toolchain
+type Platform struct {
+	GOOS         string
+	GOARCH       string
+	CgoSupported bool
+	FirstClass   bool // first-class port, see https://go.dev/wiki/PortingPolicy
+}
+
+// String returns the "goos/goarch" form used by NewMatrix, eg: "linux/arm64"
+func (p Platform) String() string {
+	return p.GOOS + "/" + p.GOARCH
+}
+
+// SupportedPlatforms lists the targets of the go toolchain (`go tool dist list`)
+// When the build env sets CGO_ENABLED=1 only the platforms supporting cgo are returned
+func (h *GoBuild) SupportedPlatforms(ctx context.Context) ([]Platform, error) {
+	tool := h.goTool()
+	cmd := exec.CommandContext(ctx, tool, "tool", "dist", "list", "-json")
+	cmd.Dir = h.config.WorkDir
+	env := h.maintenanceEnv()
+	cmd.Env = env
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s tool dist list: %w", tool, err)
+	}
+
+	var all []Platform
+	if err := json.Unmarshal(output, &all); err != nil {
+		return nil, fmt.Errorf("%s tool dist list: %w", tool, err)
+	}
+
+	cgo := ""
+	for _, entry := range env {
+		if v, ok := strings.CutPrefix(entry, "CGO_ENABLED="); ok {
+			cgo = v
+		}
+	}
+	if cgo != "1" {
+		return all, nil
+	}
+
+	platforms := all[:0]
+	for _, p := range all {
+		if p.CgoSupported {
+			platforms = append(platforms, p)
+		}
+	}
+	return platforms, nil
+}
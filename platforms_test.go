@@ -0,0 +1,40 @@
+package gobuild
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+)
+
+func TestSupportedPlatforms(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	all, err := New(&Config{Command: "go"}).SupportedPlatforms(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cgo, err := New(&Config{Command: "go", Env: []string{"CGO_ENABLED=1"}}).SupportedPlatforms(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	has := func(list []Platform, target string) bool {
+		for _, p := range list {
+			if p.String() == target {
+				return true
+			}
+		}
+		return false
+	}
+	if !has(all, "linux/amd64") || !has(all, "js/wasm") {
+		t.Errorf("Expected linux/amd64 and js/wasm, got %v", all)
+	}
+	if has(cgo, "js/wasm") || !has(cgo, "linux/amd64") {
+		t.Errorf("Expected only cgo platforms, got %v", cgo)
+	}
+	if len(cgo) >= len(all) {
+		t.Errorf("Expected fewer cgo platforms (%d) than platforms (%d)", len(cgo), len(all))
+	}
+}
@@ -0,0 +1,140 @@
+package gobuild
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy restricts which build flags and environment variables the user-supplied
+// configuration may set (eg: shared build services compiling third party code)
+// A nil slice leaves that dimension unrestricted, an empty slice allows nothing.
+// Entries ending in "*" match by prefix, eg: "CGO_*"
+type Policy struct {
+	AllowedFlags []string // eg: []string{"-tags", "-trimpath", "-X"}
+	AllowedEnv   []string // eg: []string{"GOOS", "GOARCH", "CGO_*"}
+}
+
+// PolicyViolation describes a single flag, argument or environment variable rejected by the policy
+type PolicyViolation struct {
+	Kind  string // "flag", "arg" (a positional argument, eg: a package pattern) or "env"
+	Name  string // eg: "-toolexec", "./...", "GOFLAGS"
+	Value string // the raw argument or env entry as supplied
+}
+
+// PolicyError is returned before any process is spawned when the configuration
+// breaks the policy. It lists every violation found, not only the first one.
+type PolicyError struct {
+	Violations []PolicyViolation
+}
+
+func (e *PolicyError) Error() string {
+	names := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		names = append(names, v.Kind+" "+v.Name)
+	}
+	return fmt.Sprintf("policy violation: %s not allowed", strings.Join(names, ", "))
+}
+
+// Check validates the user compiling arguments and env entries against the policy
+// Args are parsed like go build does: "--flag" is "-flag", the value-taking flags
+// (goBuildValueFlags) consume the next argument and any other positional argument
+// is a violation once AllowedFlags is set
+// Returns a *PolicyError when something is not allowed, nil otherwise
+// A nil policy allows everything
+func (p *Policy) Check(args, env []string) error {
+	if p == nil {
+		return nil
+	}
+
+	var violations []PolicyViolation
+
+	if p.AllowedFlags != nil {
+		flags, stray := parseArgs(args)
+		for _, arg := range flags {
+			if name := flagName(arg); !matchAny(p.AllowedFlags, name) {
+				violations = append(violations, PolicyViolation{Kind: "flag", Name: name, Value: arg})
+			}
+		}
+		for _, arg := range stray {
+			violations = append(violations, PolicyViolation{Kind: "arg", Name: arg, Value: arg})
+		}
+	}
+
+	if p.AllowedEnv != nil {
+		for _, entry := range env {
+			key, _, _ := strings.Cut(entry, "=")
+			if !matchAny(p.AllowedEnv, key) {
+				violations = append(violations, PolicyViolation{Kind: "env", Name: key, Value: entry})
+			}
+		}
+	}
+
+	if len(violations) > 0 {
+		return &PolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// goBuildValueFlags are the flags taking their value as the next argument, eg: -tags prod
+// go build, tinygo (-target, -opt...) and gomobile (-androidapi, -bundleid...) ones.
+// "-X" is the CompilingArguments shorthand folded into -ldflags
+var goBuildValueFlags = map[string]bool{
+	"-C": true, "-p": true, "-asmflags": true, "-buildmode": true, "-compiler": true,
+	"-covermode": true, "-coverpkg": true, "-gccgoflags": true, "-gcflags": true,
+	"-installsuffix": true, "-ldflags": true, "-mod": true, "-modfile": true, "-o": true,
+	"-overlay": true, "-pgo": true, "-pkgdir": true, "-tags": true, "-toolexec": true,
+	"-X": true, "-target": true, "-opt": true, "-scheduler": true, "-gc": true, "-panic": true,
+	"-size": true, "-stack-size": true, "-androidapi": true, "-bundleid": true,
+	"-iosversion": true, "-javapkg": true, "-prefix": true, "-bootclasspath": true, "-classpath": true,
+}
+
+// parseArgs splits args into flags (with their value when given as the next argument,
+// eg: "-tags prod") and stray positional arguments, eg: a package pattern or an extra file
+func parseArgs(args []string) (flags, stray []string) {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			stray = append(stray, arg)
+			continue
+		}
+		flags = append(flags, arg)
+		// "-tags=prod" carries its value, a bare "-tags" takes the next argument
+		if bare := strings.TrimPrefix(arg, "-"); goBuildValueFlags[bare] || goBuildValueFlags[arg] {
+			i++
+		}
+	}
+	return flags, stray
+}
+
+// strayArgs returns the arguments that are neither a flag nor a flag value, see parseArgs
+func strayArgs(args []string) []string {
+	_, stray := parseArgs(args)
+	return stray
+}
+
+// flagName returns the flag name without its value, "--flag" is spelled "-flag"
+// eg: "-tags=prod" => "-tags", "--tags=prod" => "-tags", "-X main.version=1" => "-X"
+func flagName(arg string) string {
+	if strings.HasPrefix(arg, "--") && len(arg) > 2 {
+		arg = arg[1:]
+	}
+	if strings.HasPrefix(arg, "-X") {
+		return "-X"
+	}
+	name, _, _ := strings.Cut(arg, "=")
+	return name
+}
+
+// matchAny reports whether name matches one of the patterns
+func matchAny(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if p == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package gobuild
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPolicyCheck(t *testing.T) {
+	policy := &Policy{
+		AllowedFlags: []string{"-tags", "-X"},
+		AllowedEnv:   []string{"GOOS", "GOARCH", "CGO_*"},
+	}
+
+	err := policy.Check(
+		[]string{"-tags", "prod", "-X", "main.version=1", "-toolexec=/bin/evil"},
+		[]string{"GOOS=js", "CGO_ENABLED=0", "GOFLAGS=-mod=mod"},
+	)
+
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Expected *PolicyError, got %v", err)
+	}
+
+	if len(policyErr.Violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %d: %v", len(policyErr.Violations), policyErr.Violations)
+	}
+	if policyErr.Violations[0].Kind != "flag" || policyErr.Violations[0].Name != "-toolexec" {
+		t.Errorf("Unexpected first violation: %+v", policyErr.Violations[0])
+	}
+	if policyErr.Violations[1].Kind != "env" || policyErr.Violations[1].Name != "GOFLAGS" {
+		t.Errorf("Unexpected second violation: %+v", policyErr.Violations[1])
+	}
+}
+
+func TestPolicyNilAllowsEverything(t *testing.T) {
+	var policy *Policy
+	if err := policy.Check([]string{"-toolexec=x"}, []string{"ANY=1"}); err != nil {
+		t.Errorf("Nil policy should allow everything, got %v", err)
+	}
+}
+
+func TestPolicyRejectedBeforeSpawn(t *testing.T) {
+	config := &Config{
+		Command:                   "nonexistentcommand",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "test",
+		OutFolderRelativePath:     ".",
+		CompilingArguments:        func() []string { return []string{"-race"} },
+		Policy:                    &Policy{AllowedFlags: []string{}},
+	}
+
+	err := New(config).CompileProgram()
+
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Expected *PolicyError before spawning the command, got %v", err)
+	}
+}
+
+func TestPolicyCheckParsesArgs(t *testing.T) {
+	policy := &Policy{AllowedFlags: []string{"-tags", "-trimpath"}}
+
+	// "--toolexec" is "-toolexec" and a value-less flag doesn't consume the positional after it
+	err := policy.Check([]string{"-tags", "prod", "--toolexec=/bin/evil", "-trimpath", "./evil"}, nil)
+
+	var policyErr *PolicyError
+	if !errors.As(err, &policyErr) {
+		t.Fatalf("Expected *PolicyError, got %v", err)
+	}
+	if len(policyErr.Violations) != 2 {
+		t.Fatalf("Expected 2 violations, got %d: %v", len(policyErr.Violations), policyErr.Violations)
+	}
+	if v := policyErr.Violations[0]; v.Kind != "flag" || v.Name != "-toolexec" {
+		t.Errorf("Unexpected first violation: %+v", v)
+	}
+	if v := policyErr.Violations[1]; v.Kind != "arg" || v.Name != "./evil" {
+		t.Errorf("Unexpected second violation: %+v", v)
+	}
+
+	if err := policy.Check([]string{"--tags", "prod", "-trimpath"}, nil); err != nil {
+		t.Errorf("Expected --tags to match -tags, got %v", err)
+	}
+}
+
+func TestPolicyIgnoresGeneratedArgs(t *testing.T) {
+	dir := t.TempDir()
+	config := &Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		CompilingArguments:        func() []string { return []string{"-tags", "prod"} },
+		BuildSalt:                 true,
+		Labels:                    Labels{"team": "web"},
+		LabelsVar:                 "main.buildLabels",
+		Policy:                    &Policy{AllowedFlags: []string{"-tags"}},
+	}
+
+	// the -ldflags salt and -X labels are added by gobuild, the policy only sees "-tags prod"
+	err := New(config).CompileProgram()
+	var policyErr *PolicyError
+	if errors.As(err, &policyErr) {
+		t.Fatalf("Generated flags shouldn't be checked by the policy, got %v", err)
+	}
+}
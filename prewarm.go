@@ -0,0 +1,23 @@
+package gobuild
+
+import "context"
+
+// Prewarm starts a background compile to populate the go build cache, so the
+// first build requested by the user after launching the dev tool isn't the slow one
+// The artifact is promoted like any build unless Config.PrewarmDiscard is set,
+// in which case it is deleted and the Callback is not invoked
+// A build requested meanwhile supersedes it per Config.CancelMode, cancelling ctx stops it
+func (h *GoBuild) Prewarm(ctx context.Context) *Build {
+	comp := h.newCompilation()
+	comp.label = h.config.Label
+	comp.labels = h.buildLabels(nil)
+	comp.discard = h.config.PrewarmDiscard
+	h.submit(comp)
+
+	stop := context.AfterFunc(ctx, comp.Cancel)
+	go func() {
+		<-comp.done
+		stop()
+	}()
+	return comp
+}
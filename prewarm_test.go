@@ -0,0 +1,66 @@
+package gobuild
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPrewarmDiscard(t *testing.T) {
+	dir := t.TempDir()
+	callbacks := 0
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		PrewarmDiscard:            true,
+		Callback:                  func(error) { callbacks++ },
+	})
+
+	b := gb.Prewarm(context.Background())
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Prewarm failed: %v", err)
+	}
+	if callbacks != 0 {
+		t.Errorf("Discarded prewarm must not invoke the Callback, got %d calls", callbacks)
+	}
+	if r := b.Result(); r.OutputPath != "" || r.Hash != "" {
+		t.Errorf("Expected no artifact in the result, got %+v", r)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if e.Name() != "fakecompiler.sh" {
+			t.Errorf("Expected no artifact left behind, found %s", e.Name())
+		}
+	}
+}
+
+func TestPrewarmKeepsArtifact(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+
+	if err := gb.Prewarm(context.Background()).Wait(); err != nil {
+		t.Fatalf("Prewarm failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app")); err != nil {
+		t.Errorf("Expected promoted artifact: %v", err)
+	}
+}
+
+func TestPrewarmContextCancel(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+	ctx, cancel := context.WithCancel(context.Background())
+	b := gb.Prewarm(ctx)
+	cancel()
+	if err := b.Wait(); CodeOf(err) != ErrCodeCancelled {
+		t.Errorf("Expected %s, got %q (%v)", ErrCodeCancelled, CodeOf(err), err)
+	}
+}
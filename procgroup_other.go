@@ -0,0 +1,18 @@
+//go:build !unix && !windows
+
+package gobuild
+
+import "os/exec"
+
+// procGroup has nothing to track on systems without process groups nor job objects
+type procGroup struct {
+	cmd *exec.Cmd
+}
+
+func isolateGroup(cmd *exec.Cmd) *procGroup {
+	return &procGroup{cmd: cmd}
+}
+
+func (g *procGroup) wait() error {
+	return g.cmd.Wait()
+}
@@ -0,0 +1,56 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// procGroup is the process group the compiler leads: killing only the go command
+// leaves its linker child writing the output. The group is signalled only while
+// the leader isn't reaped, afterwards its pid (the group id) may be reused
+type procGroup struct {
+	cmd    *exec.Cmd
+	mu     sync.Mutex
+	reaped bool
+}
+
+// isolateGroup starts cmd in its own process group, cancelling the build kills the whole group
+func isolateGroup(cmd *exec.Cmd) *procGroup {
+	g := &procGroup{cmd: cmd}
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+	cmd.Cancel = g.kill
+	return g
+}
+
+// kill signals every process of the group, only the leader once it may have been reaped
+func (g *procGroup) kill() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.reaped {
+		return g.cmd.Process.Kill()
+	}
+	if err := syscall.Kill(-g.cmd.Process.Pid, syscall.SIGKILL); err == syscall.ESRCH {
+		return os.ErrProcessDone
+	}
+	return nil
+}
+
+// wait waits for the compiler to exit, kills the children it left behind while the
+// unreaped leader still holds the group id and then reaps it with cmd.Wait
+// Without a way to wait for the exit without reaping, the group is killed on cancel only
+func (g *procGroup) wait() error {
+	if waitExited(g.cmd.Process.Pid) {
+		g.kill()
+	}
+	g.mu.Lock()
+	g.reaped = true
+	g.mu.Unlock()
+	return g.cmd.Wait()
+}
@@ -0,0 +1,64 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// processRunning reports whether pid is alive, zombies count as gone
+func processRunning(pid int) bool {
+	out, _ := exec.Command("ps", "-o", "stat=", "-p", strconv.Itoa(pid)).Output()
+	state := strings.TrimSpace(string(out))
+	return state != "" && !strings.HasPrefix(state, "Z")
+}
+
+func TestProcessGroupKilledAfterBuild(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "grandchild")
+
+	// a foreign process group that must survive the build
+	foreign := exec.Command("sleep", "30")
+	foreign.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if err := foreign.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		foreign.Process.Kill()
+		foreign.Wait()
+	}()
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler+"\nsleep 30 >/dev/null 2>&1 &\necho $! > "+pidFile),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Compilation failed: %v", err)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grandchild, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(grandchild) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Grandchild %d outlived the build", grandchild)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if !processRunning(foreign.Process.Pid) {
+		t.Error("A foreign process group was killed")
+	}
+}
@@ -0,0 +1,50 @@
+//go:build windows
+
+package gobuild
+
+import (
+	"os/exec"
+	"sync"
+	"syscall"
+)
+
+// procGroup holds the job object attachSandbox puts the compiler in, terminating
+// the job kills the go command together with the compile and link children it spawned
+type procGroup struct {
+	cmd *exec.Cmd
+	mu  sync.Mutex
+	job syscall.Handle // 0 until attachSandbox assigned the compiler, and once released
+}
+
+// isolateGroup makes cancelling the build terminate the job, the pipes held open by
+// children are given up after firstErrorWaitDelay
+func isolateGroup(cmd *exec.Cmd) *procGroup {
+	g := &procGroup{cmd: cmd}
+	cmd.Cancel = g.kill
+	cmd.WaitDelay = firstErrorWaitDelay
+	return g
+}
+
+// setJob records the job of the compiler, 0 once its handle is closed
+func (g *procGroup) setJob(job syscall.Handle) {
+	g.mu.Lock()
+	g.job = job
+	g.mu.Unlock()
+}
+
+// kill terminates the job, only the compiler while it isn't assigned yet
+func (g *procGroup) kill() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.job == 0 {
+		return g.cmd.Process.Kill()
+	}
+	if ok, _, callErr := procTerminateJobObject.Call(uintptr(g.job), 1); ok == 0 {
+		return callErr
+	}
+	return nil
+}
+
+func (g *procGroup) wait() error {
+	return g.cmd.Wait()
+}
@@ -0,0 +1,37 @@
+package gobuild
+
+// ProfilingOptions is a preset for builds used in performance investigations
+// It keeps symbols and uncompressed DWARF (drops -s and -w), frame pointers are
+// kept by the go toolchain on amd64/arm64, and optionally stamps a variable the
+// app reads to start a pprof listener, eg: if pprofAddr != "" { go http.ListenAndServe(pprofAddr, nil) }
+type ProfilingOptions struct {
+	PprofVar  string // -X variable enabling the pprof listener, eg: "main.pprofAddr"
+	PprofAddr string // value stamped into PprofVar, defaults to "localhost:6060"
+}
+
+// ldflags adjusts the linker flags for profiling
+func (p *ProfilingOptions) ldflags(ldFlags []string) []string {
+	var fields []string
+	for _, f := range ldFlags {
+		fields = append(fields, splitQuoted(f)...)
+	}
+
+	out := make([]string, 0, len(fields)+3)
+	for _, f := range fields {
+		// stripped binaries can't be symbolized by pprof/perf
+		if f == "-s" || f == "-w" || f == "-s=true" || f == "-w=true" {
+			continue
+		}
+		out = append(out, f)
+	}
+	out = append(out, "-compressdwarf=false")
+
+	if p.PprofVar != "" {
+		addr := p.PprofAddr
+		if addr == "" {
+			addr = "localhost:6060"
+		}
+		out = append(out, "-X", quoteLdflagValue(p.PprofVar+"="+addr))
+	}
+	return out
+}
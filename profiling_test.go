@@ -0,0 +1,43 @@
+package gobuild
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProfilingPresetArguments(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		CompilingArguments: func() []string {
+			return []string{"-ldflags=-s -w", "-X", "main.version=v1", "-tags", "prod"}
+		},
+		Profiling: &ProfilingOptions{PprofVar: "main.pprofAddr"},
+	})
+
+	expected := []string{
+		"build", "-tags", "prod",
+		"-ldflags=-X main.version=v1 -compressdwarf=false -X main.pprofAddr=localhost:6060",
+		"-o", "build/app_temp", "main.go",
+	}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+}
+
+func TestUserLdflagsMergedWithX(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		CompilingArguments: func() []string {
+			return []string{"-ldflags=-s -w", "-X", "main.version=v1"}
+		},
+	})
+
+	expected := []string{"build", "-ldflags=-s -w -X main.version=v1", "-o", "build/app_temp", "main.go"}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+}
@@ -0,0 +1,53 @@
+package gobuild
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Files written next to the quarantined artifact
+const (
+	quarantineOutput = "output.log" // captured compiler stdout and stderr
+	quarantineError  = "error.txt"  // the build error
+)
+
+// removeFailedTemp removes the temp file of a failed compile, unless Config.QuarantineDir
+// keeps it for quarantine
+func (h *GoBuild) removeFailedTemp(comp *Build) {
+	if h.config.QuarantineDir == "" {
+		h.cleanupTempFile(comp.tempFile)
+	}
+}
+
+// quarantine moves what a failed build left (the partially linked temp file, if any)
+// into QuarantineDir/<OutName>-<build ID>-<start time> with its output and error,
+// cancelled and superseded builds excluded
+func (h *GoBuild) quarantine(comp *Build, err error) {
+	if h.config.QuarantineDir == "" || comp.discard || CodeOf(err) == ErrCodeCancelled {
+		return
+	}
+	dir := filepath.Join(h.resolve(h.config.QuarantineDir),
+		fmt.Sprintf("%s-%d-%s", h.config.OutName, comp.ID, comp.startTime.Format("20060102T150405")))
+	if mkErr := os.MkdirAll(fixLongPath(dir), 0o755); mkErr != nil {
+		h.logf(LogWarn, comp.ID, "Quarantine failed:", mkErr)
+		return
+	}
+
+	tempPath := h.tempPath(comp.tempFile)
+	if _, statErr := os.Stat(fixLongPath(tempPath)); statErr == nil {
+		if mvErr := os.Rename(fixLongPath(tempPath), fixLongPath(filepath.Join(dir, comp.tempFile))); mvErr != nil {
+			h.logf(LogWarn, comp.ID, "Quarantine failed:", mvErr)
+		}
+	}
+	writeErr := errors.Join(
+		os.WriteFile(fixLongPath(filepath.Join(dir, quarantineOutput)), []byte(comp.output), 0o644),
+		os.WriteFile(fixLongPath(filepath.Join(dir, quarantineError)), []byte(err.Error()+"\n"), 0o644),
+	)
+	if writeErr != nil {
+		h.logf(LogWarn, comp.ID, "Quarantine failed:", writeErr)
+	}
+	comp.failedDir = dir
+	h.logf(LogInfo, comp.ID, "Quarantined build", comp.ID, "in", dir)
+}
@@ -0,0 +1,49 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuarantineFailedBuild(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, `prev=""; for arg; do if [ "$prev" = "-o" ]; then printf partial > "$arg"; fi; prev="$arg"; done; echo 'link: signal: segmentation fault' >&2; exit 2`),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     out,
+		QuarantineDir:             filepath.Join(dir, "quarantine"),
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err == nil {
+		t.Fatal("Expected the build to fail")
+	}
+	q := b.Result().QuarantinePath
+	if !strings.HasPrefix(filepath.Base(q), "app-1-") {
+		t.Fatalf("Expected a quarantine folder for build 1, got %q", q)
+	}
+
+	entries, _ := os.ReadDir(q)
+	var artifact string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), "app_temp_") {
+			artifact = e.Name()
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(q, artifact)); string(data) != "partial" {
+		t.Errorf("Expected the partially linked temp file, got entries %v", entries)
+	}
+	if data, _ := os.ReadFile(filepath.Join(q, quarantineOutput)); !strings.Contains(string(data), "segmentation fault") {
+		t.Errorf("Expected the compiler output, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(q, quarantineError)); !strings.Contains(string(data), "exit status 2") {
+		t.Errorf("Expected the build error, got %q", data)
+	}
+	if left, _ := os.ReadDir(out); len(left) != 0 {
+		t.Errorf("Expected nothing left in the output folder, got %v", left)
+	}
+}
@@ -0,0 +1,84 @@
+package gobuild
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// BuildOptions describes a single build request, see StartWith
+// With CancelQueue every request runs to completion, eg: ldflags variants back to back:
+//
+//	gb.StartWith(BuildOptions{Label: "free", Args: []string{"-X main.edition=free"}})
+//	gb.StartWith(BuildOptions{Label: "pro", Args: []string{"-X main.edition=pro"}})
+type BuildOptions struct {
+	Label        string          // shown in PendingBuilds and audit records, defaults to Config.Label
+	Priority     int             // with CancelQueue higher priorities start first, equal ones in arrival order
+	Args         []string        // appended to CompilingArguments for this build only, fixed when requested
+	Salt         bool            // force a distinct binary for this build, see Config.BuildSalt
+	Labels       Labels          // merged over Config.Labels, eg: {"pipeline": "4711"}
+	TraceContext context.Context // parent of the Config.TracerProvider span, eg: the request that triggered the build
+}
+
+// QueuedBuild is a snapshot of a build waiting for the active one to finish
+type QueuedBuild struct {
+	ID         uint64
+	Label      string
+	Priority   int
+	EnqueuedAt time.Time
+}
+
+// PendingBuilds returns the builds waiting to start, in the order they will run
+// Only CancelSoft (at most one) and CancelQueue keep builds waiting
+func (h *GoBuild) PendingBuilds() []QueuedBuild {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	pending := make([]QueuedBuild, 0, len(h.queue))
+	for _, b := range h.queue {
+		pending = append(pending, QueuedBuild{
+			ID:         b.ID,
+			Label:      b.label,
+			Priority:   b.priority,
+			EnqueuedAt: b.enqueued,
+		})
+	}
+	return pending
+}
+
+// RemoveQueued drops a build that has not started yet, its Wait returns ErrCancelled
+// Returns false when id is not queued (unknown, already running or finished)
+func (h *GoBuild) RemoveQueued(id uint64) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	b := h.unqueue(id)
+	if b == nil {
+		return false
+	}
+	h.drop(b, ErrCancelled)
+	return true
+}
+
+// enqueue inserts comp after every queued build with the same or higher priority
+// Must be called with h.mu held
+func (h *GoBuild) enqueue(comp *Build) {
+	i := sort.Search(len(h.queue), func(i int) bool {
+		return h.queue[i].priority < comp.priority
+	})
+	h.queue = append(h.queue, nil)
+	copy(h.queue[i+1:], h.queue[i:])
+	h.queue[i] = comp
+}
+
+// unqueue removes the queued build with the given id and returns it, nil if not queued
+// Must be called with h.mu held
+func (h *GoBuild) unqueue(id uint64) *Build {
+	for i, b := range h.queue {
+		if b.ID == id {
+			h.queue = append(h.queue[:i], h.queue[i+1:]...)
+			return b
+		}
+	}
+	return nil
+}
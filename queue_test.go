@@ -0,0 +1,96 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueueIntrospection(t *testing.T) {
+	gb := newSlowBuild(t, 5*time.Second)
+	gb.config.CancelMode = CancelQueue
+
+	running := gb.StartWith(BuildOptions{Label: "running"})
+	time.Sleep(50 * time.Millisecond)
+
+	low := gb.StartWith(BuildOptions{Label: "low"})
+	high := gb.StartWith(BuildOptions{Label: "high", Priority: 10})
+	removed := gb.StartWith(BuildOptions{Label: "removed"})
+
+	pending := gb.PendingBuilds()
+	var labels []string
+	for _, q := range pending {
+		labels = append(labels, q.Label)
+		if q.EnqueuedAt.IsZero() {
+			t.Errorf("Expected enqueue time for %q", q.Label)
+		}
+	}
+	if len(labels) != 3 || labels[0] != "high" || labels[1] != "low" || labels[2] != "removed" {
+		t.Fatalf("Expected [high low removed], got %v", labels)
+	}
+
+	if !gb.RemoveQueued(removed.ID) {
+		t.Fatal("Expected queued build to be removed")
+	}
+	if gb.RemoveQueued(running.ID) {
+		t.Error("Running build must not be removable from the queue")
+	}
+	if err := removed.Wait(); !errors.Is(err, ErrCancelled) {
+		t.Errorf("Expected ErrCancelled for the removed build, got: %v", err)
+	}
+
+	if err := running.Wait(); err != nil {
+		t.Errorf("Running build should finish in queue mode, got: %v", err)
+	}
+	if err := high.Wait(); err != nil {
+		t.Errorf("Expected high priority build to succeed, got: %v", err)
+	}
+	if low.Result() != nil {
+		t.Error("Low priority build should still be running after the high priority one")
+	}
+	if err := low.Wait(); err != nil {
+		t.Errorf("Expected low priority build to succeed, got: %v", err)
+	}
+	if n := len(gb.PendingBuilds()); n != 0 {
+		t.Errorf("Expected empty queue, got %d", n)
+	}
+}
+
+func TestQueueVariantsRunInOrder(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo \"$@\" >> "+log+"; sleep 0.1; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		CancelMode:                CancelQueue,
+	})
+
+	var builds []*Build
+	for _, edition := range []string{"free", "pro", "team"} {
+		builds = append(builds, gb.StartWith(BuildOptions{Label: edition, Args: []string{"-X main.edition=" + edition}}))
+	}
+	for _, b := range builds {
+		if err := b.Wait(); err != nil {
+			t.Fatalf("Build %d failed: %v", b.ID, err)
+		}
+	}
+
+	data, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 compiler runs, got %q", lines)
+	}
+	for i, edition := range []string{"free", "pro", "team"} {
+		if !strings.Contains(lines[i], "-ldflags=-X main.edition="+edition) {
+			t.Errorf("Run %d: expected the %s variant, got %q", i, edition, lines[i])
+		}
+	}
+}
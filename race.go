@@ -0,0 +1,43 @@
+package gobuild
+
+import "slices"
+
+// raceTargets are the platforms with race detector support (go 1.22)
+var raceTargets = map[string]bool{
+	"linux/amd64": true, "linux/arm64": true, "linux/ppc64le": true, "linux/s390x": true,
+	"darwin/amd64": true, "darwin/arm64": true, "freebsd/amd64": true, "netbsd/amd64": true,
+	"windows/amd64": true,
+}
+
+// raceArgs returns -race for Config.Race unless CompilingArguments already has it
+func (h *GoBuild) raceArgs(args []string) []string {
+	if !h.config.Race || slices.Contains(args, "-race") {
+		return nil
+	}
+	return []string{"-race"}
+}
+
+// raceIssues returns the settings Config.Race can't build with
+func (h *GoBuild) raceIssues(add func(field, format string, args ...any)) {
+	goos, goarch := h.target()
+	if !raceTargets[goos+"/"+goarch] {
+		add("Race", "the race detector is not supported on %s/%s", goos, goarch)
+	}
+	if h.tinyGo() {
+		add("Race", "not supported by tinygo")
+	}
+
+	// darwin links the race runtime without cgo since go 1.20
+	env, _ := h.userEnv()
+	for _, e := range env {
+		if e == "CGO_ENABLED=0" && goos != "darwin" {
+			add("Race", "requires cgo, the env sets CGO_ENABLED=0")
+		}
+	}
+
+	for _, arg := range h.compilingArguments() {
+		if arg == "-msan" || arg == "-asan" {
+			add("Race", "can't be combined with %s", arg)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package gobuild
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRaceArguments(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		Env:                       []string{"GOOS=linux", "GOARCH=amd64"},
+		Race:                      true,
+	})
+
+	expected := []string{"build", "-race", "-o", "build/app_temp", "main.go"}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+
+	gb.config.CompilingArguments = func() []string { return []string{"-race", "-v"} }
+	expected = []string{"build", "-race", "-v", "-o", "build/app_temp", "main.go"}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected a single -race, got %q", args)
+	}
+}
+
+func TestRaceValidation(t *testing.T) {
+	for _, tc := range []struct {
+		env  []string
+		args []string
+		want string
+	}{
+		{[]string{"GOOS=js", "GOARCH=wasm"}, nil, "not supported on js/wasm"},
+		{[]string{"GOOS=linux", "GOARCH=386"}, nil, "not supported on linux/386"},
+		{[]string{"GOOS=linux", "GOARCH=amd64", "CGO_ENABLED=0"}, nil, "requires cgo"},
+		{[]string{"GOOS=linux", "GOARCH=arm64"}, []string{"-msan"}, "can't be combined with -msan"},
+	} {
+		gb := New(&Config{
+			Command:                   "go",
+			MainInputFileRelativePath: "main.go",
+			OutName:                   "app",
+			OutFolderRelativePath:     t.TempDir(),
+			Env:                       tc.env,
+			CompilingArguments:        func() []string { return tc.args },
+			Race:                      true,
+		})
+
+		var verr *ValidationError
+		err := gb.Validate()
+		if !errors.As(err, &verr) || !strings.Contains(err.Error(), "Race: ") || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%v %v: expected a Race issue %q, got %v", tc.env, tc.args, tc.want, err)
+		}
+	}
+
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     t.TempDir(),
+		Env:                       []string{"GOOS=darwin", "GOARCH=arm64", "CGO_ENABLED=0"},
+		Race:                      true,
+	})
+	if err := gb.Validate(); err != nil {
+		t.Errorf("darwin builds the race runtime without cgo, got %v", err)
+	}
+}
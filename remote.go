@@ -0,0 +1,257 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RemoteRequest describes a build delegated to an Agent
+// The agent decides which compiler runs, only arguments and env travel with the request
+type RemoteRequest struct {
+	MainInputFileRelativePath string   // relative to the agent working tree, eg: cmd/server/main.go
+	Extension                 string   // eg: .exe, .wasm
+	Args                      []string // resolved CompilingArguments
+	Env                       []string // eg: []string{"GOOS=linux", "GOARCH=arm64"}
+}
+
+// Agent compiles delegated builds and returns the produced artifact
+// eg: an HTTPAgent pointing to another machine or a Coordinator spreading the load
+type Agent interface {
+	Build(ctx context.Context, req RemoteRequest) (io.ReadCloser, error)
+}
+
+// delegate sends the build to the configured agent and finishes the returned artifact
+// like a local one (FinalNameFunc, Manifest, wasm_exec.js, gzip and Compress copies)
+func (h *GoBuild) delegate(ctx context.Context, comp *Build, userArgs, userEnv []string) error {
+	req := RemoteRequest{
+		MainInputFileRelativePath: h.config.MainInputFileRelativePath,
+		Extension:                 h.config.Extension,
+		Args:                      userArgs,
+		Env:                       userEnv,
+	}
+
+	artifact, err := h.config.Agent.Build(ctx, req)
+	if err != nil {
+		if cause := context.Cause(ctx); cause != nil {
+			return fmt.Errorf("%w: delegate: %v", cause, err)
+		}
+		return errors.Join(errors.New("delegate"), err)
+	}
+	defer artifact.Close()
+
+	if err := writeFile(h.tempPath(comp.tempFile), artifact, 0755); err != nil {
+		h.cleanupTempFile(comp.tempFile)
+		return errors.Join(errors.New("delegate"), err)
+	}
+	return h.finishArtifact(ctx, comp)
+}
+
+// Coordinator is an Agent spreading builds across registered agents,
+// each build goes to the agent with the fewest builds in flight
+type Coordinator struct {
+	mu     sync.Mutex
+	agents []*agentLoad
+}
+
+type agentLoad struct {
+	agent    Agent
+	inFlight int
+}
+
+// Register adds an agent that receives builds from now on
+func (c *Coordinator) Register(a Agent) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.agents = append(c.agents, &agentLoad{agent: a})
+}
+
+func (c *Coordinator) Build(ctx context.Context, req RemoteRequest) (io.ReadCloser, error) {
+	c.mu.Lock()
+	var least *agentLoad
+	for _, a := range c.agents {
+		if least == nil || a.inFlight < least.inFlight {
+			least = a
+		}
+	}
+	if least == nil {
+		c.mu.Unlock()
+		return nil, errors.New("Coordinator: no agents registered")
+	}
+	least.inFlight++
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		least.inFlight--
+		c.mu.Unlock()
+	}()
+
+	return least.agent.Build(ctx, req)
+}
+
+// HTTPAgent sends builds to a remote AgentHandler
+// eg: &gobuild.HTTPAgent{URL: "http://build-arm64.local:7070/build"}
+type HTTPAgent struct {
+	URL    string
+	Header http.Header  // optional extra headers, eg: Authorization for BearerToken
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+func (a *HTTPAgent) Build(ctx context.Context, req RemoteRequest) (io.ReadCloser, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, a.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range a.Header {
+		httpReq.Header[k] = v
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		err := fmt.Errorf("HTTPAgent %s: %s %s", a.URL, resp.Status, msg)
+		if resp.StatusCode == http.StatusUnprocessableEntity {
+			// the agent's compiler ran and failed, see AgentHandler
+			return nil, &BuildError{Code: ErrCodeCompile, Err: err}
+		}
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// DefaultAgentPolicy limits the Args and Env of delegated builds when the AgentHandler
+// base has no Policy: target selection and a few harmless flags. Flags able to run other
+// programs (-toolexec, -ldflags with -extld, -exec...) and env such as GOFLAGS or CC are refused
+var DefaultAgentPolicy = &Policy{
+	AllowedFlags: []string{"-tags", "-trimpath", "-a", "-v", "-race", "-buildvcs", "-mod"},
+	AllowedEnv:   []string{"GOOS", "GOARCH", "GOARM", "GOARM64", "GOAMD64", "GO386", "GOWASM", "GOMIPS", "GOMIPS64", "GOPPC64", "GORISCV64", "CGO_ENABLED"},
+}
+
+// AgentAuthorizer accepts or rejects a delegated build request, eg: BearerToken
+type AgentAuthorizer func(r *http.Request) error
+
+// BearerToken accepts requests carrying "Authorization: Bearer <token>"
+// HTTPAgent clients send it with Header: http.Header{"Authorization": {"Bearer " + token}}
+func BearerToken(token string) AgentAuthorizer {
+	return func(r *http.Request) error {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			return errors.New("invalid bearer token")
+		}
+		return nil
+	}
+}
+
+// maxAgentRequest bounds the JSON body of a delegated build request
+const maxAgentRequest = 1 << 20
+
+// agentExtension matches the Extension of a delegated build, eg: .exe, .wasm
+var agentExtension = regexp.MustCompile(`^(\.[A-Za-z0-9]{1,16})?$`)
+
+// checkRemoteRequest rejects requests escaping workDir or breaking the agent policy
+func checkRemoteRequest(base Config, req RemoteRequest) error {
+	main := filepath.FromSlash(req.MainInputFileRelativePath)
+	if main == "" || !filepath.IsLocal(main) {
+		return fmt.Errorf("MainInputFileRelativePath %q must be a relative path inside the agent working tree", req.MainInputFileRelativePath)
+	}
+	if !agentExtension.MatchString(req.Extension) {
+		return fmt.Errorf("Extension %q must be empty or a plain .ext", req.Extension)
+	}
+	// positional args would add package patterns or files to the build
+	if stray := strayArgs(req.Args); len(stray) > 0 {
+		return fmt.Errorf("Args %q are not flags nor flag values", stray)
+	}
+	policy := base.Policy
+	if policy == nil {
+		policy = DefaultAgentPolicy
+	}
+	return policy.Check(req.Args, req.Env)
+}
+
+// AgentHandler serves delegated builds over HTTP for HTTPAgent clients
+// base provides the Command, Timeout, Policy, Sandbox... used for every build,
+// MainInputFileRelativePath of each request is resolved against workDir and can't leave it.
+// Every request must pass authorize (nil rejects them all, see BearerToken), then
+// base.Policy, or DefaultAgentPolicy when it is nil, before anything runs.
+// The transport is plain HTTP/JSON on purpose instead of gRPC: the module has no
+// dependencies and the API is a single call returning the artifact bytes
+func AgentHandler(base Config, workDir string, authorize AgentAuthorizer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if authorize == nil {
+			http.Error(w, "AgentHandler: no authorizer configured", http.StatusForbidden)
+			return
+		}
+		if err := authorize(r); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		var req RemoteRequest
+		dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAgentRequest))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := checkRemoteRequest(base, req); err != nil {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		outDir, err := os.MkdirTemp("", "gobuild_agent")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer os.RemoveAll(outDir)
+
+		c := base
+		c.MainInputFileRelativePath = filepath.Join(workDir, filepath.FromSlash(req.MainInputFileRelativePath))
+		c.OutName = "artifact"
+		c.Extension = req.Extension
+		c.OutFolderRelativePath = outDir
+		c.CompilingArguments = func() []string { return req.Args }
+		c.Env = append(append([]string{}, base.Env...), req.Env...)
+		c.Callback = nil
+		c.Agent = nil
+
+		gb := New(&c)
+		result := <-gb.CompileAsync(r.Context())
+		if result.Err != nil {
+			http.Error(w, result.Err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		http.ServeFile(w, r, result.OutputPath)
+	})
+}
@@ -0,0 +1,149 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// agentAuth is the header matching BearerToken("secret")
+var agentAuth = http.Header{"Authorization": {"Bearer secret"}}
+
+func TestDelegateToHTTPAgent(t *testing.T) {
+	agentDir := t.TempDir()
+	srv := httptest.NewServer(AgentHandler(Config{
+		Command: writeFakeCompiler(t, agentDir, fakeEchoCompiler),
+	}, agentDir, BearerToken("secret")))
+	defer srv.Close()
+
+	coordinator := &Coordinator{}
+	coordinator.Register(&HTTPAgent{URL: srv.URL, Header: agentAuth})
+
+	outDir := t.TempDir()
+	gb := New(&Config{
+		Command:                   "nonexistentcommand", // never run locally
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     outDir,
+		Agent:                     coordinator,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Delegated build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(outDir, "app"))
+	if err != nil || string(data) != "artifact" {
+		t.Errorf("Expected artifact gathered from the agent, got %q (%v)", data, err)
+	}
+}
+
+func TestDelegateFinishesArtifact(t *testing.T) {
+	agentDir := t.TempDir()
+	srv := httptest.NewServer(AgentHandler(Config{
+		Command: writeFakeCompiler(t, agentDir, fakeEchoCompiler),
+	}, agentDir, BearerToken("secret")))
+	defer srv.Close()
+
+	outDir := t.TempDir()
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     outDir,
+		Agent:                     &HTTPAgent{URL: srv.URL, Header: agentAuth},
+		Manifest:                  true,
+		Compress:                  &Compress{Gzip: true},
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Delegated build failed: %v", err)
+	}
+	for _, name := range []string{"app" + manifestSuffix, "app.gz"} {
+		if _, err := os.Stat(filepath.Join(outDir, name)); err != nil {
+			t.Errorf("Expected %s next to the delegated artifact: %v", name, err)
+		}
+	}
+}
+
+func TestCoordinatorWithoutAgents(t *testing.T) {
+	_, err := (&Coordinator{}).Build(context.Background(), RemoteRequest{})
+	if err == nil {
+		t.Error("Expected error when no agents are registered")
+	}
+}
+
+// failingAgent always reports a build failure
+type failingAgent struct{}
+
+func (failingAgent) Build(context.Context, RemoteRequest) (io.ReadCloser, error) {
+	return nil, errors.New("remote build failed")
+}
+
+func TestDelegateFailure(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     t.TempDir(),
+		Agent:                     failingAgent{},
+	})
+
+	if err := gb.CompileProgram(); err == nil {
+		t.Error("Expected the agent failure to be returned")
+	}
+}
+
+func TestAgentHandlerRejects(t *testing.T) {
+	agentDir := t.TempDir()
+	marker := filepath.Join(agentDir, "ran")
+	base := Config{Command: writeFakeCompiler(t, agentDir, "touch "+marker+"; "+fakeEchoCompiler)}
+	secured := httptest.NewServer(AgentHandler(base, agentDir, BearerToken("secret")))
+	defer secured.Close()
+	open := httptest.NewServer(AgentHandler(base, agentDir, nil))
+	defer open.Close()
+
+	for name, tc := range map[string]struct {
+		url    string
+		header http.Header
+		req    RemoteRequest
+	}{
+		"no authorizer": {open.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go"}},
+		"bad token":     {secured.URL, http.Header{"Authorization": {"Bearer guess"}}, RemoteRequest{MainInputFileRelativePath: "main.go"}},
+		"toolexec":      {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go", Args: []string{"-toolexec=/bin/sh"}}},
+		"ldflags":       {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go", Args: []string{"-ldflags=-extld=/bin/sh"}}},
+		"goflags env":   {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go", Env: []string{"GOFLAGS=-toolexec=/bin/sh"}}},
+		"parent dir":    {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "../other/main.go"}},
+		"absolute":      {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "/etc/main.go"}},
+		"extension":     {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go", Extension: "/../../x"}},
+		"positional":    {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go", Args: []string{"-v", "./other/..."}}},
+		"extra file":    {secured.URL, agentAuth, RemoteRequest{MainInputFileRelativePath: "main.go", Args: []string{"-tags", "prod", "/etc/evil.go"}}},
+	} {
+		agent := &HTTPAgent{URL: tc.url, Header: tc.header}
+		if _, err := agent.Build(context.Background(), tc.req); err == nil {
+			t.Errorf("%s: expected the request to be refused", name)
+		}
+	}
+	for name, body := range map[string]string{
+		"unknown field": `{"MainInputFileRelativePath": "main.go", "Command": "/bin/sh"}`,
+		"too large":     `{"MainInputFileRelativePath": "main.go", "Args": ["-v"], "Env": ["` + strings.Repeat("x", maxAgentRequest) + `"]}`,
+	} {
+		req, _ := http.NewRequest(http.MethodPost, secured.URL, strings.NewReader(body))
+		req.Header = agentAuth.Clone()
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected %d, got %s", name, http.StatusBadRequest, resp.Status)
+		}
+	}
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("The compiler must not run for refused requests")
+	}
+}
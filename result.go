@@ -0,0 +1,106 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"io/fs"
+	"path/filepath"
+	"time"
+)
+
+// BuildResult describes the outcome of a finished build
+type BuildResult struct {
+	ID                uint64          `json:"id"`                    // build id, see Build.ID
+	OutputPath        string          `json:"output_path,omitempty"` // final artifact path, empty when the build failed
+	NamedPath         string          `json:"named_path,omitempty"`  // Config.FinalNameFunc file, OutputPath is its stable alias. Empty when unused or failed
+	StartTime         time.Time       `json:"start_time"`            // zero if the build was dropped before starting
+	EndTime           time.Time       `json:"end_time"`              // when the result was produced
+	Duration          time.Duration   `json:"duration"`              // EndTime - StartTime, zero if never started
+	Hash              string          `json:"sha256,omitempty"`      // hex SHA-256 of the promoted artifact, empty when the build failed
+	RestoredFromCache bool            `json:"restored,omitempty"`    // artifact restored from Config.Cache/CacheDir instead of compiled
+	Size              int64           `json:"size"`                  // artifact size in bytes (bundles: sum of their files), 0 when the build failed
+	SizeDelta         int64           `json:"size_delta,omitempty"`  // Size minus that of the previous promoted artifact, 0 for the first one
+	ExitCode          int             `json:"exit_code"`             // compiler exit code, -1 when it didn't run (cache hit, validation error, killed)
+	ExitClass         ExitClass       `json:"exit_class,omitempty"`  // ExitCode per Config.ExitCodes (warning: succeeded with warnings), empty when it didn't run
+	Output            string          `json:"output,omitempty"`      // captured compiler stdout and stderr
+	Code              ErrorCode       `json:"code,omitempty"`        // failure category, empty on success
+	Failure           FailureKind     `json:"failure,omitempty"`     // why the build failed (syntax, type, missing dependency...), empty on success
+	Diagnostics       []Diagnostic    `json:"diagnostics,omitempty"` // compiler errors, warnings and notes, also present on success
+	WasmSizes         *WasmSizeReport `json:"wasm_sizes,omitempty"`  // TinyGoWasm step sizes, nil when the preset is off or the build failed
+	Compressed        *CompressReport `json:"compressed,omitempty"`  // sizes of the Config.Compress copies (.gz, .br), nil when off or the build failed
+	Timings           BuildTimings    `json:"timings"`               // time spent in each phase, eg: to find where a slow loop goes
+	PID               int             `json:"pid,omitempty"`         // process started with Config.RunAfterBuild, 0 otherwise
+	Salt              string          `json:"salt,omitempty"`        // random value linked in with Config.BuildSalt/BuildOptions.Salt, empty otherwise
+	CacheRecovery     string          `json:"recovery,omitempty"`    // remediation of a corrupted build cache before the retry (Config.CacheRecovery), eg: "go clean -cache"
+	Modules           *ModuleChanges  `json:"modules,omitempty"`     // go.mod requirements added, removed or updated since the previous build, nil when unchanged
+	QuarantinePath    string          `json:"quarantine,omitempty"`  // Config.QuarantineDir folder holding what the failed build left, empty otherwise
+	Err               error           `json:"-"`                     // nil on success, a *BuildError carrying Code otherwise
+}
+
+// Success reports whether the build produced the final artifact
+func (r *BuildResult) Success() bool {
+	return r.Err == nil
+}
+
+// MarshalJSON encodes the result with Err as its "error" message, eg: for Config.Webhooks
+// Durations are nanoseconds
+func (r *BuildResult) MarshalJSON() ([]byte, error) {
+	type result BuildResult // without MarshalJSON
+	out := struct {
+		*result
+		Error string `json:"error,omitempty"`
+	}{result: (*result)(r)}
+	if r.Err != nil {
+		out.Error = r.Err.Error()
+	}
+	return json.Marshal(out)
+}
+
+// newBuildResult creates the result of a finished or dropped build
+func (h *GoBuild) newBuildResult(b *Build, err error) *BuildResult {
+	r := &BuildResult{
+		ID:          b.ID,
+		StartTime:   b.startTime,
+		EndTime:     h.now(),
+		Err:         err,
+		Code:        CodeOf(err),
+		Failure:     classifyFailure(err, b.output, b.diags),
+		Diagnostics: b.diags,
+		ExitCode:    b.exitCode,
+		ExitClass:   b.exitClass,
+		Output:      b.output,
+		Timings:     b.timings,
+		Salt:        b.salt,
+	}
+	r.CacheRecovery = b.recovery
+	r.Modules = b.modules
+	r.QuarantinePath = b.failedDir
+	if !b.startTime.IsZero() {
+		r.Duration = r.EndTime.Sub(b.startTime)
+	}
+	if err == nil && !b.discard {
+		r.OutputPath = h.FinalOutputPath()
+		r.NamedPath = b.namedPath
+		r.Hash = b.hash
+		r.RestoredFromCache = b.restored
+		r.WasmSizes = b.wasmSizes
+		r.Compressed = b.compress
+		r.Size = h.artifactSizeOf(r.OutputPath)
+		r.SizeDelta = b.sizeDelta
+		r.PID = b.pid
+	}
+	return r
+}
+
+// artifactSize returns the size of a file, or of every file in a bundle directory
+func artifactSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(fixLongPath(path), func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
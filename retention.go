@@ -0,0 +1,77 @@
+package gobuild
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+)
+
+// RegisterPID tracks a process started from the build output (eg: by a supervisor
+// outside gobuild), Config.KeepArtifacts defers deleting the files it still maps
+func (h *GoBuild) RegisterPID(pid int) {
+	h.archiveMu.Lock()
+	defer h.archiveMu.Unlock()
+	if h.pids == nil {
+		h.pids = map[int]bool{}
+	}
+	h.pids[pid] = true
+}
+
+// UnregisterPID stops tracking pid, eg: once the process exited
+func (h *GoBuild) UnregisterPID(pid int) {
+	h.archiveMu.Lock()
+	defer h.archiveMu.Unlock()
+	delete(h.pids, pid)
+}
+
+// archiveNamed records the FinalNameFunc file of comp and deletes the ones beyond
+// Config.KeepArtifacts, oldest first. Files still mapped by the RunAfterBuild app or a
+// RegisterPID process are kept and retried after the next build
+func (h *GoBuild) archiveNamed(comp *Build) {
+	if h.config.KeepArtifacts <= 0 || h.config.OutputFS != nil {
+		return
+	}
+
+	h.archiveMu.Lock()
+	defer h.archiveMu.Unlock()
+	archived := h.archived[:0]
+	for _, path := range h.archived {
+		if path != comp.namedPath {
+			archived = append(archived, path)
+		}
+	}
+	archived = append(archived, comp.namedPath)
+
+	excess := len(archived) - h.config.KeepArtifacts
+	var kept []string
+	for i, path := range archived {
+		if i >= excess {
+			kept = append(kept, path)
+			continue
+		}
+		if pid, busy := h.mappedBy(path); busy {
+			h.logf(LogDebug, comp.ID, "Keeping", path, "mapped by pid", pid)
+			kept = append(kept, path)
+			continue
+		}
+		if err := os.Remove(fixLongPath(path)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			h.logf(LogWarn, comp.ID, "KeepArtifacts:", err)
+			kept = append(kept, path)
+		}
+	}
+	h.archived = kept
+}
+
+// mappedBy returns a tracked process still mapping path
+// Must be called with h.archiveMu held
+func (h *GoBuild) mappedBy(path string) (int, bool) {
+	if pid := h.AppPID(); pid != 0 && processMaps(pid, path) {
+		return pid, true
+	}
+	for pid := range h.pids {
+		if processMaps(pid, path) {
+			return pid, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,38 @@
+package gobuild
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// processMaps reports whether pid runs path or has it mapped (eg: plugins)
+// Hard links share the inode, so a process started from the stable OutName sees its named file as busy
+func processMaps(pid int, path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	proc := "/proc/" + strconv.Itoa(pid)
+	if exe, err := os.Stat(proc + "/exe"); err == nil && os.SameFile(exe, info) {
+		return true
+	}
+
+	maps, err := os.Open(proc + "/maps")
+	if err != nil {
+		return false
+	}
+	defer maps.Close()
+	scanner := bufio.NewScanner(maps)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			continue
+		}
+		if mapped, err := os.Stat(fields[5]); err == nil && os.SameFile(mapped, info) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,8 @@
+//go:build !unix && !windows
+
+package gobuild
+
+// processMaps can't inspect other processes here, a tracked process keeps every file
+func processMaps(pid int, path string) bool {
+	return true
+}
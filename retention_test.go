@@ -0,0 +1,63 @@
+package gobuild
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestKeepArtifactsDefersMappedFiles(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process mappings are read from /proc")
+	}
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep not available")
+	}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, `prev=""; for arg; do if [ "$prev" = "-o" ]; then cp `+sleep+` "$arg"; fi; prev="$arg"; done`),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     out,
+		FinalNameFunc:             func(i BuildInfo) string { return fmt.Sprintf("%s-%d", i.OutName, i.ID) },
+		KeepArtifacts:             1,
+	})
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(out, name))
+		return err == nil
+	}
+
+	first, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	running := exec.Command(first.NamedPath, "30")
+	if err := running.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer running.Process.Kill()
+	gb.RegisterPID(running.Process.Pid)
+
+	if _, err := gb.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if !exists("app-1") || !exists("app-2") {
+		t.Error("Expected app-1 kept while a registered process runs it")
+	}
+
+	running.Process.Kill()
+	running.Wait()
+	gb.UnregisterPID(running.Process.Pid)
+	if _, err := gb.Compile(); err != nil {
+		t.Fatal(err)
+	}
+	if exists("app-1") || exists("app-2") || !exists("app-3") {
+		t.Error("Expected only the last versioned file kept")
+	}
+}
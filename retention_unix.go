@@ -0,0 +1,18 @@
+//go:build unix && !linux
+
+package gobuild
+
+import (
+	"os"
+	"syscall"
+)
+
+// processMaps can't list the files mapped by pid without /proc: a tracked process
+// still alive is assumed to map path, the file is pruned once it exited
+func processMaps(pid int, path string) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	return p.Signal(syscall.Signal(0)) == nil
+}
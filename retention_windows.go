@@ -0,0 +1,27 @@
+//go:build windows
+
+package gobuild
+
+import (
+	"errors"
+	"syscall"
+)
+
+// errorSharingViolation is returned when another process holds path open without sharing
+const errorSharingViolation syscall.Errno = 32
+
+// processMaps reports whether path is in use: windows can't list the files a process
+// maps, so path is opened without sharing and a sharing violation means some process
+// (pid or any other) still runs or maps it
+func processMaps(pid int, path string) bool {
+	name, err := syscall.UTF16PtrFromString(fixLongPath(path))
+	if err != nil {
+		return false
+	}
+	handle, err := syscall.CreateFile(name, syscall.GENERIC_READ, 0, nil, syscall.OPEN_EXISTING, syscall.FILE_ATTRIBUTE_NORMAL, 0)
+	if err != nil {
+		return errors.Is(err, errorSharingViolation)
+	}
+	syscall.CloseHandle(handle)
+	return false
+}
@@ -0,0 +1,129 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// defaultRunStopTimeout is how long a running app gets to exit after the interrupt
+const defaultRunStopTimeout = 5 * time.Second
+
+// appProcess is the binary started with Config.RunAfterBuild
+type appProcess struct {
+	cmd  *exec.Cmd
+	done chan struct{} // closed once the process exited
+}
+
+// runIssues returns the settings Config.RunAfterBuild can't run with
+func (h *GoBuild) runIssues(add func(field, format string, args ...any)) {
+	c := h.config
+	if goos, goarch := h.target(); goos != runtime.GOOS || goarch != runtime.GOARCH {
+		add("RunAfterBuild", "can't run %s/%s binaries on %s/%s", goos, goarch, runtime.GOOS, runtime.GOARCH)
+	}
+	if c.Mobile != nil {
+		add("RunAfterBuild", "can't be combined with Mobile")
+	}
+	if c.OutputFS != nil {
+		add("RunAfterBuild", "the artifact must be on the local disk, not in OutputFS")
+	}
+}
+
+// startApp starts the freshly promoted artifact with Config.RunArgs
+// A previous app still running is stopped first, appMu is held throughout so
+// concurrent builds never leave an orphaned process behind
+func (h *GoBuild) startApp(comp *Build) error {
+	h.appMu.Lock()
+	defer h.appMu.Unlock()
+	if h.app != nil {
+		if err := h.stopApp(h.app); err != nil {
+			h.logf(LogWarn, comp.ID, err)
+		}
+		h.app = nil
+	}
+
+	path, err := filepath.Abs(h.FinalOutputPath())
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, h.config.RunArgs...)
+	cmd.Dir = h.config.WorkDir
+	if dir := h.CoverDir(); dir != "" {
+		cmd.Env = append(os.Environ(), "GOCOVERDIR="+dir)
+	}
+	cmd.Stdout = h.config.RunOutput
+	cmd.Stderr = h.config.RunOutput
+	if err := cmd.Start(); err != nil {
+		return errors.Join(errors.New("RunAfterBuild"), err)
+	}
+
+	app := &appProcess{cmd: cmd, done: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		close(app.done)
+		h.logf(LogInfo, comp.ID, "App", cmd.Process.Pid, "exited:", cmd.ProcessState, err)
+	}()
+
+	h.app = app
+	comp.pid = cmd.Process.Pid
+	h.logf(LogInfo, comp.ID, "App started, pid", comp.pid)
+	return nil
+}
+
+// StopApp stops the binary started with Config.RunAfterBuild, if any
+// It is interrupted first (SIGTERM on unix) and killed after Config.RunStopTimeout
+func (h *GoBuild) StopApp() error {
+	h.appMu.Lock()
+	app := h.app
+	h.app = nil
+	h.appMu.Unlock()
+	if app == nil {
+		return nil
+	}
+	return h.stopApp(app)
+}
+
+// stopApp interrupts app and kills it once Config.RunStopTimeout elapsed
+func (h *GoBuild) stopApp(app *appProcess) error {
+	select {
+	case <-app.done:
+		return nil
+	default:
+	}
+
+	timeout := h.config.RunStopTimeout
+	if timeout <= 0 {
+		timeout = defaultRunStopTimeout
+	}
+	if err := interruptProcess(app.cmd.Process); err != nil {
+		app.cmd.Process.Kill()
+	}
+	select {
+	case <-app.done:
+		return nil
+	case <-time.After(timeout):
+	}
+	if err := app.cmd.Process.Kill(); err != nil {
+		return errors.Join(errors.New("StopApp"), err)
+	}
+	<-app.done
+	return nil
+}
+
+// AppPID returns the pid of the running Config.RunAfterBuild binary, 0 when none is running
+func (h *GoBuild) AppPID() int {
+	h.appMu.Lock()
+	defer h.appMu.Unlock()
+	if h.app == nil {
+		return 0
+	}
+	select {
+	case <-h.app.done:
+		return 0
+	default:
+		return h.app.cmd.Process.Pid
+	}
+}
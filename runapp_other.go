@@ -0,0 +1,10 @@
+//go:build !unix
+
+package gobuild
+
+import "os"
+
+// interruptProcess kills p, windows has no signal a console-less child can catch
+func interruptProcess(p *os.Process) error {
+	return p.Kill()
+}
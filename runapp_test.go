@@ -0,0 +1,115 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAppCompiler writes an artifact script logging its arguments and its graceful stop
+const fakeAppCompiler = `prev=""
+for arg; do
+	if [ "$prev" = "-o" ]; then printf '#!/bin/sh\ntrap "echo stopped >> LOG; exit 0" TERM\necho "$@" >> LOG\nsleep 5 &\nwait\n' > "$arg"; chmod +x "$arg"; fi
+	prev="$arg"
+done`
+
+// waitLog waits for the app log to hold want
+func waitLog(t *testing.T, log, want string) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		data, _ := os.ReadFile(log)
+		if string(data) == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected app log %q, got %q", want, data)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestRunAfterBuild(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "runs")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, strings.ReplaceAll(fakeAppCompiler, "LOG", log)),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     filepath.Join(dir, "out"),
+		RunAfterBuild:             true,
+		RunArgs:                   []string{"-port", "8080"},
+		RunStopTimeout:            time.Second,
+	})
+	defer gb.StopApp()
+
+	first, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.PID == 0 || gb.AppPID() != first.PID {
+		t.Fatalf("Expected the app running, result pid %d, AppPID %d", first.PID, gb.AppPID())
+	}
+	waitLog(t, log, "-port 8080\n")
+
+	second, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.PID == 0 || second.PID == first.PID {
+		t.Errorf("Expected a new process, got pid %d after %d", second.PID, first.PID)
+	}
+	waitLog(t, log, "-port 8080\nstopped\n-port 8080\n")
+
+	if err := gb.StopApp(); err != nil {
+		t.Fatal(err)
+	}
+	if pid := gb.AppPID(); pid != 0 {
+		t.Errorf("Expected no app after StopApp, got pid %d", pid)
+	}
+	waitLog(t, log, "-port 8080\nstopped\n-port 8080\nstopped\n")
+}
+
+func TestStartAppReplacesRunningApp(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "runs")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, strings.ReplaceAll(fakeAppCompiler, "LOG", log)),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     filepath.Join(dir, "out"),
+		RunAfterBuild:             true,
+		RunStopTimeout:            time.Second,
+	})
+	defer gb.StopApp()
+
+	first, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitLog(t, log, "\n")
+
+	// a start without the promote step must not orphan the running app
+	if err := gb.startApp(&Build{}); err != nil {
+		t.Fatal(err)
+	}
+	if pid := gb.AppPID(); pid == 0 || pid == first.PID {
+		t.Errorf("Expected a new app, got pid %d after %d", pid, first.PID)
+	}
+	waitLog(t, log, "\nstopped\n\n")
+}
+
+func TestRunAfterBuildCrossTarget(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Env:                       []string{"GOOS=plan9", "GOARCH=arm"},
+		RunAfterBuild:             true,
+	})
+	if err := gb.Validate(); err == nil || !strings.Contains(err.Error(), "can't run plan9/arm") {
+		t.Errorf("Expected the cross target rejected, got %v", err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os"
+	"syscall"
+)
+
+// interruptProcess asks p to exit, giving it the chance to close its listeners
+func interruptProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGTERM)
+}
@@ -0,0 +1,47 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+)
+
+// RunRequest describes the compiler process gobuild wants to run
+type RunRequest struct {
+	Name   string    // executable, eg: "go"
+	Args   []string  // eg: build -o web/main_temp.wasm main.go
+	Dir    string    // working directory, empty for the current one
+	Env    []string  // full environment, nil inherits the current process environment
+	Output io.Writer // receives stdout and stderr combined
+}
+
+// Runner executes the compiler process, set Config.Runner to replace os/exec
+// eg: fakes that write the -o file, fail with a given output or hang until ctx is done
+// Run returns the process exit code, err reports a process that couldn't start or
+// was stopped (it must return once ctx is done: timeout, cancel, ErrorsFirst)
+// Only the compiler goes through the Runner, helper commands (go env, wasm-opt...) don't
+type Runner interface {
+	Run(ctx context.Context, req RunRequest) (exitCode int, err error)
+}
+
+// runInjected runs the compiler through Config.Runner
+func (h *GoBuild) runInjected(ctx context.Context, comp *Build, req RunRequest) ([]byte, error) {
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+
+	var output bytes.Buffer
+	out, flush := h.streamOutput(comp, downloadWatcher{out: &output, last: &comp.fetchedAt, now: h.now})
+	defer flush()
+	req.Output = h.outputWriter(out, func() {
+		comp.firstErr = true
+		stop()
+	})
+
+	code, err := h.config.Runner.Run(runCtx, req)
+	comp.exitCode = code
+	if err == nil && code != 0 {
+		err = fmt.Errorf("exit status %d", code)
+	}
+	return output.Bytes(), err
+}
@@ -0,0 +1,101 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// fakeRunner builds without a toolchain: it writes "artifact" to the -o path, or
+// prints output and exits with code, or hangs until the context is done
+type fakeRunner struct {
+	output string
+	code   int
+	hang   bool
+	got    RunRequest
+}
+
+func (f *fakeRunner) Run(ctx context.Context, req RunRequest) (int, error) {
+	f.got = req
+	fmt.Fprint(req.Output, f.output)
+	if f.hang {
+		<-ctx.Done()
+		return -1, ctx.Err()
+	}
+	if f.code != 0 {
+		return f.code, nil
+	}
+	for i, arg := range req.Args {
+		if arg == "-o" && i+1 < len(req.Args) {
+			return 0, os.WriteFile(filepath.Join(req.Dir, req.Args[i+1]), []byte("artifact"), 0644)
+		}
+	}
+	return 0, errors.New("no -o argument")
+}
+
+func newRunnerBuild(t *testing.T, runner Runner) *GoBuild {
+	dir := t.TempDir()
+	return New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Runner:                    runner,
+		Timeout:                   time.Second,
+	})
+}
+
+func TestRunnerBuild(t *testing.T) {
+	runner := &fakeRunner{}
+	gb := newRunnerBuild(t, runner)
+
+	r, err := gb.Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if runner.got.Name != "go" || runner.got.Args[0] != "build" {
+		t.Errorf("Unexpected request %+v", runner.got)
+	}
+	if data, _ := os.ReadFile(gb.FinalOutputPath()); string(data) != "artifact" || r.ExitCode != 0 {
+		t.Errorf("Expected the promoted artifact and exit code 0, got %q / %d", data, r.ExitCode)
+	}
+}
+
+func TestRunnerFailure(t *testing.T) {
+	gb := newRunnerBuild(t, &fakeRunner{output: "./main.go:3:1: undefined: x\n", code: 1})
+
+	r, err := gb.Compile()
+	if CodeOf(err) != ErrCodeCompile || r.ExitCode != 1 || r.Failure != FailureType {
+		t.Fatalf("Expected a type error with exit code 1, got %v / %+v", err, r)
+	}
+}
+
+func TestRunnerHangingCompilerCancelled(t *testing.T) {
+	gb := newRunnerBuild(t, &fakeRunner{hang: true})
+
+	b := gb.Start()
+	time.Sleep(20 * time.Millisecond)
+	gb.Cancel()
+	if err := b.Wait(); CodeOf(err) != ErrCodeCancelled {
+		t.Errorf("Expected E_CANCELLED, got %v", err)
+	}
+
+	if err := gb.CompileProgram(); CodeOf(err) != ErrCodeTimeout {
+		t.Errorf("Expected E_TIMEOUT, got %v", err)
+	}
+}
+
+func TestRunnerErrorsFirst(t *testing.T) {
+	gb := newRunnerBuild(t, &fakeRunner{output: "./main.go:3:1: undefined: x\n", hang: true})
+	gb.config.ErrorMode = ErrorsFirst
+
+	start := time.Now()
+	err := gb.CompileProgram()
+	if CodeOf(err) != ErrCodeCompile || time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Expected the runner to be stopped at the first error, got %v after %v", err, time.Since(start))
+	}
+}
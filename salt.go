@@ -0,0 +1,22 @@
+package gobuild
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// saltArgs returns the linker arguments embedding a fresh random salt in comp
+// Config.BuildSaltVar receives it through -X, otherwise it replaces the link -buildid
+func (h *GoBuild) saltArgs(comp *Build) []string {
+	if !comp.salted {
+		return nil
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+	comp.salt = hex.EncodeToString(b)
+
+	if h.config.BuildSaltVar != "" {
+		return []string{"-X", h.config.BuildSaltVar + "=" + comp.salt}
+	}
+	return []string{"-ldflags=-buildid=" + comp.salt}
+}
@@ -0,0 +1,69 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildSalt(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo \"$@\" >> "+log+"; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		CacheDir:                  filepath.Join(dir, "cache"),
+		BuildSalt:                 true,
+	})
+
+	first, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Salt == "" || first.Salt == second.Salt {
+		t.Fatalf("Expected distinct salts, got %q and %q", first.Salt, second.Salt)
+	}
+	if second.RestoredFromCache {
+		t.Error("Salted builds must not be restored from the cache")
+	}
+	data, _ := os.ReadFile(log)
+	if !strings.Contains(string(data), "-ldflags=-buildid="+first.Salt) || !strings.Contains(string(data), "-buildid="+second.Salt) {
+		t.Errorf("Expected the salts as -buildid, got %q", data)
+	}
+}
+
+func TestBuildSaltOnDemand(t *testing.T) {
+	dir := t.TempDir()
+	log := filepath.Join(dir, "args")
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo \"$@\" >> "+log+"; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		BuildSaltVar:              "main.buildSalt",
+		CompilingArguments:        func() []string { return []string{"-X", "main.version=1.0"} },
+	})
+
+	plain, err := gb.Compile()
+	if err != nil || plain.Salt != "" {
+		t.Fatalf("Expected an unsalted build, got %q (%v)", plain.Salt, err)
+	}
+	b := gb.StartWith(BuildOptions{Salt: true})
+	if err := b.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(log)
+	want := "-ldflags=-X main.version=1.0 -X main.buildSalt=" + b.Result().Salt
+	if !strings.Contains(string(data), want) {
+		t.Errorf("Expected %q, got %q", want, data)
+	}
+}
@@ -0,0 +1,16 @@
+package gobuild
+
+// Sandbox limits what the compiler process can reach, for services building
+// code submitted by third parties.
+// Linux: runs the compiler chrooted to Root inside new user/mount/ipc/uts (and network)
+// namespaces. Root is required, the mount namespace alone doesn't hide host files.
+// Windows: creates the compiler suspended and assigns it to a job object before it runs,
+// so no child escapes and all are killed with the build. Job objects limit processes
+// only: the filesystem and network stay reachable, Validate requires AllowNetwork to
+// acknowledge it and rejects Root.
+// Other systems fail Validate when a sandbox is configured.
+type Sandbox struct {
+	AllowNetwork bool   // keep the host network (linux), must be set on windows where it can't be blocked
+	Root         string // linux: required chroot directory holding the toolchain and sources
+	MaxProcesses uint32 // windows: max active processes in the job, 0 means unlimited
+}
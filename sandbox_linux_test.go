@@ -0,0 +1,57 @@
+//go:build linux
+
+package gobuild
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func TestPrepareSandboxNamespaces(t *testing.T) {
+	gb := New(&Config{Sandbox: &Sandbox{}})
+
+	cmd := exec.Command("go")
+	if err := gb.prepareSandbox(cmd); err != nil {
+		t.Fatalf("prepareSandbox failed: %v", err)
+	}
+
+	if cmd.SysProcAttr.Cloneflags&syscall.CLONE_NEWNET == 0 {
+		t.Error("Expected a new network namespace when AllowNetwork is false")
+	}
+	if cmd.SysProcAttr.Cloneflags&syscall.CLONE_NEWUSER == 0 {
+		t.Error("Expected a new user namespace")
+	}
+}
+
+func TestPrepareSandboxAllowNetwork(t *testing.T) {
+	gb := New(&Config{Sandbox: &Sandbox{AllowNetwork: true}})
+
+	cmd := exec.Command("go")
+	if err := gb.prepareSandbox(cmd); err != nil {
+		t.Fatalf("prepareSandbox failed: %v", err)
+	}
+
+	if cmd.SysProcAttr.Cloneflags&syscall.CLONE_NEWNET != 0 {
+		t.Error("Network namespace should not be created when AllowNetwork is true")
+	}
+}
+
+func TestPrepareSandboxRejectsRunAs(t *testing.T) {
+	gb := New(&Config{Sandbox: &Sandbox{}, RunAs: &Credential{Uid: 1000}})
+
+	if err := gb.prepareSandbox(exec.Command("go")); err == nil {
+		t.Error("Expected error when combining Sandbox and RunAs")
+	}
+}
+
+func TestSandboxRequiresRoot(t *testing.T) {
+	gb := New(&Config{Command: "go", MainInputFileRelativePath: "main.go", OutName: "app", Sandbox: &Sandbox{}})
+
+	var verr *ValidationError
+	if err := gb.Validate(); !errors.As(err, &verr) || !strings.Contains(err.Error(), "Root required") {
+		t.Errorf("Expected a Sandbox.Root validation issue, got %v", err)
+	}
+}
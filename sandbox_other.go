@@ -0,0 +1,26 @@
+//go:build !linux && !windows
+
+package gobuild
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// prepareSandbox reports that sandboxing is not available on this system
+func (h *GoBuild) prepareSandbox(cmd *exec.Cmd) error {
+	if h.config.Sandbox != nil {
+		return errors.New("prepareSandbox: Sandbox is only supported on linux and windows")
+	}
+	return nil
+}
+
+// sandboxIssues reports that sandboxing is not available on this system
+func (h *GoBuild) sandboxIssues(add func(field, format string, args ...any)) {
+	add("Sandbox", "only supported on linux and windows")
+}
+
+// attachSandbox is a no-op on systems without sandbox support
+func (h *GoBuild) attachSandbox(group *procGroup) (release func(), err error) {
+	return func() {}, nil
+}
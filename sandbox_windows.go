@@ -0,0 +1,127 @@
+//go:build windows
+
+package gobuild
+
+import (
+	"errors"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	kernel32                     = syscall.NewLazyDLL("kernel32.dll")
+	procCreateJobObjectW         = kernel32.NewProc("CreateJobObjectW")
+	procSetInformationJobObject  = kernel32.NewProc("SetInformationJobObject")
+	procAssignProcessToJobObject = kernel32.NewProc("AssignProcessToJobObject")
+	procTerminateJobObject       = kernel32.NewProc("TerminateJobObject")
+	procNtResumeProcess          = syscall.NewLazyDLL("ntdll.dll").NewProc("NtResumeProcess")
+)
+
+const (
+	jobObjectExtendedLimitInfoClass = 9
+	jobObjectLimitActiveProcess     = 0x00000008
+	jobObjectLimitKillOnJobClose    = 0x00002000
+	processSetQuota                 = 0x0100
+	processTerminate                = 0x0001
+	processSuspendResume            = 0x0800
+	createSuspended                 = 0x00000004
+)
+
+type ioCounters struct {
+	ReadOperationCount, WriteOperationCount, OtherOperationCount uint64
+	ReadTransferCount, WriteTransferCount, OtherTransferCount    uint64
+}
+
+type jobObjectBasicLimitInformation struct {
+	PerProcessUserTimeLimit int64
+	PerJobUserTimeLimit     int64
+	LimitFlags              uint32
+	MinimumWorkingSetSize   uintptr
+	MaximumWorkingSetSize   uintptr
+	ActiveProcessLimit      uint32
+	Affinity                uintptr
+	PriorityClass           uint32
+	SchedulingClass         uint32
+}
+
+type jobObjectExtendedLimitInformation struct {
+	BasicLimitInformation jobObjectBasicLimitInformation
+	IoInfo                ioCounters
+	ProcessMemoryLimit    uintptr
+	JobMemoryLimit        uintptr
+	PeakProcessMemoryUsed uintptr
+	PeakJobMemoryUsed     uintptr
+}
+
+// prepareSandbox creates the compiler suspended, it runs once assigned to the job
+func (h *GoBuild) prepareSandbox(cmd *exec.Cmd) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CreationFlags |= createSuspended
+	return nil
+}
+
+// sandboxIssues reports the Sandbox settings a job object can't honor
+func (h *GoBuild) sandboxIssues(add func(field, format string, args ...any)) {
+	sb := h.config.Sandbox
+	if sb.Root != "" {
+		add("Sandbox", "Root is linux only, windows job objects can't confine the filesystem")
+	}
+	if !sb.AllowNetwork {
+		add("Sandbox", "windows job objects can't block the network, set AllowNetwork to acknowledge it")
+	}
+}
+
+// attachSandbox assigns the suspended compiler to a job object before resuming it,
+// so it and every child it spawns are killed together when the build ends or is
+// cancelled (no linker keeps writing the output of a cancelled build), Config.Sandbox adds the limits
+func (h *GoBuild) attachSandbox(group *procGroup) (release func(), err error) {
+	cmd, sb := group.cmd, h.config.Sandbox
+
+	job, _, callErr := procCreateJobObjectW.Call(0, 0)
+	if job == 0 {
+		return nil, errors.Join(errors.New("attachSandbox: CreateJobObject"), callErr)
+	}
+	jobHandle := syscall.Handle(job)
+
+	info := jobObjectExtendedLimitInformation{}
+	info.BasicLimitInformation.LimitFlags = jobObjectLimitKillOnJobClose
+	if sb != nil && sb.MaxProcesses > 0 {
+		info.BasicLimitInformation.LimitFlags |= jobObjectLimitActiveProcess
+		info.BasicLimitInformation.ActiveProcessLimit = sb.MaxProcesses
+	}
+
+	ok, _, callErr := procSetInformationJobObject.Call(job, jobObjectExtendedLimitInfoClass,
+		uintptr(unsafe.Pointer(&info)), unsafe.Sizeof(info))
+	if ok == 0 {
+		syscall.CloseHandle(jobHandle)
+		return nil, errors.Join(errors.New("attachSandbox: SetInformationJobObject"), callErr)
+	}
+
+	process, err := syscall.OpenProcess(processSetQuota|processTerminate|processSuspendResume, false, uint32(cmd.Process.Pid))
+	if err != nil {
+		syscall.CloseHandle(jobHandle)
+		return nil, errors.Join(errors.New("attachSandbox: OpenProcess"), err)
+	}
+	defer syscall.CloseHandle(process)
+
+	ok, _, callErr = procAssignProcessToJobObject.Call(job, uintptr(process))
+	if ok == 0 {
+		syscall.CloseHandle(jobHandle)
+		return nil, errors.Join(errors.New("attachSandbox: AssignProcessToJobObject"), callErr)
+	}
+
+	group.setJob(jobHandle)
+	if status, _, _ := procNtResumeProcess.Call(uintptr(process)); status != 0 {
+		group.setJob(0)
+		syscall.CloseHandle(jobHandle)
+		return nil, errors.New("attachSandbox: NtResumeProcess failed")
+	}
+
+	return func() {
+		group.setJob(0)
+		syscall.CloseHandle(jobHandle)
+	}, nil
+}
@@ -0,0 +1,179 @@
+package gobuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SecretSource provides secret values at build time
+// eg: a vault/keychain client or environment lookup kept out of config files
+type SecretSource interface {
+	Get(key string) (string, error)
+}
+
+// SecretFunc adapts a function to the SecretSource interface
+// eg: gobuild.SecretFunc(func(k string) (string, error) { return vault.Read(k) })
+type SecretFunc func(key string) (string, error)
+
+func (f SecretFunc) Get(key string) (string, error) { return f(key) }
+
+// secretFileName is the generated file added to each stamped package
+const secretFileName = "zz_gobuild_secrets.go"
+
+// redactedSecret replaces what would reveal a secret value, eg: the DryRun -overlay path
+const redactedSecret = "***"
+
+// applySecretStamps resolves Config.SecretStamps into one generated file per package,
+// assigning the values in an init function, and adds it to the build with -overlay.
+// Unlike -X, which go hands over to the link subprocess on its command line, the values
+// never reach an argv or the env: they only live in a private temp folder removed by cleanup
+// Package-level initializers reading a stamped variable see its zero value, init runs after them
+// Returns the build arguments, a hash of the generated files for the cache key and cleanup
+func (h *GoBuild) applySecretStamps(ctx context.Context, buildArgs, env []string) (args []string, digest string, cleanup func(), err error) {
+	cleanup = func() {}
+	if len(h.config.SecretStamps) == 0 {
+		return buildArgs, "", cleanup, nil
+	}
+	files, err := h.secretFiles(ctx, env)
+	if err != nil {
+		return nil, "", cleanup, err
+	}
+
+	dir, err := os.MkdirTemp("", "gobuild_secrets")
+	if err != nil {
+		return nil, "", cleanup, errors.Join(errors.New("applySecretStamps"), err)
+	}
+	cleanup = func() { os.RemoveAll(dir) }
+
+	sum := sha256.New()
+	overlay := struct{ Replace map[string]string }{Replace: map[string]string{}}
+	targets := make([]string, 0, len(files))
+	for target := range files {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+	for i, target := range targets {
+		src := filepath.Join(dir, strconv.Itoa(i)+".go")
+		if err := os.WriteFile(src, files[target], 0600); err != nil {
+			cleanup()
+			return nil, "", func() {}, errors.Join(errors.New("applySecretStamps"), err)
+		}
+		overlay.Replace[target] = src
+		sum.Write(files[target])
+	}
+
+	data, _ := json.Marshal(overlay)
+	overlayPath := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlayPath, data, 0600); err != nil {
+		cleanup()
+		return nil, "", func() {}, errors.Join(errors.New("applySecretStamps"), err)
+	}
+
+	return h.withSecretOverlay(buildArgs, overlayPath, files), hex.EncodeToString(sum.Sum(nil)), cleanup, nil
+}
+
+// withSecretOverlay adds -overlay=overlayPath after the subcommand, eg: build -overlay=... -o app main.go
+// A main given as files only compiles those, the generated one is named next to it
+func (h *GoBuild) withSecretOverlay(buildArgs []string, overlayPath string, files map[string][]byte) []string {
+	args := append([]string{buildArgs[0], "-overlay=" + overlayPath}, buildArgs[1:]...)
+	if mainArg := args[len(args)-1]; strings.HasSuffix(mainArg, ".go") && files[h.mainSecretFile()] != nil {
+		args = append(args, filepath.Join(filepath.Dir(mainArg), secretFileName))
+	}
+	return args
+}
+
+// secretFiles returns the generated source of each stamped package by its overlay path
+// eg: /src/app/zz_gobuild_secrets.go => package main; func init() { apiKey = "..." }
+func (h *GoBuild) secretFiles(ctx context.Context, env []string) (map[string][]byte, error) {
+	if h.config.Secrets == nil {
+		return nil, errors.New("applySecretStamps: SecretStamps configured without a Secrets source")
+	}
+
+	// sorted so the same secrets always produce the same files (and cache key)
+	variables := make([]string, 0, len(h.config.SecretStamps))
+	for v := range h.config.SecretStamps {
+		variables = append(variables, v)
+	}
+	sort.Strings(variables)
+
+	type pkgSource struct {
+		name    string
+		assigns []string
+	}
+	packages := map[string]*pkgSource{} // by package folder
+	for _, variable := range variables {
+		pkgPath, name, ok := cutLast(variable, ".")
+		if !ok || pkgPath == "" || !token.IsIdentifier(name) {
+			return nil, fmt.Errorf("applySecretStamps %s: expected importpath.name, eg: main.apiKey", variable)
+		}
+		value, err := h.config.Secrets.Get(h.config.SecretStamps[variable])
+		if err != nil {
+			return nil, fmt.Errorf("applySecretStamps %s: %w", variable, err)
+		}
+
+		dir, pkgName, err := h.packageDir(ctx, pkgPath, env)
+		if err != nil {
+			return nil, fmt.Errorf("applySecretStamps %s: %w", variable, err)
+		}
+		src := packages[dir]
+		if src == nil {
+			src = &pkgSource{name: pkgName}
+			packages[dir] = src
+		}
+		src.assigns = append(src.assigns, "\t"+name+" = "+strconv.Quote(value)+"\n")
+	}
+
+	files := make(map[string][]byte, len(packages))
+	for dir, src := range packages {
+		files[filepath.Join(dir, secretFileName)] = []byte("// Code generated by gobuild for Config.SecretStamps. DO NOT EDIT.\n\n" +
+			"package " + src.name + "\n\nfunc init() {\n" + strings.Join(src.assigns, "") + "}\n")
+	}
+	return files, nil
+}
+
+// mainSecretFile returns the overlay path of the generated file in the main package
+func (h *GoBuild) mainSecretFile() string {
+	dir, _, _ := h.packageDir(context.Background(), "main", nil)
+	return filepath.Join(dir, secretFileName)
+}
+
+// packageDir returns the absolute folder and name of the package stamped as pkgPath
+// "main" is the package of MainInputFileRelativePath, other paths are looked up with go list
+func (h *GoBuild) packageDir(ctx context.Context, pkgPath string, env []string) (dir, name string, err error) {
+	if pkgPath == "main" {
+		dir, err = filepath.Abs(filepath.Dir(h.resolve(h.config.MainInputFileRelativePath)))
+		return dir, "main", err
+	}
+
+	cmd := exec.CommandContext(ctx, h.goTool(), "list", "-f", "{{.Dir}}\n{{.Name}}", pkgPath)
+	cmd.Dir = h.config.WorkDir
+	cmd.Env = h.environment(env)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", "", fmt.Errorf("go list %s: %v", pkgPath, err)
+	}
+	dir, name, _ = strings.Cut(strings.TrimSpace(string(output)), "\n")
+	if dir == "" || name == "" {
+		return "", "", fmt.Errorf("go list %s: package not found", pkgPath)
+	}
+	return dir, name, nil
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
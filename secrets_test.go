@@ -0,0 +1,118 @@
+package gobuild
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApplySecretStamps(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		MainInputFileRelativePath: filepath.Join(dir, "main.go"),
+		Secrets:                   SecretFunc(func(key string) (string, error) { return "s3cr3t \"" + key, nil }),
+		SecretStamps:              map[string]string{"main.apiKey": "API_KEY"},
+	})
+
+	buildArgs := []string{"build", "-v", "-ldflags=-X main.version=1", "-o", "app", "main.go"}
+	args, digest, cleanup, err := gb.applySecretStamps(context.Background(), buildArgs, []string{"GOOS=linux"})
+	if err != nil {
+		t.Fatalf("applySecretStamps failed: %v", err)
+	}
+	defer cleanup()
+
+	overlayPath, ok := strings.CutPrefix(args[1], "-overlay=")
+	if !ok || !slices.Equal(args[2:len(args)-1], buildArgs[1:]) || args[len(args)-1] != secretFileName || digest == "" {
+		t.Fatalf("Expected the overlay after the subcommand, the user flags untouched and the generated file named, got %q", args)
+	}
+	for _, a := range args {
+		if strings.Contains(a, "s3cr3t") {
+			t.Errorf("Secrets must not be in argv, found %q", a)
+		}
+	}
+
+	data, err := os.ReadFile(overlayPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var overlay struct{ Replace map[string]string }
+	if err := json.Unmarshal(data, &overlay); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(overlay.Replace[filepath.Join(dir, secretFileName)])
+	if err != nil {
+		t.Fatalf("Expected a generated file in the main package folder: %v (%v)", err, overlay.Replace)
+	}
+	if want := `apiKey = "s3cr3t \"API_KEY"`; !strings.Contains(string(src), want) || !strings.Contains(string(src), "package main") {
+		t.Errorf("Expected %s in\n%s", want, src)
+	}
+
+	cleanup()
+	if _, err := os.Stat(overlayPath); !os.IsNotExist(err) {
+		t.Error("Expected cleanup to remove the generated files")
+	}
+}
+
+func TestApplySecretStampsErrors(t *testing.T) {
+	gb := New(&Config{SecretStamps: map[string]string{"main.apiKey": "API_KEY"}})
+	if _, _, _, err := gb.applySecretStamps(context.Background(), []string{"build"}, nil); err == nil {
+		t.Error("Expected error without a Secrets source")
+	}
+
+	gb.config.Secrets = SecretFunc(func(string) (string, error) { return "", errors.New("vault sealed") })
+	if _, _, _, err := gb.applySecretStamps(context.Background(), []string{"build"}, nil); err == nil {
+		t.Error("Expected the secret source error")
+	}
+
+	gb.config.Secrets = SecretFunc(func(string) (string, error) { return "x", nil })
+	gb.config.SecretStamps = map[string]string{"apiKey": "API_KEY"}
+	if _, _, _, err := gb.applySecretStamps(context.Background(), []string{"build"}, nil); err == nil {
+		t.Error("Expected an error for a variable without package path")
+	}
+}
+
+func TestSecretStampsCompile(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles with the real toolchain")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	tempDir := t.TempDir()
+	mainGoPath := filepath.Join(tempDir, "main.go")
+	os.WriteFile(mainGoPath, []byte("package main\n\nvar apiKey, version string\n\nfunc main() { println(apiKey, version) }\n"), 0644)
+
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: mainGoPath,
+		OutName:                   "app",
+		Extension:                 getExecutableExtension(),
+		OutFolderRelativePath:     tempDir,
+		CompilingArguments:        func() []string { return []string{"-x", "-X", "main.version=v1"} },
+		Secrets:                   SecretFunc(func(string) (string, error) { return "top secret", nil }),
+		SecretStamps:              map[string]string{"main.apiKey": "API_KEY"},
+		Timeout:                   60 * time.Second,
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Compilation failed: %v", err)
+	}
+	// -x prints every compile and link command line
+	if out := b.Result().Output; !strings.Contains(out, "link") || strings.Contains(out, "top secret") {
+		t.Errorf("The secret must not appear in any command line:\n%s", out)
+	}
+
+	out, err := exec.Command(gb.FinalOutputPath()).CombinedOutput()
+	if err != nil || string(out) != "top secret v1\n" {
+		t.Errorf("Expected 'top secret v1', got %q (%v)", out, err)
+	}
+}
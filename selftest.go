@@ -0,0 +1,148 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// The program compiled by SelfTest, its cgo file only builds when cgo is enabled
+// (CGO_ENABLED=1, or the go default with a C compiler on the PATH)
+const (
+	selfTestMod    = "module gobuild.selftest\n"
+	selfTestMain   = "package main\n\nfunc main() { println(\"" + selfTestOutput + "\" + cgoCheck()) }\n"
+	selfTestNoCgo  = "//go:build !cgo\n\npackage main\n\nfunc cgoCheck() string { return \"\" }\n"
+	selfTestCgo    = "//go:build cgo\n\npackage main\n\n// static int answer(void) { return 42; }\nimport \"C\"\n\nfunc cgoCheck() string {\n\tif C.answer() != 42 {\n\t\treturn \" cgo broken\"\n\t}\n\treturn \" cgo\"\n}\n"
+	selfTestOutput = "gobuild selftest ok"
+)
+
+// selfTestMinTimeout leaves room for a cold build cache
+const selfTestMinTimeout = time.Minute
+
+// SelfTest compiles a tiny hello-world program in a temp dir with the toolchain, env,
+// cgo setup and target of this GoBuild, then runs it when it targets this machine
+// It backs "doctor" commands of dev tools: the error names the failing step
+// (toolchain, cache, compile or run) and wraps its cause
+func (h *GoBuild) SelfTest(ctx context.Context) error {
+	name, _ := h.commandLine(nil)
+	if h.config.Agent == nil && h.config.Runner == nil {
+		if _, err := exec.LookPath(name); err != nil {
+			return fmt.Errorf("SelfTest toolchain: %w", err)
+		}
+		if !h.tinyGo() {
+			if err := h.checkGoCache(ctx); err != nil {
+				return fmt.Errorf("SelfTest cache: %w", err)
+			}
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "gobuild-selftest-")
+	if err != nil {
+		return fmt.Errorf("SelfTest: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{"go.mod": selfTestMod, "main.go": selfTestMain, "nocgo.go": selfTestNoCgo, "cgo.go": selfTestCgo}
+	for file, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			return fmt.Errorf("SelfTest: %w", err)
+		}
+	}
+
+	gb := h.sideBuilder(h.selfTestConfig(dir))
+
+	b := gb.Start()
+	stop := context.AfterFunc(ctx, func() { gb.Cancel() })
+	defer stop()
+	if err := b.Wait(); err != nil {
+		return fmt.Errorf("SelfTest compile: %w", err)
+	}
+
+	goos, goarch := gb.target()
+	if h.config.Agent != nil || h.config.Runner != nil || goos != runtime.GOOS || goarch != runtime.GOARCH {
+		return nil // cross builds and injected runners stop at the compile
+	}
+	output, err := exec.CommandContext(ctx, gb.FinalOutputPath()).CombinedOutput()
+	got := strings.TrimSpace(string(output))
+	want := selfTestOutput + " cgo"
+	if !h.cgoEnabled() && got == selfTestOutput {
+		want = selfTestOutput
+	}
+	if err != nil || got != want {
+		return fmt.Errorf("SelfTest run: expected %q, got %q (%v)", want, got, err)
+	}
+	return nil
+}
+
+// selfTestConfig copies the toolchain and environment settings of h into a
+// config building the SelfTest program of dir
+func (h *GoBuild) selfTestConfig(dir string) *Config {
+	c := h.config
+	timeout := c.Timeout
+	if timeout < selfTestMinTimeout {
+		timeout = selfTestMinTimeout
+	}
+	return &Config{
+		Command:                   c.Command,
+		CommandLine:               c.CommandLine,
+		UseShell:                  c.UseShell,
+		MainInputFileRelativePath: ".",
+		OutName:                   "selftest",
+		Extension:                 c.Extension,
+		OutFolderRelativePath:     "out",
+		WorkDir:                   dir,
+		Timeout:                   timeout,
+		Env:                       c.Env,
+		EnvFiles:                  c.EnvFiles,
+		IsolateEnv:                c.IsolateEnv,
+		EnvAllowlist:              c.EnvAllowlist,
+		OutputDecoder:             c.OutputDecoder,
+		Agent:                     c.Agent,
+		Runner:                    c.Runner,
+		Experiments:               c.Experiments,
+		GoDebug:                   c.GoDebug,
+		TinyGoWasm:                c.TinyGoWasm,
+		CgoToolchains:             c.CgoToolchains,
+		ZigCC:                     c.ZigCC,
+		Device:                    c.Device,
+		TargetWASM:                c.TargetWASM,
+		Clock:                     c.Clock,
+		RunAs:                     c.RunAs,
+		Sandbox:                   c.Sandbox,
+		LogSinks:                  c.LogSinks,
+	}
+}
+
+// checkGoCache verifies GOCACHE is enabled and writable
+func (h *GoBuild) checkGoCache(ctx context.Context) error {
+	dir, err := h.goCacheDir(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, "gobuild-selftest-")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// cgoEnabled reports whether the build env sets CGO_ENABLED=1
+func (h *GoBuild) cgoEnabled() bool {
+	enabled := h.getenv("CGO_ENABLED") == "1"
+	env, _ := h.userEnv()
+	for _, e := range env {
+		if v, ok := strings.CutPrefix(e, "CGO_ENABLED="); ok {
+			enabled = v == "1"
+		}
+	}
+	return enabled
+}
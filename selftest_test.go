@@ -0,0 +1,45 @@
+package gobuild
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestSelfTestRealToolchain(t *testing.T) {
+	if testing.Short() {
+		t.Skip("compiles with the real toolchain")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     t.TempDir(),
+	})
+	if err := gb.SelfTest(context.Background()); err != nil {
+		t.Fatalf("SelfTest failed: %v", err)
+	}
+}
+
+func TestSelfTestReportsStep(t *testing.T) {
+	missing := New(&Config{
+		Command:                   "gobuild-missing-compiler",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     t.TempDir(),
+	})
+	if err := missing.SelfTest(context.Background()); err == nil || !strings.HasPrefix(err.Error(), "SelfTest toolchain:") {
+		t.Errorf("Expected a toolchain error, got %v", err)
+	}
+
+	broken := newRunnerBuild(t, &fakeRunner{output: "cannot find runtime/cgo\n", code: 2})
+	err := broken.SelfTest(context.Background())
+	if err == nil || !strings.HasPrefix(err.Error(), "SelfTest compile:") || !strings.Contains(err.Error(), "runtime/cgo") {
+		t.Errorf("Expected a compile error with the compiler output, got %v", err)
+	}
+}
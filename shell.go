@@ -0,0 +1,67 @@
+package gobuild
+
+import (
+	"runtime"
+	"strings"
+)
+
+// shellCommand renders name and args into a single quoted invocation run through
+// a login shell, so version managers (asdf, mise...) set up in profiles apply
+// unix: /bin/sh -lc '<command>', $SHELL is ignored since fish, nushell... don't
+// speak POSIX quoting. windows: cmd /S /C "<command>", passed verbatim by setShellCmdLine
+func shellCommand(name string, args []string) (string, []string) {
+	parts := append([]string{name}, args...)
+
+	if runtime.GOOS == "windows" {
+		quoted := make([]string, len(parts))
+		for i, p := range parts {
+			quoted[i] = cmdQuote(p)
+		}
+		return "cmd", []string{"/S", "/C", `"` + strings.Join(quoted, " ") + `"`}
+	}
+
+	quoted := make([]string, len(parts))
+	for i, p := range parts {
+		quoted[i] = shQuote(p)
+	}
+	return "/bin/sh", []string{"-lc", strings.Join(quoted, " ")}
+}
+
+// shQuote quotes s for POSIX shells, eg: it's => 'it'\”s'
+func shQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\n'\"\\$`&|;<>()*?[]#~=%!{}") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// cmdQuote quotes s for cmd.exe and the program parsing its command line:
+// quotes are doubled, % is escaped as ^% outside the quotes so cmd doesn't
+// expand it and backslashes before a quote are doubled
+// eg: my app => "my app", say "hi" => "say ""hi""", 50% => "50"^%""
+func cmdQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"&|<>^()%!") {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	slashes := 0
+	for _, r := range s {
+		switch r {
+		case '\\':
+			slashes++
+			b.WriteRune(r)
+			continue
+		case '"':
+			b.WriteString(strings.Repeat(`\`, slashes) + `""`)
+		case '%':
+			b.WriteString(strings.Repeat(`\`, slashes) + `"^%"`)
+		default:
+			b.WriteRune(r)
+		}
+		slashes = 0
+	}
+	b.WriteString(strings.Repeat(`\`, slashes) + `"`)
+	return b.String()
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package gobuild
+
+import "os/exec"
+
+// setShellCmdLine has nothing to do, unix shells receive their arguments as is
+func setShellCmdLine(cmd *exec.Cmd) {}
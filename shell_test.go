@@ -0,0 +1,63 @@
+package gobuild
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestShQuote(t *testing.T) {
+	tests := []struct{ in, expected string }{
+		{"build", "build"},
+		{"-o", "-o"},
+		{"out dir/app", "'out dir/app'"},
+		{"it's", `'it'\''s'`},
+		{"-ldflags=-X 'main.name=My App'", `'-ldflags=-X '\''main.name=My App'\'''`},
+		{"", "''"},
+	}
+	for _, tt := range tests {
+		if got := shQuote(tt.in); got != tt.expected {
+			t.Errorf("shQuote(%q): expected %s, got %s", tt.in, tt.expected, got)
+		}
+	}
+}
+
+func TestCmdQuote(t *testing.T) {
+	tests := []struct{ in, expected string }{
+		{"build", "build"},
+		{`C:\my app\main.go`, `"C:\my app\main.go"`},
+		{`say "hi"`, `"say ""hi"""`},
+		{"50% off", `"50"^%" off"`},
+		{`C:\my dir\`, `"C:\my dir\\"`},
+		{`a\"b`, `"a\\""b"`},
+	}
+	for _, tt := range tests {
+		if got := cmdQuote(tt.in); got != tt.expected {
+			t.Errorf("cmdQuote(%q): expected %s, got %s", tt.in, tt.expected, got)
+		}
+	}
+}
+
+func TestUseShellCompiles(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a unix shell")
+	}
+	t.Setenv("SHELL", "/usr/bin/fish")
+
+	tempDir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "my app",
+		OutFolderRelativePath:     tempDir,
+		UseShell:                  true,
+	})
+
+	name, args := gb.commandLine([]string{"build"})
+	if name != "/bin/sh" || len(args) != 2 || args[0] != "-lc" {
+		t.Fatalf("Unexpected shell invocation: %s %v", name, args)
+	}
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Errorf("Compilation through the shell failed: %v", err)
+	}
+}
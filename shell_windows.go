@@ -0,0 +1,18 @@
+//go:build windows
+
+package gobuild
+
+import (
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// setShellCmdLine passes the cmd /S /C invocation of shellCommand verbatim, the
+// default exec.Cmd escaping follows the msvcrt rules cmd.exe doesn't understand
+func setShellCmdLine(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.CmdLine = strings.Join(cmd.Args, " ")
+}
@@ -0,0 +1,51 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSizeDelta(t *testing.T) {
+	dir := t.TempDir()
+	payload := filepath.Join(dir, "payload")
+	var messages []string
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, `prev=""; for arg; do if [ "$prev" = "-o" ]; then cp `+payload+` "$arg"; fi; prev="$arg"; done`),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		LogSinks:                  []LogSink{{Level: LogInfo, Func: func(e LogEntry) { messages = append(messages, e.Message) }}},
+	})
+
+	os.WriteFile(payload, make([]byte, 2048), 0644)
+	first, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.SizeDelta != 0 {
+		t.Errorf("Expected no delta for the first build, got %d", first.SizeDelta)
+	}
+
+	os.WriteFile(payload, make([]byte, 2048+12698), 0644)
+	second, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Size != 14746 || second.SizeDelta != 12698 {
+		t.Errorf("Expected 14746 bytes (+12698), got %d (%+d)", second.Size, second.SizeDelta)
+	}
+	if last := messages[len(messages)-1]; !strings.HasSuffix(last, "(14.4 KiB, +12.4 KiB)") {
+		t.Errorf("Expected the size and delta in the log, got %q", last)
+	}
+
+	os.WriteFile(payload, make([]byte, 100), 0644)
+	third, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if third.SizeDelta != 100-14746 {
+		t.Errorf("Expected a negative delta, got %d", third.SizeDelta)
+	}
+}
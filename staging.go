@@ -0,0 +1,107 @@
+package gobuild
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ElevateFunc publishes a staged artifact into a read-only output folder with the
+// rights the build process lacks, eg: exec.Command("sudo", "install", "-m755", staged, final).Run()
+type ElevateFunc func(staged, final string) error
+
+// writableDir reports whether files can be created in dir, or in its nearest
+// existing parent when dir doesn't exist yet. Replaced in tests
+var writableDir = func(dir string) bool {
+	for {
+		info, err := os.Stat(fixLongPath(dir))
+		if err == nil {
+			// a file is reported by Validate as not a folder
+			return !info.IsDir() || dirWritable(dir)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return true
+		}
+		dir = parent
+	}
+}
+
+// stagingIssues checks a read-only output folder, detected on every Validate
+// With StagingDir and Elevate the build is redirected, otherwise it is rejected
+// before compiling instead of failing at the rename
+func (h *GoBuild) stagingIssues(add func(field, format string, args ...any)) {
+	c := h.config
+	outDir := h.resolve(h.outFolder())
+	staging := false
+	if !writableDir(outDir) {
+		switch {
+		case c.StagingDir == "" || c.Elevate == nil:
+			add("OutFolderRelativePath", "%q is read-only, set StagingDir and Elevate to publish through a privileged copy", outDir)
+		case !writableDir(h.resolve(c.StagingDir)):
+			add("StagingDir", "%q is read-only", h.resolve(c.StagingDir))
+		default:
+			staging = true
+		}
+	}
+
+	if staging {
+		// sidecars are written next to the artifact, only the artifact is elevated
+		for _, s := range []struct {
+			field string
+			on    bool
+		}{
+			{"Manifest", c.Manifest},
+			{"FinalNameFunc", c.FinalNameFunc != nil},
+			{"CopyWasmExec", c.CopyWasmExec},
+			{"TinyGoWasm", c.TinyGoWasm != nil && c.TinyGoWasm.Gzip},
+			{"Compress", c.Compress != nil},
+			{"OutputFS", c.OutputFS != nil},
+		} {
+			if s.on {
+				add(s.field, "writes next to the artifact, %q is read-only", outDir)
+			}
+		}
+	}
+
+	h.mu.Lock()
+	h.staging = staging
+	h.mu.Unlock()
+}
+
+// isStaging reports whether the last Validate redirected builds to Config.StagingDir
+func (h *GoBuild) isStaging() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.staging
+}
+
+// tempFolder returns the folder the compiler writes into, relative to the working directory
+func (h *GoBuild) tempFolder() string {
+	if h.isStaging() {
+		return filepath.FromSlash(h.config.StagingDir)
+	}
+	return h.outFolder()
+}
+
+// tempPath returns the OS path of a temp file, see tempFolder
+func (h *GoBuild) tempPath(fileName string) string {
+	return h.resolve(filepath.Join(h.tempFolder(), fileName))
+}
+
+// publishStaged hands the staged temp file to Config.Elevate and removes it
+func (h *GoBuild) publishStaged(tempFileName string) error {
+	stagedPath := h.tempPath(tempFileName)
+	finalPath := h.FinalOutputPath()
+	err := h.config.Elevate(stagedPath, finalPath)
+	h.cleanupTempFile(tempFileName)
+	if err != nil {
+		h.logf(LogError, 0, "Elevate failed:", fmt.Sprintf("%q -> %q:", stagedPath, finalPath), err)
+		return &BuildError{
+			Code: ErrCodeRenameLocked,
+			Err:  errors.Join(fmt.Errorf("elevate %q -> %q", stagedPath, finalPath), err),
+		}
+	}
+	return nil
+}
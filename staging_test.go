@@ -0,0 +1,96 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readOnlyDirs makes writableDir report the given folders as read-only
+func readOnlyDirs(t *testing.T, dirs ...string) {
+	orig := writableDir
+	writableDir = func(dir string) bool {
+		for _, d := range dirs {
+			if dir == d {
+				return false
+			}
+		}
+		return orig(dir)
+	}
+	t.Cleanup(func() { writableDir = orig })
+}
+
+func TestReadOnlyOutFolderRejected(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "deploy")
+	readOnlyDirs(t, out)
+
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     out,
+	})
+
+	_, err := gb.Compile()
+	if CodeOf(err) != ErrCodeValidation || !strings.Contains(err.Error(), "is read-only") {
+		t.Fatalf("Expected a read-only validation error, got %v", err)
+	}
+}
+
+func TestReadOnlyOutFolderStaging(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "deploy")
+	staging := filepath.Join(dir, "staging")
+	if err := os.Mkdir(out, 0755); err != nil {
+		t.Fatal(err)
+	}
+	readOnlyDirs(t, out)
+
+	var staged string
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     out,
+		StagingDir:                staging,
+		Elevate: func(src, dst string) error {
+			staged = src
+			data, err := os.ReadFile(src)
+			if err != nil {
+				return err
+			}
+			return os.WriteFile(dst, data, 0755)
+		},
+	})
+
+	result, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(staged) != staging {
+		t.Errorf("Expected the compiler to write into %q, got %q", staging, staged)
+	}
+	if result.OutputPath != filepath.Join(out, "app") {
+		t.Errorf("Unexpected OutputPath %q", result.OutputPath)
+	}
+	if _, err := os.Stat(result.OutputPath); err != nil {
+		t.Error("Expected the elevated artifact:", err)
+	}
+	if entries, _ := os.ReadDir(staging); len(entries) != 0 {
+		t.Errorf("Expected the staged file removed, found %d entries", len(entries))
+	}
+
+	gb.config.Elevate = func(string, string) error { return errors.New("sudo: a password is required") }
+	if _, err := gb.Compile(); CodeOf(err) != ErrCodeRenameLocked {
+		t.Errorf("Expected %s from a failed Elevate, got %v", ErrCodeRenameLocked, err)
+	}
+
+	gb.config.Elevate = nil
+	gb.config.StagingDir = ""
+	if err := gb.Validate(); err == nil {
+		t.Error("Expected the read-only folder rejected without StagingDir")
+	}
+}
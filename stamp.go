@@ -0,0 +1,45 @@
+package gobuild
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// StampFromStruct returns the -X flags setting, for every exported string field of v,
+// the variable pkgPath.<Field> to its value, ready for CompilingArguments
+// The `stamp:"name"` tag renames the variable, `stamp:"-"` skips the field, empty values are skipped
+// eg: StampFromStruct("main", struct{ Version string `stamp:"version"` }{"v1.2.0"}) => -X main.version=v1.2.0
+func StampFromStruct(pkgPath string, v any) ([]string, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("StampFromStruct: expected a struct, got %T", v)
+	}
+	if pkgPath == "" {
+		return nil, fmt.Errorf("StampFromStruct: empty package path")
+	}
+
+	var args []string
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if !field.IsExported() || field.Type.Kind() != reflect.String {
+			continue
+		}
+		name := field.Name
+		if tag, ok := field.Tag.Lookup("stamp"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		if value := rv.Field(i).String(); value != "" {
+			args = append(args, "-X", pkgPath+"."+name+"="+value)
+		}
+	}
+	return args, nil
+}
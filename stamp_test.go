@@ -0,0 +1,39 @@
+package gobuild
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStampFromStruct(t *testing.T) {
+	type release struct {
+		Version string `stamp:"version"`
+		Channel string
+		Notes   string `stamp:"-"`
+		Empty   string
+		Build   int
+		secret  string
+	}
+
+	got, err := StampFromStruct("example.com/app/internal/buildinfo", &release{
+		Version: "v1.2.0",
+		Channel: "stable beta",
+		Notes:   "skipped",
+		Build:   7,
+		secret:  "hidden",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"-X", "example.com/app/internal/buildinfo.version=v1.2.0",
+		"-X", "example.com/app/internal/buildinfo.Channel=stable beta",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if _, err := StampFromStruct("main", "v1.2.0"); err == nil {
+		t.Error("Expected an error for a non-struct value")
+	}
+}
@@ -0,0 +1,46 @@
+package gobuild
+
+import "time"
+
+// BuildState is the Status of a GoBuild
+type BuildState string
+
+const (
+	StateIdle       BuildState = "idle"       // no build running
+	StateCompiling  BuildState = "compiling"  // ActiveID is running
+	StateCancelling BuildState = "cancelling" // Cancel was called, the build is still shutting down
+)
+
+// Status is a snapshot of a GoBuild, eg: for status bars
+type Status struct {
+	State      BuildState
+	ActiveID   uint64        // running (or cancelling) build, 0 when idle
+	Label      string        // label of ActiveID
+	Elapsed    time.Duration // since ActiveID started
+	Queued     int           // builds waiting to start, see PendingBuilds
+	LastResult *BuildResult  // last build that ran to the end (not dropped), nil before the first one
+	LastError  error         // LastResult.Err
+}
+
+// Status returns the current state, the active build and the last result
+func (h *GoBuild) Status() Status {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	s := Status{State: StateIdle, Queued: len(h.queue), LastResult: h.lastResult}
+	if h.lastResult != nil {
+		s.LastError = h.lastResult.Err
+	}
+	comp := h.active
+	if comp != nil {
+		s.State = StateCompiling
+	} else if comp = h.cancelling; comp != nil {
+		s.State = StateCancelling
+	}
+	if comp != nil {
+		s.ActiveID = comp.ID
+		s.Label = comp.label
+		s.Elapsed = h.now().Sub(comp.startTime)
+	}
+	return s
+}
@@ -0,0 +1,49 @@
+package gobuild
+
+import (
+	"context"
+	"testing"
+)
+
+// stubbornRunner keeps running after its context is done until release is closed
+type stubbornRunner struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *stubbornRunner) Run(ctx context.Context, req RunRequest) (int, error) {
+	close(r.started)
+	<-ctx.Done()
+	<-r.release
+	return -1, ctx.Err()
+}
+
+func TestStatus(t *testing.T) {
+	runner := &stubbornRunner{started: make(chan struct{}), release: make(chan struct{})}
+	gb := newRunnerBuild(t, runner)
+
+	if s := gb.Status(); s.State != StateIdle || s.ActiveID != 0 || s.LastResult != nil {
+		t.Fatalf("Expected idle before the first build, got %+v", s)
+	}
+
+	b := gb.Start()
+	<-runner.started
+	if s := gb.Status(); s.State != StateCompiling || s.ActiveID != b.ID {
+		t.Errorf("Expected build %d compiling, got %+v", b.ID, s)
+	}
+
+	gb.Cancel()
+	if s := gb.Status(); s.State != StateCancelling || s.ActiveID != b.ID {
+		t.Errorf("Expected build %d cancelling, got %+v", b.ID, s)
+	}
+
+	close(runner.release)
+	b.Wait()
+	s := gb.Status()
+	if s.State != StateIdle || s.ActiveID != 0 {
+		t.Errorf("Expected idle after the build ended, got %+v", s)
+	}
+	if s.LastResult == nil || s.LastResult.ID != b.ID || CodeOf(s.LastError) != ErrCodeCancelled {
+		t.Errorf("Expected the cancelled build as last result, got %+v", s)
+	}
+}
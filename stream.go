@@ -0,0 +1,75 @@
+package gobuild
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// lineStreamer passes the compiler output through to out and hands every complete
+// line to emit as soon as it is written, flush emits a trailing line without newline
+type lineStreamer struct {
+	out  io.Writer
+	emit func(line []byte)
+
+	mu      sync.Mutex
+	pending []byte
+}
+
+func (w *lineStreamer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.emit(bytes.TrimSuffix(w.pending[:i], []byte("\r")))
+		w.pending = w.pending[i+1:]
+	}
+	w.mu.Unlock()
+	return w.out.Write(p)
+}
+
+func (w *lineStreamer) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		w.emit(w.pending)
+		w.pending = nil
+	}
+}
+
+// streaming reports whether the compiler output is sent live, see Config.StreamOutput
+func (h *GoBuild) streaming(comp *Build) bool {
+	return (h.config.StreamOutput || h.config.OutputWriter != nil) && !comp.discard
+}
+
+// streamOutput wraps out so each output line of comp reaches Config.OutputWriter and,
+// with StreamOutput, the Logger while the compiler runs. Call flush once it exited
+func (h *GoBuild) streamOutput(comp *Build, out io.Writer) (w io.Writer, flush func()) {
+	if !h.streaming(comp) {
+		return out, func() {}
+	}
+	s := &lineStreamer{out: out, emit: func(line []byte) { h.streamLine(comp, h.decodeOutput(line)) }}
+	return s, s.flush
+}
+
+// streamLine sends one output line to Config.OutputWriter and Config.Logger
+// With JSONEvents the Logger receives a build_output event per line
+func (h *GoBuild) streamLine(comp *Build, line string) {
+	if h.config.OutputWriter != nil {
+		h.logMu.Lock()
+		fmt.Fprintln(h.config.OutputWriter, line)
+		h.logMu.Unlock()
+	}
+	if !h.config.StreamOutput || h.config.Logger == nil {
+		return
+	}
+	if h.config.JSONEvents {
+		h.emitEvent(BuildEvent{Event: EventBuildOutput, BuildID: comp.ID, Output: line})
+		return
+	}
+	h.config.Logger(line)
+}
@@ -0,0 +1,66 @@
+package gobuild
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// chanWriter forwards each write to a channel
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestStreamOutputLive(t *testing.T) {
+	dir := t.TempDir()
+	proceed := filepath.Join(dir, "proceed")
+	lines := make(chanWriter, 10)
+	var mu sync.Mutex
+	var logged []string
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'go: downloading example.com/lib v1.0.0' >&2; while [ ! -e "+proceed+" ]; do sleep 0.01; done; echo line2; printf tail\n"+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		OutputWriter:              lines,
+		StreamOutput:              true,
+		Logger: func(message ...any) {
+			mu.Lock()
+			defer mu.Unlock()
+			logged = append(logged, fmt.Sprint(message...))
+		},
+	})
+
+	b := gb.Start()
+	select {
+	case line := <-lines:
+		if line != "go: downloading example.com/lib v1.0.0\n" {
+			t.Errorf("Unexpected first line %q", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the first line while the compiler is still running")
+	}
+	if !gb.IsCompiling() {
+		t.Error("Expected the build to still be running")
+	}
+
+	os.WriteFile(proceed, nil, 0644)
+	if err := b.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if got := []string{<-lines, <-lines}; got[0] != "line2\n" || got[1] != "tail\n" {
+		t.Errorf("Expected line2 and the unterminated tail, got %q", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(logged) != 3 || logged[1] != "line2" {
+		t.Errorf("Expected the 3 lines in the Logger, got %q", logged)
+	}
+}
@@ -0,0 +1,99 @@
+package gobuild
+
+import (
+	"regexp"
+	"strings"
+)
+
+// SuggestionKind identifies the automated fix a Suggestion proposes
+type SuggestionKind string
+
+const (
+	SuggestGoGet     SuggestionKind = "go-get"     // add a missing module requirement
+	SuggestModTidy   SuggestionKind = "mod-tidy"   // go.mod/go.sum out of date
+	SuggestModVendor SuggestionKind = "mod-vendor" // vendor folder out of sync with go.mod
+	SuggestImports   SuggestionKind = "goimports"  // fix the imports and gofmt the file
+	SuggestGoCommand SuggestionKind = "go-command" // any other command the go tool recommends
+)
+
+// Suggestion is a fix gobuild can propose after a failed build, eg: for a one-keypress remediation
+type Suggestion struct {
+	Kind    SuggestionKind
+	Message string   // the compiler message that triggered it
+	File    string   // source file the fix applies to, empty for module-wide fixes
+	Command []string // command to run from Config.WorkDir, eg: []string{"go", "mod", "tidy"}
+}
+
+// String returns the command line, eg: "go get example.com/lib"
+func (s Suggestion) String() string {
+	return strings.Join(s.Command, " ")
+}
+
+// quotedGoCommand matches the "run 'go mod vendor' to sync" style hints of the go tool
+var quotedGoCommand = regexp.MustCompile(`run '(go [^']+)'`)
+
+// importProblem matches type-check errors fixed by goimports
+var importProblem = regexp.MustCompile(`imported and not used|^undefined: [a-z][a-z0-9]*$`)
+
+// parseSuggestions extracts the fixes proposed by a failed build's output and diagnostics
+// The go tool ends lines with "to add it:"/"to update it:" and prints the command on the next line
+func parseSuggestions(output string, diags []Diagnostic) []Suggestion {
+	var suggestions []Suggestion
+	seen := map[string]bool{}
+	add := func(s Suggestion) {
+		if key := s.String(); !seen[key] {
+			seen[key] = true
+			suggestions = append(suggestions, s)
+		}
+	}
+
+	lines := strings.Split(output, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, ":") && strings.Contains(line, "; to ") && i+1 < len(lines) {
+			if next := strings.TrimSpace(lines[i+1]); strings.HasPrefix(next, "go ") {
+				add(goCommandSuggestion(line, next))
+			}
+		}
+		if m := quotedGoCommand.FindStringSubmatch(line); m != nil {
+			add(goCommandSuggestion(line, m[1]))
+		}
+	}
+
+	for _, d := range diags {
+		if d.Severity == SeverityError && d.File != "" && importProblem.MatchString(d.Message) {
+			add(Suggestion{
+				Kind:    SuggestImports,
+				Message: d.Message,
+				File:    d.File,
+				Command: []string{"goimports", "-w", d.File},
+			})
+		}
+	}
+	return suggestions
+}
+
+// goCommandSuggestion builds the Suggestion for a go command recommended by the go tool
+func goCommandSuggestion(message, command string) Suggestion {
+	args := strings.Fields(command)
+	kind := SuggestGoCommand
+	switch {
+	case len(args) > 1 && args[1] == "get":
+		kind = SuggestGoGet
+	case len(args) > 2 && args[1] == "mod" && args[2] == "tidy":
+		kind = SuggestModTidy
+	case len(args) > 2 && args[2] == "vendor":
+		kind = SuggestModVendor
+	}
+	return Suggestion{Kind: kind, Message: message, Command: args}
+}
+
+// reportSuggestions streams the fixes for a failed build to Config.OnSuggestion
+func (h *GoBuild) reportSuggestions(comp *Build) {
+	if h.config.OnSuggestion == nil {
+		return
+	}
+	for _, s := range parseSuggestions(comp.output, comp.diags) {
+		h.config.OnSuggestion(s)
+	}
+}
@@ -0,0 +1,54 @@
+package gobuild
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSuggestions(t *testing.T) {
+	output := "main.go:5:2: no required module provides package example.com/lib; to add it:\n" +
+		"\tgo get example.com/lib\n" +
+		"go: updates to go.mod needed; to update it:\n" +
+		"\tgo mod tidy\n" +
+		"go: inconsistent vendoring in /src/app:\n" +
+		"\trun 'go mod vendor' to sync, or use -mod=mod or -mod=readonly to ignore the vendor directory\n"
+	diags := []Diagnostic{
+		{Severity: SeverityError, File: "./main.go", Line: 3, Message: `"os" imported and not used`},
+		{Severity: SeverityError, File: "./main.go", Line: 9, Message: "cannot use x (variable of type int) as string value"},
+	}
+
+	got := parseSuggestions(output, diags)
+	want := []Suggestion{
+		{Kind: SuggestGoGet, Command: []string{"go", "get", "example.com/lib"}},
+		{Kind: SuggestModTidy, Command: []string{"go", "mod", "tidy"}},
+		{Kind: SuggestModVendor, Command: []string{"go", "mod", "vendor"}},
+		{Kind: SuggestImports, File: "./main.go", Command: []string{"goimports", "-w", "./main.go"}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d suggestions, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].File != want[i].File || !slices.Equal(got[i].Command, want[i].Command) || got[i].Message == "" {
+			t.Errorf("Suggestion %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+}
+
+func TestOnSuggestion(t *testing.T) {
+	dir := t.TempDir()
+	var got []Suggestion
+	err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'main.go:5:2: no required module provides package example.com/lib; to add it:' >&2; printf '\\tgo get example.com/lib\\n' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		OnSuggestion:              func(s Suggestion) { got = append(got, s) },
+	}).CompileProgram()
+
+	if err == nil {
+		t.Fatal("Expected compile error")
+	}
+	if len(got) != 1 || got[0].String() != "go get example.com/lib" {
+		t.Errorf("Expected a go get suggestion, got %+v", got)
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package gobuild
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// applySysProcAttr configures OS specific process attributes (eg: run as another user)
+func (h *GoBuild) applySysProcAttr(cmd *exec.Cmd) error {
+	if h.config.RunAs != nil {
+		return errors.New("applySysProcAttr: RunAs is only supported on unix systems")
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// applySysProcAttr configures OS specific process attributes (eg: run as another user)
+func (h *GoBuild) applySysProcAttr(cmd *exec.Cmd) error {
+	if h.config.RunAs == nil {
+		return nil
+	}
+
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Credential = &syscall.Credential{
+		Uid:    h.config.RunAs.Uid,
+		Gid:    h.config.RunAs.Gid,
+		Groups: h.config.RunAs.Groups,
+	}
+	return nil
+}
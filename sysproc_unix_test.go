@@ -0,0 +1,36 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestApplySysProcAttrRunAs(t *testing.T) {
+	gb := New(&Config{RunAs: &Credential{Uid: 1000, Gid: 1001}})
+
+	cmd := exec.Command("go")
+	if err := gb.applySysProcAttr(cmd); err != nil {
+		t.Fatalf("applySysProcAttr failed: %v", err)
+	}
+
+	if cmd.SysProcAttr == nil || cmd.SysProcAttr.Credential == nil {
+		t.Fatal("Expected SysProcAttr.Credential to be set")
+	}
+	if cmd.SysProcAttr.Credential.Uid != 1000 || cmd.SysProcAttr.Credential.Gid != 1001 {
+		t.Errorf("Unexpected credential: %+v", cmd.SysProcAttr.Credential)
+	}
+}
+
+func TestApplySysProcAttrWithoutRunAs(t *testing.T) {
+	gb := New(&Config{})
+
+	cmd := exec.Command("go")
+	if err := gb.applySysProcAttr(cmd); err != nil {
+		t.Fatalf("applySysProcAttr failed: %v", err)
+	}
+	if cmd.SysProcAttr != nil {
+		t.Error("SysProcAttr should be untouched when RunAs is not set")
+	}
+}
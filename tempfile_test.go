@@ -0,0 +1,79 @@
+package gobuild
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTempFileNameUnique(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{OutName: "app", Extension: ".exe", OutFolderRelativePath: dir})
+
+	first := gb.newTempFileName(7)
+	if !strings.HasPrefix(first, fmt.Sprintf("app_temp_%d_7_", os.Getpid())) || !strings.HasSuffix(first, ".exe") {
+		t.Errorf("Expected PID and build ID in %q", first)
+	}
+
+	// a leftover with the same name must not be reused
+	if err := os.WriteFile(filepath.Join(dir, first), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	seen := map[string]bool{first: true}
+	for i := 0; i < 100; i++ {
+		name := gb.newTempFileName(7)
+		if seen[name] {
+			t.Fatalf("Temp file name %q reused", name)
+		}
+		seen[name] = true
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// fakeOrphanCompiler mimics go build killed while its linker child keeps writing the output
+// Once the "fast" marker exists next to the output it builds right away instead
+const fakeOrphanCompiler = `out=""
+prev=""
+for arg; do
+	if [ "$prev" = "-o" ]; then out="$arg"; fi
+	prev="$arg"
+done
+if [ -e "$(dirname "$out")/fast" ]; then printf artifact > "$out"; exit 0; fi
+(sleep 0.2; printf partial > "$out") >/dev/null 2>&1 &
+exec sleep 5`
+
+func TestSupersededTempFileRemoved(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeOrphanCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	})
+
+	first := gb.Start()
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "fast"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := gb.Start().Wait(); err != nil {
+		t.Fatalf("Second build failed: %v", err)
+	}
+	waited := time.Now()
+	first.Wait()
+	if elapsed := time.Since(waited); elapsed > 2*time.Second {
+		t.Errorf("Superseded build waited %v for its orphaned children, they should be killed with it", elapsed)
+	}
+
+	// the linker-like child would have written by now had it outlived the build
+	time.Sleep(300 * time.Millisecond)
+	temps, _ := filepath.Glob(filepath.Join(dir, "app_temp_*"))
+	if len(temps) != 0 {
+		t.Errorf("Expected no orphaned temp files, found %v", temps)
+	}
+}
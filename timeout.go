@@ -0,0 +1,42 @@
+package gobuild
+
+import (
+	"errors"
+	"time"
+)
+
+// ExtendTimeout pushes back the deadline of the active compilation by d
+// eg: progress shows modules still downloading during a long cold build
+// Returns an error if there is no active compilation or it already timed out
+func (h *GoBuild) ExtendTimeout(d time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.active == nil {
+		return errors.New("ExtendTimeout: no active compilation")
+	}
+	return h.active.extendDeadline(d)
+}
+
+// extendDeadline moves the compilation deadline forward and re-arms its timer
+// While paused for a Limiter slot only the remaining time grows, waitSlot re-arms the timer
+// Must be called with the GoBuild mutex held
+func (c *Build) extendDeadline(d time.Duration) error {
+	if c.paused {
+		c.remaining += d
+		c.deadline = c.deadline.Add(d)
+		return nil
+	}
+	if !c.timer.Stop() {
+		return errors.New("ExtendTimeout: compilation already timed out or finished")
+	}
+	c.deadline = c.deadline.Add(d)
+	c.timer.Reset(c.deadline.Sub(c.gb.now()))
+	return nil
+}
+
+// stop releases the deadline timer and the context of a finished compilation
+func (c *Build) stop() {
+	c.timer.Stop()
+	c.cancel(nil)
+}
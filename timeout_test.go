@@ -0,0 +1,48 @@
+package gobuild
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeSlowCompiler sleeps before writing the -o output file
+const fakeSlowCompiler = `out=""
+prev=""
+for arg; do
+	if [ "$prev" = "-o" ]; then out="$arg"; fi
+	prev="$arg"
+done
+sleep 0.6
+touch "$out"`
+
+func TestExtendTimeout(t *testing.T) {
+	tempDir := t.TempDir()
+
+	config := &Config{
+		Command:                   writeFakeCompiler(t, tempDir, fakeSlowCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     tempDir,
+		Timeout:                   200 * time.Millisecond,
+	}
+	gb := New(config)
+
+	done := make(chan error, 1)
+	go func() { done <- gb.CompileProgram() }()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := gb.ExtendTimeout(2 * time.Second); err != nil {
+		t.Fatalf("ExtendTimeout failed: %v", err)
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("Compilation should succeed after extending the timeout, got: %v", err)
+	}
+}
+
+func TestExtendTimeoutWithoutActiveCompilation(t *testing.T) {
+	gb := New(&Config{})
+	if err := gb.ExtendTimeout(time.Second); err == nil {
+		t.Error("Expected error when no compilation is active")
+	}
+}
@@ -0,0 +1,74 @@
+package gobuild
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// BuildTimings splits the duration of a build into its phases
+// Phases a build didn't reach (or skipped, eg: compile on a cache hit) stay zero
+type BuildTimings struct {
+	Queued      time.Duration `json:"queued"`       // waiting for the previous build, a CancelQueue turn or the Debounce quiet period
+	Validation  time.Duration `json:"validation"`   // Validate, env files, Policy and cgo toolchain checks
+	Prepare     time.Duration `json:"prepare"`      // pre-build steps: experiments check, vendor sync, cache lookup, Limiter slot
+	Download    time.Duration `json:"download"`     // module downloads reported by the go command ("go: downloading ...")
+	Compile     time.Duration `json:"compile"`      // the compiler process, downloads excluded
+	PostProcess time.Duration `json:"post_process"` // wasm-opt, gzip and the cache store
+	Rename      time.Duration `json:"rename"`       // promotion of the temp file to the final artifact (fsync and verify included)
+}
+
+// String returns the non zero phases, eg: "validation 2ms, compile 7.1s, rename 3ms"
+func (t BuildTimings) String() string {
+	phases := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"queued", t.Queued}, {"validation", t.Validation}, {"prepare", t.Prepare},
+		{"download", t.Download}, {"compile", t.Compile}, {"post-process", t.PostProcess}, {"rename", t.Rename},
+	}
+	var parts []string
+	for _, p := range phases {
+		if p.d > 0 {
+			parts = append(parts, fmt.Sprintf("%s %v", p.name, p.d.Round(time.Millisecond)))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// lap adds the time elapsed since the previous lap to phase
+func (b *Build) lap(phase *time.Duration) {
+	now := b.gb.now()
+	*phase += now.Sub(b.lapStart)
+	b.lapStart = now
+}
+
+// lapCompile splits the compiler run into Download and Compile at the last
+// "go: downloading" line, the go command resolves modules before compiling
+func (b *Build) lapCompile() {
+	now := b.gb.now()
+	if !b.fetchedAt.IsZero() && b.fetchedAt.After(b.lapStart) {
+		b.timings.Download += b.fetchedAt.Sub(b.lapStart)
+		b.lapStart = b.fetchedAt
+	}
+	b.timings.Compile += now.Sub(b.lapStart)
+	b.lapStart = now
+}
+
+// downloadWatcher records when the compiler output last reported a module download
+type downloadWatcher struct {
+	out  io.Writer
+	last *time.Time
+	now  func() time.Time
+}
+
+var downloadMarker = []byte("go: downloading ")
+
+func (w downloadWatcher) Write(p []byte) (int, error) {
+	if bytes.Contains(p, downloadMarker) {
+		*w.last = w.now()
+	}
+	return w.out.Write(p)
+}
@@ -0,0 +1,54 @@
+package gobuild
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildTimings(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'go: downloading example.com/lib v1.0.0' >&2; sleep 0.2; "+fakeEchoCompiler+"; sleep 0.1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}).Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	tm := r.Timings
+	if tm.Download > 100*time.Millisecond {
+		t.Errorf("Expected the download phase to end at the download line, got %v", tm.Download)
+	}
+	if tm.Compile < 250*time.Millisecond {
+		t.Errorf("Expected the compile phase to cover the compiler run, got %v", tm.Compile)
+	}
+	if tm.Rename <= 0 {
+		t.Errorf("Expected a rename phase, got %+v", tm)
+	}
+	sum := tm.Queued + tm.Validation + tm.Prepare + tm.Download + tm.Compile + tm.PostProcess + tm.Rename
+	if sum > r.Duration {
+		t.Errorf("Phases (%v) exceed the build duration (%v)", sum, r.Duration)
+	}
+	if s := tm.String(); !strings.Contains(s, "compile ") || !strings.Contains(s, "rename ") {
+		t.Errorf("Unexpected summary %q", s)
+	}
+}
+
+func TestBuildTimingsDownloadPhase(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "sleep 0.2; echo 'go: downloading example.com/lib v1.0.0' >&2; "+fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+	}).Compile()
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if r.Timings.Download < 150*time.Millisecond || r.Timings.Compile > 150*time.Millisecond {
+		t.Errorf("Expected the time before the last download in Download, got %+v", r.Timings)
+	}
+}
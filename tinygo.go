@@ -0,0 +1,246 @@
+package gobuild
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// TinyGoWasm chains the size optimizations TinyGo web users otherwise assemble by hand:
+// TinyGo flags (-no-debug, -panic=trap, -opt), wasm-opt and gzip, with a final size report
+// Use it with Command "tinygo" and Extension ".wasm"
+type TinyGoWasm struct {
+	Target       string               // tinygo -target, defaults to "wasm"
+	Opt          string               // tinygo -opt level, defaults to "z"
+	KeepDebug    bool                 // drop -no-debug, eg: to profile the module
+	KeepPanics   bool                 // drop -panic=trap, keeps panic messages at a size cost
+	WasmOpt      string               // wasm-opt binary run on the module, empty to skip, eg: "wasm-opt"
+	WasmOptLevel string               // defaults to "-Oz"
+	Gzip         bool                 // also write <artifact>.gz next to the artifact
+	Report       func(WasmSizeReport) // optional, receives the sizes of each successful build
+}
+
+// WasmSizeReport lists the artifact size after each step of the TinyGoWasm pipeline
+type WasmSizeReport struct {
+	Compiled  int64 `json:"compiled"`          // as produced by tinygo
+	Optimized int64 `json:"optimized"`         // after wasm-opt, equals Compiled when skipped
+	Gzipped   int64 `json:"gzipped,omitempty"` // size of the .gz file, 0 when Gzip is off
+}
+
+func (r WasmSizeReport) String() string {
+	s := fmt.Sprintf("wasm: %s compiled, %s optimized", formatBytes(r.Compiled), formatBytes(r.Optimized))
+	if r.Gzipped > 0 {
+		s += fmt.Sprintf(", %s gzipped", formatBytes(r.Gzipped))
+	}
+	return s
+}
+
+// tinyGoUnsupported lists go build flags tinygo rejects
+var tinyGoUnsupported = map[string]bool{
+	"-race": true, "-msan": true, "-asan": true, "-cover": true, "-trimpath": true,
+	"-gcflags": true, "-asmflags": true, "-pgo": true, "-buildvcs": true, "-toolexec": true,
+	"-compiler": true, "-installsuffix": true, "-linkshared": true, "-pkgdir": true,
+}
+
+// tinyGo reports whether the compiler is tinygo: Command, a CommandLine element or the TinyGoWasm preset
+func (h *GoBuild) tinyGo() bool {
+	if h.config.TinyGoWasm != nil || isTinyGo(h.config.Command) {
+		return true
+	}
+	for _, arg := range h.config.CommandLine {
+		if isTinyGo(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// tinyGoArgs translates go build arguments for tinygo
+// tinygo -ldflags only accepts -X: -s/-w become -no-debug, other linker flags are dropped
+// js/wasm and wasip1/wasm builds without -target get it from GOOS/GOARCH
+func (h *GoBuild) tinyGoArgs(buildArgs, ldFlags []string) ([]string, []string) {
+	var fields, kept []string
+	for _, entry := range ldFlags {
+		fields = append(fields, splitQuoted(entry)...)
+	}
+	noDebug := false
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i]; {
+		case f == "-X" && i+1 < len(fields):
+			kept = append(kept, f, fields[i+1])
+			i++
+		case strings.HasPrefix(f, "-X="):
+			kept = append(kept, "-X", strings.TrimPrefix(f, "-X="))
+		case f == "-s", f == "-w":
+			noDebug = true
+		}
+	}
+	if len(kept) > 0 {
+		kept = []string{strings.Join(kept, " ")}
+	}
+
+	hasTarget, hasNoDebug := false, false
+	for _, arg := range buildArgs {
+		hasTarget = hasTarget || arg == "-target" || strings.HasPrefix(arg, "-target=")
+		hasNoDebug = hasNoDebug || arg == "-no-debug"
+	}
+	if noDebug && !hasNoDebug {
+		buildArgs = append(buildArgs, "-no-debug")
+	}
+	if !hasTarget {
+		switch goos, goarch := h.target(); {
+		case goos == "js" && goarch == "wasm":
+			buildArgs = append(buildArgs, "-target=wasm")
+		case goos == "wasip1" && goarch == "wasm":
+			buildArgs = append(buildArgs, "-target=wasip1")
+		}
+	}
+	return buildArgs, kept
+}
+
+// tinyGoIssues returns the go build flags of CompilingArguments tinygo can't handle
+func (h *GoBuild) tinyGoIssues(add func(field, format string, args ...any)) {
+	for _, arg := range h.compilingArguments() {
+		name, _, _ := strings.Cut(arg, "=")
+		if tinyGoUnsupported[name] {
+			add("CompilingArguments", "%s is not supported by tinygo", name)
+		}
+	}
+}
+
+// args returns the tinygo flags of the preset
+func (t *TinyGoWasm) args() []string {
+	target, opt := t.Target, t.Opt
+	if target == "" {
+		target = "wasm"
+	}
+	if opt == "" {
+		opt = "z"
+	}
+	args := []string{"-target=" + target, "-opt=" + opt}
+	if !t.KeepDebug {
+		args = append(args, "-no-debug")
+	}
+	if !t.KeepPanics {
+		args = append(args, "-panic=trap")
+	}
+	return args
+}
+
+// optimizeWasm runs wasm-opt over the temp module before it is promoted
+func (h *GoBuild) optimizeWasm(ctx context.Context, comp *Build) error {
+	t := h.config.TinyGoWasm
+	tempPath := h.tempPath(comp.tempFile)
+	info, err := os.Stat(fixLongPath(tempPath))
+	if err != nil {
+		return err
+	}
+	comp.wasmSizes = &WasmSizeReport{Compiled: info.Size(), Optimized: info.Size()}
+	if t.WasmOpt == "" {
+		return nil
+	}
+
+	level := t.WasmOptLevel
+	if level == "" {
+		level = "-Oz"
+	}
+	optPath := tempPath + ".opt"
+	cmd := exec.CommandContext(ctx, t.WasmOpt, level, "--all-features", tempPath, "-o", optPath)
+	cmd.Dir = h.config.WorkDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(optPath)
+		optErr := fmt.Errorf("wasm-opt: %v %s", err, strings.TrimSpace(h.decodeOutput(output)))
+		if toolchainMissing(err) {
+			return &BuildError{Code: ErrCodeToolchainMissing, Err: optErr}
+		}
+		return optErr
+	}
+	if err := os.Rename(optPath, tempPath); err != nil {
+		os.Remove(optPath)
+		return err
+	}
+	if info, err := os.Stat(fixLongPath(tempPath)); err == nil {
+		comp.wasmSizes.Optimized = info.Size()
+	}
+	return nil
+}
+
+// compressWasm writes the gzip copy of the promoted module and reports the sizes
+func (h *GoBuild) compressWasm(comp *Build) error {
+	t := h.config.TinyGoWasm
+	finalPath := h.FinalOutputPath()
+	if comp.wasmSizes == nil { // restored from the cache, the pipeline already ran
+		info, err := h.statArtifact(finalPath)
+		if err != nil {
+			return err
+		}
+		comp.wasmSizes = &WasmSizeReport{Compiled: info.Size(), Optimized: info.Size()}
+	}
+
+	if t.Gzip {
+		size, err := h.gzipFile(finalPath, finalPath+".gz")
+		if err != nil {
+			return errors.Join(errors.New("gzip wasm"), err)
+		}
+		comp.wasmSizes.Gzipped = size
+	}
+
+	if t.Report != nil {
+		t.Report(*comp.wasmSizes)
+	}
+	return nil
+}
+
+// gzipFile compresses src into dst with the best compression, returns the dst size
+// Both are promoted files, see Config.OutputFS
+func (h *GoBuild) gzipFile(src, dst string) (int64, error) {
+	in, err := h.openArtifact(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	return h.writeSidecar(dst, func(w io.Writer) error {
+		zw, _ := gzip.NewWriterLevel(w, gzip.BestCompression)
+		if _, err := io.Copy(zw, in); err != nil {
+			return err
+		}
+		return zw.Close()
+	})
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// formatBytes returns n in B, KiB or MiB, eg: 1.5 MiB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// formatSizeDelta returns d signed in B, KiB or MiB, eg: +12.4 KiB, -3 B
+func formatSizeDelta(d int64) string {
+	if d < 0 {
+		return "-" + formatBytes(-d)
+	}
+	return "+" + formatBytes(d)
+}
@@ -0,0 +1,165 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTinyGoWasmArguments(t *testing.T) {
+	gb := New(&Config{
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     "web",
+		TinyGoWasm:                &TinyGoWasm{KeepPanics: true},
+	})
+
+	expected := []string{"build", "-target=wasm", "-opt=z", "-no-debug", "-o", filepath.Join("web", "main_temp.wasm"), "main.go"}
+	if args := gb.BuildArguments(); !reflect.DeepEqual(args, expected) {
+		t.Errorf("Expected %q, got %q", expected, args)
+	}
+}
+
+// fakeWasmOpt writes a smaller module to the -o path
+const fakeWasmOpt = `prev=""
+for arg; do
+	if [ "$prev" = "-o" ]; then printf opt > "$arg"; fi
+	prev="$arg"
+done`
+
+func TestTinyGoWasmPipeline(t *testing.T) {
+	dir := t.TempDir()
+	optDir := filepath.Join(dir, "binaryen")
+	if err := os.MkdirAll(optDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var reported WasmSizeReport
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     dir,
+		TinyGoWasm: &TinyGoWasm{
+			WasmOpt: writeFakeCompiler(t, optDir, fakeWasmOpt),
+			Gzip:    true,
+			Report:  func(r WasmSizeReport) { reported = r },
+		},
+	})
+
+	b := gb.Start()
+	if err := b.Wait(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	sizes := b.Result().WasmSizes
+	if sizes == nil || sizes.Compiled != int64(len("artifact")) || sizes.Optimized != int64(len("opt")) || sizes.Gzipped == 0 {
+		t.Fatalf("Unexpected sizes: %+v", sizes)
+	}
+	if reported != *sizes {
+		t.Errorf("Expected report %+v, got %+v", *sizes, reported)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "main.wasm")); string(data) != "opt" {
+		t.Errorf("Expected the optimized module promoted, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "main.wasm.gz")); err != nil {
+		t.Errorf("Expected gzip copy: %v", err)
+	}
+}
+
+func TestTinyGoWasmOptMissing(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".wasm",
+		OutFolderRelativePath:     dir,
+		TinyGoWasm:                &TinyGoWasm{WasmOpt: filepath.Join(dir, "no-wasm-opt")},
+	})
+
+	if err := gb.CompileProgram(); CodeOf(err) != ErrCodeToolchainMissing {
+		t.Errorf("Expected %s, got %q (%v)", ErrCodeToolchainMissing, CodeOf(err), err)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	r := WasmSizeReport{Compiled: 3 << 20, Optimized: 1536, Gzipped: 900}
+	if s := r.String(); s != "wasm: 3.0 MiB compiled, 1.5 KiB optimized, 900 B gzipped" {
+		t.Errorf("Unexpected report %q", s)
+	}
+}
+
+func TestTinyGoArguments(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "tinygo",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     "web",
+		TargetWASM:                true,
+		CompilingArguments: func() []string {
+			return []string{"-X", "main.version=1.0", "-ldflags=-s -w -extldflags=-static"}
+		},
+	})
+
+	args := gb.BuildArguments()
+	for _, want := range []string{"-target=wasm", "-no-debug", "-ldflags=-X main.version=1.0"} {
+		if !slices.Contains(args, want) {
+			t.Errorf("Expected %q in %v", want, args)
+		}
+	}
+	for _, arg := range args {
+		if strings.Contains(arg, "-extldflags") || strings.Contains(arg, "-s -w") {
+			t.Errorf("Unexpected go-only linker flag in %v", args)
+		}
+	}
+	if gb.MainOutputFileNameWithExtension() != "main.wasm" {
+		t.Errorf("Expected main.wasm, got %q", gb.MainOutputFileNameWithExtension())
+	}
+}
+
+func TestTinyGoKeepsExplicitTarget(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "/usr/local/bin/tinygo",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "firmware",
+		OutFolderRelativePath:     "out",
+		Extension:                 ".hex",
+		CompilingArguments:        func() []string { return []string{"-target=pico"} },
+	})
+
+	args := gb.BuildArguments()
+	n := 0
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-target") {
+			n++
+		}
+	}
+	if n != 1 || !slices.Contains(args, "-target=pico") {
+		t.Errorf("Expected only -target=pico, got %v", args)
+	}
+}
+
+func TestTinyGoUnsupportedFlags(t *testing.T) {
+	gb := New(&Config{
+		CommandLine:               []string{"nix", "develop", "-c", "tinygo"},
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     t.TempDir(),
+		CompilingArguments:        func() []string { return []string{"-trimpath", "-gcflags=-N", "-tags=web"} },
+	})
+
+	err := gb.Validate()
+	if err == nil {
+		t.Fatal("Expected unsupported flags to be rejected")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "-trimpath") || !strings.Contains(msg, "-gcflags") || strings.Contains(msg, "-tags") {
+		t.Errorf("Unexpected validation error: %v", msg)
+	}
+}
@@ -0,0 +1,70 @@
+package gobuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// TracerProvider creates the Tracer of Config.TracerProvider, its shape follows
+// OpenTelemetry so an adapter over go.opentelemetry.io/otel/trace is a few lines
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+// Tracer starts spans, ctx carries the parent (BuildOptions.TraceContext)
+type Tracer interface {
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+}
+
+// Span is one traced compilation
+type Span interface {
+	SetAttribute(key string, value any) // value is a string, int64 or bool
+	RecordError(err error)              // called once for failed builds, before End
+	End()
+}
+
+// tracerName identifies the spans emitted by this package
+const tracerName = "github.com/tinywasm/gobuild"
+
+// startSpan opens the "gobuild.compile" span of comp when Config.TracerProvider is set
+func (h *GoBuild) startSpan(comp *Build) {
+	if h.config.TracerProvider == nil {
+		return
+	}
+	parent := comp.traceCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	_, comp.span = h.config.TracerProvider.Tracer(tracerName).Start(parent, "gobuild.compile")
+
+	goos, goarch := h.target()
+	comp.span.SetAttribute("gobuild.build_id", int64(comp.ID))
+	comp.span.SetAttribute("gobuild.output", h.outFileName)
+	comp.span.SetAttribute("gobuild.target", goos+"/"+goarch)
+	if comp.label != "" {
+		comp.span.SetAttribute("gobuild.label", comp.label)
+	}
+}
+
+// endSpan closes the span of comp with its result
+func (h *GoBuild) endSpan(comp *Build) {
+	if comp.span == nil {
+		return
+	}
+	r := comp.result
+	if len(comp.argv) > 0 {
+		sum := sha256.Sum256([]byte(strings.Join(comp.argv, "\x00")))
+		comp.span.SetAttribute("gobuild.args_hash", hex.EncodeToString(sum[:]))
+	}
+	if r.Err == nil {
+		comp.span.SetAttribute("gobuild.result", "success")
+		comp.span.SetAttribute("gobuild.binary_size", r.Size)
+		comp.span.SetAttribute("gobuild.restored", r.RestoredFromCache)
+	} else {
+		comp.span.SetAttribute("gobuild.result", string(r.Code))
+		comp.span.RecordError(r.Err)
+	}
+	comp.span.End()
+}
@@ -0,0 +1,81 @@
+package gobuild
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type traceKey struct{}
+
+type recordingSpan struct {
+	mu     sync.Mutex
+	parent any
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs[key] = value
+}
+
+func (s *recordingSpan) RecordError(err error) { s.err = err }
+func (s *recordingSpan) End()                  { s.ended = true }
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Tracer(string) Tracer { return t }
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recordingSpan{parent: ctx.Value(traceKey{}), attrs: map[string]any{"name": name}}
+	t.mu.Lock()
+	t.spans = append(t.spans, span)
+	t.mu.Unlock()
+	return ctx, span
+}
+
+func TestTracerProviderSpans(t *testing.T) {
+	dir := t.TempDir()
+	tracer := &recordingTracer{}
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Env:                       []string{"GOOS=linux", "GOARCH=arm64"},
+		TracerProvider:            tracer,
+	})
+
+	parent := context.WithValue(context.Background(), traceKey{}, "request-42")
+	if err := gb.StartWith(BuildOptions{TraceContext: parent}).Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tracer.spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if !span.ended || span.err != nil || span.parent != "request-42" {
+		t.Errorf("Unexpected span state %+v", span)
+	}
+	for key, want := range map[string]any{
+		"name":                "gobuild.compile",
+		"gobuild.target":      "linux/arm64",
+		"gobuild.result":      "success",
+		"gobuild.binary_size": int64(len("artifact")),
+		"gobuild.build_id":    int64(1),
+	} {
+		if got := span.attrs[key]; got != want {
+			t.Errorf("Expected %s=%v, got %v", key, want, got)
+		}
+	}
+	if hash, _ := span.attrs["gobuild.args_hash"].(string); len(hash) != 64 {
+		t.Errorf("Expected a sha256 args hash, got %q", hash)
+	}
+}
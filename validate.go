@@ -0,0 +1,183 @@
+package gobuild
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ValidationIssue describes one invalid or ambiguous configuration value
+type ValidationIssue struct {
+	Field   string // Config field name, eg: "OutName"
+	Message string
+}
+
+// ValidationError lists every configuration problem found by Validate
+type ValidationError struct {
+	Issues []ValidationIssue
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Issues))
+	for _, i := range e.Issues {
+		msgs = append(msgs, i.Field+": "+i.Message)
+	}
+	return fmt.Sprintf("invalid config: %s", strings.Join(msgs, "; "))
+}
+
+// Validate checks the configuration before any process is spawned
+// Relative paths are resolved against WorkDir (or the current directory)
+// Returns a *ValidationError listing every problem, nil if the config is usable
+func (h *GoBuild) Validate() error {
+	var issues []ValidationIssue
+	add := func(field, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	c := h.config
+	if c.Command == "" && len(c.CommandLine) == 0 && c.Agent == nil {
+		add("Command", "required, eg: go, tinygo")
+	}
+
+	if len(c.SecretStamps) > 0 && c.Agent != nil {
+		add("SecretStamps", "not supported when delegating to an Agent")
+	}
+	if len(c.SecretStamps) > 0 && (h.tinyGo() || c.Mobile != nil) {
+		add("SecretStamps", "needs go build -overlay, not available with tinygo or gomobile")
+	}
+	if len(c.SecretStamps) > 0 && slices.ContainsFunc(h.compilingArguments(), func(a string) bool { return flagName(a) == "-overlay" }) {
+		add("SecretStamps", "the generated -overlay can't be combined with a CompilingArguments -overlay")
+	}
+
+	if c.OutName == "" {
+		add("OutName", "required, eg: app")
+	} else if strings.ContainsAny(c.OutName, `/\`) {
+		add("OutName", "%q must be a file name, use OutFolderRelativePath for folders", c.OutName)
+	}
+
+	if c.MainInputFileRelativePath == "" {
+		add("MainInputFileRelativePath", "required, eg: cmd/main.go")
+	} else if ambiguousPath(c.WorkDir, c.MainInputFileRelativePath) {
+		add("MainInputFileRelativePath", "%q is neither absolute nor relative (drive relative or rooted without a drive), it can't be resolved against WorkDir %q, use an absolute path", c.MainInputFileRelativePath, c.WorkDir)
+	}
+
+	if ambiguousPath(c.WorkDir, c.OutFolderRelativePath) {
+		add("OutFolderRelativePath", "%q is neither absolute nor relative (drive relative or rooted without a drive), it can't be resolved against WorkDir %q, use an absolute path", c.OutFolderRelativePath, c.WorkDir)
+	}
+
+	outDir := h.resolve(c.OutFolderRelativePath)
+	if info, err := os.Stat(outDir); err == nil && !info.IsDir() {
+		add("OutFolderRelativePath", "%q is a file, not a folder", outDir)
+	}
+	h.stagingIssues(add)
+
+	switch c.Mod {
+	case "", "readonly", "vendor", "mod":
+	default:
+		add("Mod", "%q must be readonly, vendor or mod", c.Mod)
+	}
+
+	if c.ModFile != "" {
+		modFile := h.resolve(c.ModFile)
+		if filepath.Ext(modFile) != ".mod" {
+			add("ModFile", "%q must end in .mod, eg: go.legacy.mod", c.ModFile)
+		} else if _, err := os.Stat(modFile); err != nil {
+			add("ModFile", "%q not found", modFile)
+		}
+	}
+
+	for _, e := range c.Experiments {
+		if e == "" || strings.ContainsAny(e, ", =") {
+			add("Experiments", "%q must be a single experiment name, eg: loopvar", e)
+		}
+	}
+	for _, d := range c.GoDebug {
+		if key, _, ok := strings.Cut(d, "="); !ok || key == "" || strings.ContainsAny(d, ", ") {
+			add("GoDebug", "%q must be a key=value setting, eg: http2client=0", d)
+		}
+	}
+
+	if m := c.Mobile; m != nil {
+		if m.Target == "" {
+			add("Mobile", "Target required, eg: android, ios")
+		} else if c.Extension != m.extension() {
+			add("Extension", "%q must be %q for gomobile %s -target=%s", c.Extension, m.extension(), h.subcommand(), m.Target)
+		}
+		if m.bundle() && (c.Cache != nil || c.CacheDir != "") {
+			add("Cache", "%s bundles are directories and can't be cached", m.extension())
+		}
+		if m.bundle() && c.Compress != nil {
+			add("Compress", "%s bundles are directories and can't be compressed", m.extension())
+		}
+	}
+
+	if c.StripSymbols && c.Profiling != nil {
+		add("StripSymbols", "Profiling keeps the symbols pprof needs")
+	}
+
+	if c.FinalNameFunc != nil && c.Mobile != nil && c.Mobile.bundle() {
+		add("FinalNameFunc", "%s bundles are directories and can't be linked", c.Mobile.extension())
+	}
+
+	if c.Runner != nil && (c.RunAs != nil || c.Sandbox != nil) {
+		add("Runner", "RunAs and Sandbox are not applied by a custom Runner")
+	}
+
+	if c.Sandbox != nil {
+		h.sandboxIssues(add)
+	}
+
+	if c.TargetWASM {
+		h.wasmIssues(add)
+	}
+
+	if c.Race {
+		h.raceIssues(add)
+	}
+
+	if c.Coverage != nil {
+		h.coverageIssues(add)
+	}
+
+	if c.Device != "" {
+		h.deviceIssues(add)
+	}
+
+	if c.RunAfterBuild {
+		h.runIssues(add)
+	}
+
+	if h.tinyGo() {
+		h.tinyGoIssues(add)
+		if c.BuildSalt && c.BuildSaltVar == "" {
+			add("BuildSaltVar", "required by BuildSalt with tinygo, which has no -buildid")
+		}
+	}
+
+	if c.Layout == LayoutByVersion {
+		if c.Version == "" {
+			add("Version", "required by LayoutByVersion, eg: v1.2.0")
+		} else if strings.ContainsAny(c.Version, `/\`) || c.Version == "." || c.Version == ".." {
+			add("Version", "%q must be a single folder name", c.Version)
+		}
+	}
+
+	if len(issues) > 0 {
+		return &ValidationError{Issues: issues}
+	}
+	return nil
+}
+
+// ambiguousPath reports whether p is neither absolute nor relative on windows,
+// eg: `\dist` (root of the current drive) or "D:dist" (current folder of drive D),
+// joining it to workDir would silently change its meaning
+// Plain relative paths, ".." included, resolve against workDir
+func ambiguousPath(workDir, p string) bool {
+	p = filepath.FromSlash(p)
+	if workDir == "" || filepath.IsAbs(p) {
+		return false
+	}
+	return filepath.VolumeName(p) != "" || strings.HasPrefix(p, string(filepath.Separator))
+}
@@ -0,0 +1,110 @@
+package gobuild
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tempDir := t.TempDir()
+	fileAsFolder := filepath.Join(tempDir, "notafolder")
+	os.WriteFile(fileAsFolder, []byte("x"), 0644)
+
+	tests := []struct {
+		name   string
+		config *Config
+		fields []string
+	}{
+		{
+			name: "valid config",
+			config: &Config{
+				Command:                   "go",
+				MainInputFileRelativePath: "cmd/main.go",
+				OutName:                   "app",
+				OutFolderRelativePath:     "build",
+				WorkDir:                   tempDir,
+			},
+		},
+		{
+			name:   "missing required fields",
+			config: &Config{},
+			fields: []string{"Command", "OutName", "MainInputFileRelativePath"},
+		},
+		{
+			name: "out name with folder",
+			config: &Config{
+				Command:                   "go",
+				MainInputFileRelativePath: "main.go",
+				OutName:                   "bin/app",
+			},
+			fields: []string{"OutName"},
+		},
+		{
+			name: "relative paths leaving WorkDir resolve against it",
+			config: &Config{
+				Command:                   "go",
+				MainInputFileRelativePath: "../other/main.go",
+				OutName:                   "app",
+				OutFolderRelativePath:     "../dist",
+				WorkDir:                   tempDir,
+			},
+		},
+		{
+			name: "output folder is a file",
+			config: &Config{
+				Command:                   "go",
+				MainInputFileRelativePath: "main.go",
+				OutName:                   "app",
+				OutFolderRelativePath:     fileAsFolder,
+			},
+			fields: []string{"OutFolderRelativePath"},
+		},
+	}
+
+	if runtime.GOOS == "windows" {
+		tests = append(tests, struct {
+			name   string
+			config *Config
+			fields []string
+		}{
+			name: "drive relative and rooted paths with WorkDir",
+			config: &Config{
+				Command:                   "go",
+				MainInputFileRelativePath: "D:main.go",
+				OutName:                   "app",
+				OutFolderRelativePath:     `\dist`,
+				WorkDir:                   tempDir,
+			},
+			fields: []string{"MainInputFileRelativePath", "OutFolderRelativePath"},
+		})
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := New(tt.config).Validate()
+
+			if len(tt.fields) == 0 {
+				if err != nil {
+					t.Errorf("Expected valid config, got %v", err)
+				}
+				return
+			}
+
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("Expected *ValidationError, got %v", err)
+			}
+			if len(validationErr.Issues) != len(tt.fields) {
+				t.Fatalf("Expected %d issues, got %v", len(tt.fields), validationErr.Issues)
+			}
+			for i, field := range tt.fields {
+				if validationErr.Issues[i].Field != field {
+					t.Errorf("Issue %d: expected field %s, got %s", i, field, validationErr.Issues[i].Field)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package gobuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// vendorInputs are the files go mod/work vendor reads, a change in any of them makes vendor/ stale
+var vendorInputs = []string{"go.mod", "go.sum", "go.work", "go.work.sum"}
+
+// syncVendor runs before vendor-mode builds (Config.Mod "vendor", or a vendor
+// folder the go command would pick up by default)
+// With Config.VendorSync it refreshes vendor/ whenever the module files changed
+// since the last sync, otherwise it only fails early when vendor/ is missing
+func (h *GoBuild) syncVendor(ctx context.Context, env []string) error {
+	root, sub := h.vendorRoot()
+	modules := filepath.Join(root, "vendor", "modules.txt")
+	_, statErr := os.Stat(modules)
+
+	if h.config.Mod != "vendor" && (h.config.Mod != "" || statErr != nil) {
+		return nil // not a vendor-mode build
+	}
+
+	if !h.config.VendorSync {
+		if statErr != nil {
+			return fmt.Errorf("vendor: %q not found, run go %s vendor or set Config.VendorSync", modules, sub)
+		}
+		return nil
+	}
+
+	stamp := hashVendorInputs(root)
+	h.mu.RLock()
+	synced := statErr == nil && h.vendorStamp == stamp
+	h.mu.RUnlock()
+	if synced {
+		return nil
+	}
+
+	cmd := exec.CommandContext(ctx, h.goTool(), sub, "vendor")
+	cmd.Dir = root
+	cmd.Env = h.environment(env)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("vendor: go %s vendor failed in %q: %v %s", sub, root, err, h.decodeOutput(output))
+	}
+
+	h.mu.Lock()
+	h.vendorStamp = stamp
+	h.mu.Unlock()
+	return nil
+}
+
+// vendorRoot returns the folder holding vendor/ and the go subcommand that fills it:
+// the go.work folder ("work") inside a workspace, the module root ("mod") otherwise
+func (h *GoBuild) vendorRoot() (dir, sub string) {
+	root := moduleRoot(filepath.Dir(h.resolve(h.config.MainInputFileRelativePath)))
+	if os.Getenv("GOWORK") != "off" {
+		for d := root; ; d = filepath.Dir(d) {
+			if _, err := os.Stat(filepath.Join(d, "go.work")); err == nil {
+				return d, "work"
+			}
+			if filepath.Dir(d) == d {
+				break
+			}
+		}
+	}
+	return root, "mod"
+}
+
+// goTool returns the go command used for module maintenance steps
+// Config.Command when it is a go binary (eg: /usr/local/go1.22/bin/go), "go" otherwise (eg: tinygo)
+func (h *GoBuild) goTool() string {
+	name := filepath.Base(h.config.Command)
+	if name == "go" || name == "go.exe" {
+		return h.config.Command
+	}
+	return "go"
+}
+
+// hashVendorInputs hashes the vendorInputs found in dir
+func hashVendorInputs(dir string) string {
+	sum := sha256.New()
+	for _, name := range vendorInputs {
+		f, err := os.Open(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+		io.WriteString(sum, name+"\x00")
+		io.Copy(sum, f)
+		f.Close()
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
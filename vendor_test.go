@@ -0,0 +1,80 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// fakeGoVendor counts "go mod vendor" runs in vendor.log and otherwise builds like fakeEchoCompiler
+const fakeGoVendor = `if [ "$1" = "mod" ] && [ "$2" = "vendor" ]; then
+	mkdir -p vendor && touch vendor/modules.txt && echo run >> vendor.log
+	exit 0
+fi
+` + fakeEchoCompiler
+
+func TestVendorSync(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake go script requires a unix shell")
+	}
+	dir := t.TempDir()
+	goPath := filepath.Join(dir, "go")
+	if err := os.WriteFile(goPath, []byte("#!/bin/sh\n"+fakeGoVendor+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gb := New(&Config{
+		Command:                   goPath,
+		WorkDir:                   dir,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "build",
+		Mod:                       "vendor",
+		VendorSync:                true,
+	})
+
+	runs := func() int {
+		data, _ := os.ReadFile(filepath.Join(dir, "vendor.log"))
+		return strings.Count(string(data), "run")
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := gb.CompileProgram(); err != nil {
+			t.Fatalf("CompileProgram failed: %v", err)
+		}
+	}
+	if n := runs(); n != 1 {
+		t.Errorf("Expected a single vendor sync while go.mod is unchanged, got %d", n)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example\n\ngo 1.22\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("CompileProgram failed: %v", err)
+	}
+	if n := runs(); n != 2 {
+		t.Errorf("Expected a vendor sync after go.mod changed, got %d runs", n)
+	}
+}
+
+func TestVendorMissingPreflight(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		WorkDir:                   dir,
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		Mod:                       "vendor",
+	})
+
+	err := gb.CompileProgram()
+	if err == nil || !strings.Contains(err.Error(), "go mod vendor") {
+		t.Errorf("Expected pre-flight vendor error, got: %v", err)
+	}
+}
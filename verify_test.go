@@ -0,0 +1,34 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyArtifact(t *testing.T) {
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		VerifyArtifact:            true,
+	})
+
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("CompileProgram failed: %v", err)
+	}
+	if gb.ArtifactHash() == "" {
+		t.Fatal("Expected artifact hash after a verified promotion")
+	}
+
+	// simulate a tool rewriting the binary right after the rename
+	if err := os.WriteFile(filepath.Join(dir, "app"), []byte("quarantined"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := gb.verifyArtifact(gb.ArtifactHash())
+	if CodeOf(err) != ErrCodeArtifactMismatch {
+		t.Errorf("Expected %s, got %q (%v)", ErrCodeArtifactMismatch, CodeOf(err), err)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+
+package gobuild
+
+import "syscall"
+
+// waitExited blocks until pid exited, leaving it unreaped (kqueue NOTE_EXIT)
+// Returns false if the exit couldn't be awaited
+func waitExited(pid int) bool {
+	kq, err := syscall.Kqueue()
+	if err != nil {
+		return false
+	}
+	defer syscall.Close(kq)
+
+	var change syscall.Kevent_t
+	syscall.SetKevent(&change, pid, syscall.EVFILT_PROC, syscall.EV_ADD|syscall.EV_ONESHOT)
+	change.Fflags = syscall.NOTE_EXIT
+	events := make([]syscall.Kevent_t, 1)
+	for {
+		_, err := syscall.Kevent(kq, []syscall.Kevent_t{change}, events, nil)
+		switch err {
+		case nil:
+			return true
+		case syscall.ESRCH:
+			return true // exited before the registration, still a zombie
+		case syscall.EINTR:
+			continue
+		default:
+			return false
+		}
+	}
+}
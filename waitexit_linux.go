@@ -0,0 +1,22 @@
+package gobuild
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+// pPID is the waitid idtype selecting a single process
+const pPID = 1
+
+// waitExited blocks until pid exited, leaving it unreaped (waitid WNOWAIT)
+// Returns false if the exit couldn't be awaited
+func waitExited(pid int) bool {
+	var info [128]byte // siginfo_t
+	for {
+		_, _, errno := syscall.Syscall6(syscall.SYS_WAITID, pPID, uintptr(pid),
+			uintptr(unsafe.Pointer(&info)), syscall.WEXITED|syscall.WNOWAIT, 0, 0)
+		if errno != syscall.EINTR {
+			return errno == 0
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build unix && !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package gobuild
+
+// waitExited can't wait for an exit without reaping here
+func waitExited(pid int) bool {
+	return false
+}
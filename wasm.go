@@ -0,0 +1,46 @@
+package gobuild
+
+import "strings"
+
+// wasmExtension is the extension Config.TargetWASM builds use
+const wasmExtension = ".wasm"
+
+// wasmEnv returns GOOS=js GOARCH=wasm for Config.TargetWASM, placed before the user env
+func (h *GoBuild) wasmEnv() []string {
+	if !h.config.TargetWASM {
+		return nil
+	}
+	return []string{"GOOS=js", "GOARCH=wasm"}
+}
+
+// wasmIssues returns the settings Config.TargetWASM can't build with
+func (h *GoBuild) wasmIssues(add func(field, format string, args ...any)) {
+	c := h.config
+	if c.Extension != wasmExtension {
+		add("Extension", "%q must be %q with TargetWASM", c.Extension, wasmExtension)
+	}
+	if c.Mobile != nil {
+		add("Mobile", "can't be combined with TargetWASM")
+	}
+	if goos, goarch := h.target(); goos != "js" || goarch != "wasm" {
+		add("Env", "GOOS=%s GOARCH=%s overrides TargetWASM (js/wasm)", goos, goarch)
+	}
+
+	env, _ := h.userEnv()
+	for _, e := range env {
+		if e == "CGO_ENABLED=1" {
+			add("Env", "CGO_ENABLED=1 is not supported by js/wasm")
+		}
+	}
+
+	for _, arg := range h.compilingArguments() {
+		switch {
+		case arg == "-race", arg == "-msan", arg == "-asan":
+			add("CompilingArguments", "%s is not supported by js/wasm", arg)
+		case strings.HasPrefix(arg, "-buildmode="):
+			if mode := strings.TrimPrefix(arg, "-buildmode="); mode != "default" && mode != "exe" {
+				add("CompilingArguments", "%s is not supported by js/wasm", arg)
+			}
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package gobuild
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTargetWASMDefaults(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     t.TempDir(),
+		TargetWASM:                true,
+	})
+
+	if got := gb.MainOutputFileNameWithExtension(); got != "main.wasm" {
+		t.Errorf("Expected main.wasm, got %q", got)
+	}
+	env, err := gb.userEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(env, "GOOS=js") || !slices.Contains(env, "GOARCH=wasm") {
+		t.Errorf("Expected js/wasm env, got %v", env)
+	}
+	if err := gb.Validate(); err != nil {
+		t.Errorf("Expected a valid config, got %v", err)
+	}
+}
+
+func TestTargetWASMIncompatible(t *testing.T) {
+	gb := New(&Config{
+		Command:                   "go",
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		Extension:                 ".exe",
+		OutFolderRelativePath:     t.TempDir(),
+		TargetWASM:                true,
+		Env:                       []string{"GOOS=linux", "CGO_ENABLED=1"},
+		CompilingArguments:        func() []string { return []string{"-race", "-buildmode=c-shared", "-trimpath"} },
+	})
+
+	err := gb.Validate()
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Expected a ValidationError, got %v", err)
+	}
+	msg := err.Error()
+	for _, want := range []string{".exe", "GOOS=linux", "CGO_ENABLED=1", "-race", "-buildmode=c-shared"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("Expected %q to be reported, got %v", want, msg)
+		}
+	}
+	if strings.Contains(msg, "-trimpath") {
+		t.Errorf("-trimpath is supported, got %v", msg)
+	}
+}
@@ -0,0 +1,100 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// wasmExecFile is the JavaScript support file js/wasm binaries are loaded with
+const wasmExecFile = "wasm_exec.js"
+
+// wasmExecLocations are the wasm_exec.js paths inside GOROOT (go 1.24+ first) or TINYGOROOT
+var (
+	goWasmExecLocations     = []string{"lib/wasm/wasm_exec.js", "misc/wasm/wasm_exec.js"}
+	tinyGoWasmExecLocations = []string{"targets/wasm_exec.js"}
+)
+
+// copyWasmExec copies wasm_exec.js of the active toolchain next to the artifact
+// for Config.CopyWasmExec js/wasm builds. go and tinygo ship different versions
+func (h *GoBuild) copyWasmExec(ctx context.Context) error {
+	if !h.config.CopyWasmExec {
+		return nil
+	}
+	if goos, goarch := h.target(); goos != "js" || goarch != "wasm" {
+		return nil
+	}
+
+	if err := h.writeWasmExec(ctx); err != nil {
+		return errors.Join(errors.New("copy "+wasmExecFile), err)
+	}
+	return nil
+}
+
+// writeWasmExec copies the toolchain wasm_exec.js into the output folder
+func (h *GoBuild) writeWasmExec(ctx context.Context) error {
+	src, err := h.wasmExecSource(ctx)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	dst := filepath.Join(filepath.Dir(h.FinalOutputPath()), wasmExecFile)
+	out, err := h.createArtifact(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// wasmExecSource locates wasm_exec.js from `go env GOROOT` (`tinygo env TINYGOROOT`)
+// The path is looked up once per GoBuild
+func (h *GoBuild) wasmExecSource(ctx context.Context) (string, error) {
+	h.mu.RLock()
+	src := h.wasmExecPath
+	h.mu.RUnlock()
+	if src != "" {
+		return src, nil
+	}
+
+	tool, rootVar, locations := h.goTool(), "GOROOT", goWasmExecLocations
+	if h.tinyGo() {
+		tool, rootVar, locations = h.config.Command, "TINYGOROOT", tinyGoWasmExecLocations
+		if tool == "" {
+			tool = "tinygo"
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, tool, "env", rootVar)
+	cmd.Dir = h.config.WorkDir
+	cmd.Env = h.maintenanceEnv()
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s env %s: %w", tool, rootVar, err)
+	}
+	root := strings.TrimSpace(string(output))
+
+	for _, location := range locations {
+		candidate := filepath.Join(root, filepath.FromSlash(location))
+		if _, err := os.Stat(candidate); err == nil {
+			h.mu.Lock()
+			h.wasmExecPath = candidate
+			h.mu.Unlock()
+			return candidate, nil
+		}
+	}
+	return "", errors.New(wasmExecFile + " not found in " + rootVar + " " + root)
+}
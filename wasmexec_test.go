@@ -0,0 +1,83 @@
+package gobuild
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+// writeFakeGo writes a "go" script answering `go env GOROOT` with root and building like fakeEchoCompiler
+func writeFakeGo(t *testing.T, dir, root string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake compiler scripts require a unix shell")
+	}
+	path := filepath.Join(dir, "go")
+	script := "#!/bin/sh\nif [ \"$1\" = env ]; then echo " + root + "; exit 0; fi\n" + fakeEchoCompiler + "\n"
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCopyWasmExec(t *testing.T) {
+	dir, root := t.TempDir(), t.TempDir()
+	os.MkdirAll(filepath.Join(root, "lib", "wasm"), 0755)
+	os.WriteFile(filepath.Join(root, "lib", "wasm", "wasm_exec.js"), []byte("// go runtime glue"), 0644)
+
+	out := filepath.Join(dir, "public")
+	gb := New(&Config{
+		Command:                   writeFakeGo(t, dir, root),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     out,
+		TargetWASM:                true,
+		CopyWasmExec:              true,
+	})
+	if err := gb.CompileProgram(); err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "wasm_exec.js"))
+	if err != nil || string(data) != "// go runtime glue" {
+		t.Errorf("Expected wasm_exec.js next to the output, got %q (%v)", data, err)
+	}
+	if !slices.Contains(gb.UnobservedFiles(), "wasm_exec.js") {
+		t.Errorf("Expected wasm_exec.js to be unobserved, got %v", gb.UnobservedFiles())
+	}
+}
+
+func TestCopyWasmExecNotFound(t *testing.T) {
+	dir := t.TempDir()
+	err := New(&Config{
+		Command:                   writeFakeGo(t, dir, t.TempDir()),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "main",
+		OutFolderRelativePath:     dir,
+		TargetWASM:                true,
+		CopyWasmExec:              true,
+	}).CompileProgram()
+	if err == nil {
+		t.Error("Expected an error when the toolchain has no wasm_exec.js")
+	}
+}
+
+func TestCopyWasmExecSkipsOtherTargets(t *testing.T) {
+	dir := t.TempDir()
+	err := New(&Config{
+		Command:                   writeFakeGo(t, dir, t.TempDir()),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Env:                       []string{"GOOS=linux", "GOARCH=amd64"},
+		CopyWasmExec:              true,
+	}).CompileProgram()
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "wasm_exec.js")); !os.IsNotExist(err) {
+		t.Error("wasm_exec.js must only be copied for js/wasm")
+	}
+}
@@ -0,0 +1,133 @@
+package gobuild
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// defaultWatchInterval is how often Watch scans the source folders
+const defaultWatchInterval = 300 * time.Millisecond
+
+// fileStamp is what Watch compares between scans
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch rebuilds whenever a file under dirs is created, changed or removed, until ctx is done
+// The folders are polled every Config.WatchInterval (no OS watcher dependency), changes found
+// in the same scan start a single build and Config.Debounce collapses bursts across scans
+// Files gobuild writes (UnobservedFiles, temp files, FinalNameFunc versions), the folders builds
+// write into (output, StagingDir, QuarantineDir, CacheDir, Coverage), hidden files/folders
+// (eg: .git) and editor backups are ignored, so a promotion never triggers the next build
+// Relative dirs are resolved against WorkDir, none watches the folder of MainInputFileRelativePath
+func (h *GoBuild) Watch(ctx context.Context, dirs ...string) error {
+	if len(dirs) == 0 {
+		dirs = []string{filepath.Dir(h.config.MainInputFileRelativePath)}
+	}
+	resolved := make([]string, len(dirs))
+	for i, dir := range dirs {
+		resolved[i] = h.resolve(dir)
+	}
+	dirs = resolved
+
+	interval := h.config.WatchInterval
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	prev, err := h.scanSources(dirs)
+	if err != nil {
+		return errors.Join(errors.New("Watch"), err)
+	}
+
+	ticker := h.clock().NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C():
+		}
+
+		cur, err := h.scanSources(dirs)
+		if err != nil {
+			// a folder removed or renamed by a git checkout, retried on the next tick
+			h.logf(LogWarn, 0, "Watch:", err)
+			continue
+		}
+		if changed := changedFiles(prev, cur); len(changed) > 0 {
+			h.logf(LogDebug, 0, "Changed:", strings.Join(changed, ", "))
+			h.Start()
+		}
+		prev = cur
+	}
+}
+
+// scanSources stamps every observed file under dirs
+func (h *GoBuild) scanSources(dirs []string) (map[string]fileStamp, error) {
+	files := map[string]fileStamp{}
+	skip := h.outputDirs()
+	for _, dir := range dirs {
+		err := filepath.WalkDir(fixLongPath(dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if path != fixLongPath(dir) && errors.Is(err, fs.ErrNotExist) {
+					return nil // removed while walking
+				}
+				return err
+			}
+			name := d.Name()
+			if d.IsDir() {
+				if path == fixLongPath(dir) {
+					return nil
+				}
+				if abs, _ := filepath.Abs(path); strings.HasPrefix(name, ".") || slices.Contains(skip, abs) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !h.observed(name) {
+				return nil
+			}
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+			files[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// observed reports whether a change to the file named name should trigger a build
+func (h *GoBuild) observed(name string) bool {
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, "~") {
+		return false
+	}
+	return !h.generatedFile(name)
+}
+
+// changedFiles returns the files created, modified or removed between two scans
+func changedFiles(prev, cur map[string]fileStamp) []string {
+	var changed []string
+	for path, stamp := range cur {
+		if old, ok := prev[path]; !ok || old != stamp {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := cur[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,117 @@
+package gobuild
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWatchRebuildsOnChange(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	if err := os.MkdirAll(filepath.Join(src, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	main := filepath.Join(src, "main.go")
+	os.WriteFile(main, []byte("package main"), 0644)
+
+	var builds atomic.Int32
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: main,
+		OutName:                   "app",
+		OutFolderRelativePath:     src, // artifacts next to the sources must not retrigger
+		WatchInterval:             20 * time.Millisecond,
+		OnSuccess:                 func(*BuildResult) { builds.Add(1) },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- gb.Watch(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+
+	// ignored: hidden folders and editor backups
+	os.WriteFile(filepath.Join(src, ".git", "index"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(src, "main.go~"), []byte("x"), 0644)
+	time.Sleep(100 * time.Millisecond)
+	if n := builds.Load(); n != 0 {
+		t.Fatalf("Expected no build for ignored files, got %d", n)
+	}
+
+	os.WriteFile(filepath.Join(src, "util.go"), []byte("package main"), 0644)
+	deadline := time.Now().Add(3 * time.Second)
+	for builds.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the promoted artifact lands in the watched folder, it must not start another build
+	time.Sleep(150 * time.Millisecond)
+	if n := builds.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 build, got %d", n)
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWatchSkipsOutputFolders(t *testing.T) {
+	dir := t.TempDir()
+	web := filepath.Join(dir, "web")
+	if err := os.MkdirAll(web, 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(web, "main.go"), []byte("package main"), 0644)
+
+	var builds atomic.Int32
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		WorkDir:                   dir,
+		MainInputFileRelativePath: "web/main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     "web/build", // versioned files and the manifest land here
+		FinalNameFunc:             func(i BuildInfo) string { return fmt.Sprintf("app-%d", i.ID) },
+		Manifest:                  true,
+		CacheDir:                  "web/cache", // a new entry after each compile
+		WatchInterval:             20 * time.Millisecond,
+		OnSuccess:                 func(*BuildResult) { builds.Add(1) },
+	})
+
+	dirs := []string{"web"}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- gb.Watch(ctx, dirs...) }()
+	time.Sleep(50 * time.Millisecond)
+
+	os.WriteFile(filepath.Join(web, "util.go"), []byte("package main"), 0644)
+	deadline := time.Now().Add(3 * time.Second)
+	for builds.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if n := builds.Load(); n != 1 {
+		t.Errorf("Expected exactly 1 build, got %d", n)
+	}
+	if gb.observed("app-1") {
+		t.Error("Expected the versioned file to be unobserved")
+	}
+	if dirs[0] != "web" {
+		t.Errorf("Watch must not rewrite the caller's dirs, got %q", dirs)
+	}
+
+	cancel()
+	<-done
+}
+
+func TestWatchMissingFolder(t *testing.T) {
+	gb := New(&Config{MainInputFileRelativePath: "main.go", OutName: "app"})
+	err := gb.Watch(context.Background(), filepath.Join(t.TempDir(), "missing"))
+	if err == nil || !strings.Contains(err.Error(), "Watch") {
+		t.Errorf("Expected a Watch error for a missing folder, got %v", err)
+	}
+}
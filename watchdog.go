@@ -0,0 +1,173 @@
+package gobuild
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Watchdog reports builds that keep running, to diagnose hangs the hard Timeout just kills
+// eg: &gobuild.Watchdog{Interval: 15 * time.Second, SoftLimit: time.Minute, DumpStacks: true}
+type Watchdog struct {
+	Interval   time.Duration       // between "still compiling" events, defaults to 15s
+	SoftLimit  time.Duration       // a build running longer is reported once as stalled, 0 disables
+	DumpStacks bool                // at SoftLimit send SIGQUIT to the compiler (unix): the go command prints its goroutine stacks into the output and exits
+	OnEvent    func(WatchdogEvent) // optional, events are also logged at LogWarn (stalled) or LogInfo
+}
+
+// WatchdogEvent is the periodic progress report of a running build
+type WatchdogEvent struct {
+	BuildID    uint64
+	Elapsed    time.Duration
+	Phase      string // validation, prepare, download, compile, link (with -x), post-process or rename
+	Stalled    bool   // Elapsed exceeded Watchdog.SoftLimit
+	OutputTail string // last lines of the compiler output so far, with -x the commands being run
+}
+
+// String returns the log line of the event, eg: "still compiling, 45s elapsed, phase=link"
+func (e WatchdogEvent) String() string {
+	s := fmt.Sprintf("still compiling, %v elapsed, phase=%s", e.Elapsed.Round(time.Second), e.Phase)
+	if e.Stalled {
+		s += " (stalled)"
+	}
+	return s
+}
+
+// watchdogTail is the amount of compiler output kept for WatchdogEvent.OutputTail
+const watchdogTail = 4 << 10
+
+// buildProgress is the state of a running build shared with the watchdog goroutine
+type buildProgress struct {
+	mu    sync.Mutex
+	phase string
+	tail  []byte
+	proc  *os.Process // compiler process, nil before it starts or with an injected Runner
+}
+
+func (p *buildProgress) setPhase(phase string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.phase = phase
+	p.mu.Unlock()
+}
+
+func (p *buildProgress) setProcess(proc *os.Process) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.proc = proc
+	p.mu.Unlock()
+}
+
+// snapshot returns the current phase, the output tail and the compiler process
+func (p *buildProgress) snapshot() (string, string, *os.Process) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tail := p.tail
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 && len(tail) == watchdogTail {
+		tail = tail[i+1:] // drop the partial first line
+	}
+	return p.phase, string(tail), p.proc
+}
+
+// progressWriter keeps the output tail and follows the -x commands: the go command
+// prints each tool invocation, the compile and link steps are the long ones
+type progressWriter struct {
+	out      io.Writer
+	progress *buildProgress
+}
+
+var (
+	linkMarker    = []byte("/link ")
+	compileMarker = []byte("/compile ")
+)
+
+func (w progressWriter) Write(p []byte) (int, error) {
+	w.progress.mu.Lock()
+	w.progress.tail = append(w.progress.tail, p...)
+	if len(w.progress.tail) > watchdogTail {
+		w.progress.tail = w.progress.tail[len(w.progress.tail)-watchdogTail:]
+	}
+	switch {
+	case bytes.Contains(p, linkMarker):
+		w.progress.phase = "link"
+	case bytes.Contains(p, compileMarker):
+		w.progress.phase = "compile"
+	case bytes.Contains(p, downloadMarker):
+		w.progress.phase = "download"
+	}
+	w.progress.mu.Unlock()
+	return w.out.Write(p)
+}
+
+// startWatchdog reports comp every Watchdog.Interval until it is done
+func (h *GoBuild) startWatchdog(comp *Build) {
+	wd := h.config.Watchdog
+	if wd == nil || comp.discard {
+		return
+	}
+	interval := wd.Interval
+	if interval <= 0 {
+		interval = 15 * time.Second
+	}
+	comp.progress = &buildProgress{phase: "validation"}
+
+	go func() {
+		ticker := h.clock().NewTicker(interval)
+		defer ticker.Stop()
+		var soft chan struct{}
+		if wd.SoftLimit > 0 {
+			soft = make(chan struct{}, 1)
+			timer := h.clock().AfterFunc(wd.SoftLimit, func() { soft <- struct{}{} })
+			defer timer.Stop()
+		}
+
+		for {
+			select {
+			case <-comp.done:
+				return
+			case <-comp.ctx.Done():
+				return
+			case <-ticker.C():
+				h.watchdogEvent(comp, false)
+			case <-soft:
+				h.watchdogEvent(comp, true)
+			}
+		}
+	}()
+}
+
+// watchdogEvent reports comp, a stalled build gets its stacks dumped with Watchdog.DumpStacks
+func (h *GoBuild) watchdogEvent(comp *Build, stalled bool) {
+	wd := h.config.Watchdog
+	phase, tail, proc := comp.progress.snapshot()
+	e := WatchdogEvent{
+		BuildID:    comp.ID,
+		Elapsed:    h.now().Sub(comp.startTime),
+		Phase:      phase,
+		Stalled:    stalled,
+		OutputTail: tail,
+	}
+
+	if !stalled {
+		h.logf(LogInfo, comp.ID, fmt.Sprintf("Build %d %s", comp.ID, e))
+	} else {
+		h.logf(LogWarn, comp.ID, fmt.Sprintf("Build %d %s", comp.ID, e), tail)
+	}
+	if wd.OnEvent != nil {
+		wd.OnEvent(e)
+	}
+
+	// after OnEvent, the dump ends the build
+	if stalled && wd.DumpStacks && proc != nil {
+		if err := quitProcess(proc); err != nil {
+			h.logf(LogWarn, comp.ID, "Watchdog stack dump failed:", err)
+		}
+	}
+}
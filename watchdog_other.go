@@ -0,0 +1,13 @@
+//go:build !unix
+
+package gobuild
+
+import (
+	"errors"
+	"os"
+)
+
+// quitProcess is not available, there is no SIGQUIT to make go programs dump their stacks
+func quitProcess(p *os.Process) error {
+	return errors.New("quitProcess: stack dumps are only supported on unix systems")
+}
@@ -0,0 +1,57 @@
+package gobuild
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchdogEvents(t *testing.T) {
+	dir := t.TempDir()
+	var mu sync.Mutex
+	var events []WatchdogEvent
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo '/usr/local/go/pkg/tool/linux_amd64/link -o $WORK/b001/exe/a.out' >&2; exec sleep 5"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Timeout:                   10 * time.Second,
+		Watchdog: &Watchdog{
+			Interval:   50 * time.Millisecond,
+			SoftLimit:  300 * time.Millisecond,
+			DumpStacks: true,
+			OnEvent: func(e WatchdogEvent) {
+				mu.Lock()
+				events = append(events, e)
+				mu.Unlock()
+			},
+		},
+	})
+
+	start := time.Now()
+	if _, err := gb.Compile(); err == nil {
+		t.Fatal("Expected the stalled build to fail once its stacks were dumped")
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("Expected DumpStacks to end the build early, took %v", elapsed)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) < 2 {
+		t.Fatalf("Expected periodic events, got %d", len(events))
+	}
+	var stalled WatchdogEvent
+	for _, e := range events {
+		if e.Stalled {
+			stalled = e
+		}
+	}
+	if stalled.Phase != "link" || !strings.Contains(stalled.OutputTail, "/link -o") {
+		t.Errorf("Unexpected stalled event %+v", stalled)
+	}
+	if s := stalled.String(); !strings.HasPrefix(s, "still compiling, ") || !strings.HasSuffix(s, "phase=link (stalled)") {
+		t.Errorf("Unexpected event message %q", s)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build unix
+
+package gobuild
+
+import (
+	"os"
+	"syscall"
+)
+
+// quitProcess sends SIGQUIT, go programs print every goroutine stack to stderr and exit
+func quitProcess(p *os.Process) error {
+	return p.Signal(syscall.SIGQUIT)
+}
@@ -0,0 +1,191 @@
+package gobuild
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 of the body with Webhook.Secret, eg: "sha256=5d41..."
+const WebhookSignatureHeader = "X-Gobuild-Signature"
+
+// Webhook receives the JSON of every finished build (BuildResult) as a POST request
+// eg: {URL: "https://chat.example.com/hooks/builds", Secret: os.Getenv("HOOK_SECRET")}
+type Webhook struct {
+	URL     string
+	Secret  string        // optional, signs the body in WebhookSignatureHeader
+	Header  http.Header   // optional extra headers, eg: Authorization
+	Client  *http.Client  // defaults to http.DefaultClient
+	Retries int           // extra attempts on network errors, 429 and 5xx responses
+	Backoff time.Duration // wait before the first retry, doubled on each one. Default: 1s
+	Failed  bool          // also send failed builds, cancelled and dropped ones never are
+}
+
+// SignWebhook returns the WebhookSignatureHeader value of body, for receivers to compare
+// with hmac.Equal against the header they got
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts body, retrying with backoff until ctx is done
+func (w Webhook) Send(ctx context.Context, body []byte) error {
+	backoff := w.Backoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		retry, err = w.post(ctx, body)
+		if err == nil || !retry || attempt >= w.Retries {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(ctx.Err(), err)
+		}
+		backoff *= 2
+	}
+}
+
+// post sends body once, retry reports whether another attempt may succeed
+func (w Webhook) post(ctx context.Context, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range w.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if w.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(w.Secret, body))
+	}
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, fmt.Errorf("webhook %s: %s", w.URL, resp.Status)
+	}
+	return false, nil
+}
+
+// webhookQueue serializes the deliveries to one URL: one in flight and the newest
+// result waiting, a result still waiting when a newer one arrives is dropped
+type webhookQueue struct {
+	sending bool
+	next    *webhookDelivery
+}
+
+// webhookDelivery is the result of one build for one Webhook
+type webhookDelivery struct {
+	hook Webhook
+	body []byte
+	id   uint64
+}
+
+// webhookContext returns the context of the deliveries, cancelled by Close
+func (h *GoBuild) webhookContext() context.Context {
+	h.hookMu.Lock()
+	defer h.hookMu.Unlock()
+	if h.hookCtx == nil {
+		h.hookCtx, h.hookStop = context.WithCancel(context.Background())
+	}
+	return h.hookCtx
+}
+
+// sendWebhooks posts the result of comp to Config.Webhooks in the background
+// Failures are logged, they never affect the build
+func (h *GoBuild) sendWebhooks(comp *Build) {
+	r := comp.result
+	if len(h.config.Webhooks) == 0 || comp.discard || r.Code == ErrCodeCancelled {
+		return
+	}
+
+	var body []byte
+	for _, w := range h.config.Webhooks {
+		if r.Err != nil && !w.Failed {
+			continue
+		}
+		if body == nil {
+			data, err := json.Marshal(r)
+			if err != nil {
+				h.logf(LogWarn, comp.ID, "Webhook payload failed:", err)
+				return
+			}
+			body = data
+		}
+		h.queueWebhook(&webhookDelivery{hook: w, body: body, id: comp.ID})
+	}
+}
+
+// queueWebhook starts the delivery of d unless its URL is busy, then d waits in
+// place of the result waiting before it
+func (h *GoBuild) queueWebhook(d *webhookDelivery) {
+	ctx := h.webhookContext()
+
+	h.hookMu.Lock()
+	if h.hooks == nil {
+		h.hooks = make(map[string]*webhookQueue)
+	}
+	q := h.hooks[d.hook.URL]
+	if q == nil {
+		q = &webhookQueue{}
+		h.hooks[d.hook.URL] = q
+	}
+	superseded, start := q.next, !q.sending
+	if start {
+		q.sending = true
+	} else {
+		q.next = d
+	}
+	h.hookMu.Unlock()
+
+	if superseded != nil {
+		h.logf(LogInfo, superseded.id, "Webhook skipped, superseded by build", d.id)
+	}
+	if start {
+		go h.deliverWebhooks(ctx, q, d)
+	}
+}
+
+// deliverWebhooks sends d and then the results that waited for it, one at a time
+// After Close the waiting result is dropped
+func (h *GoBuild) deliverWebhooks(ctx context.Context, q *webhookQueue, d *webhookDelivery) {
+	for d != nil {
+		if err := d.hook.Send(ctx, d.body); err != nil {
+			h.logf(LogWarn, d.id, "Webhook failed:", err)
+		}
+		h.hookMu.Lock()
+		d, q.next = q.next, nil
+		if ctx.Err() != nil {
+			d = nil
+		}
+		q.sending = d != nil
+		h.hookMu.Unlock()
+	}
+}
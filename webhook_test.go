@@ -0,0 +1,179 @@
+package gobuild
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookSignedWithRetries(t *testing.T) {
+	var attempts atomic.Int32
+	received := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		if got := r.Header.Get(WebhookSignatureHeader); got != SignWebhook("s3cret", body) {
+			t.Errorf("Unexpected signature %q", got)
+		}
+		var payload map[string]any
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Error(err)
+		}
+		received <- payload
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Webhooks:                  []Webhook{{URL: srv.URL, Secret: "s3cret", Retries: 2, Backoff: time.Millisecond}},
+	})
+
+	result, err := gb.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload["id"] != float64(result.ID) || payload["sha256"] != result.Hash || payload["error"] != nil {
+			t.Errorf("Unexpected payload %v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Webhook not delivered")
+	}
+	if n := attempts.Load(); n != 2 {
+		t.Errorf("Expected 2 attempts, got %d", n)
+	}
+}
+
+func TestWebhookFailedBuilds(t *testing.T) {
+	received := make(chan string, 2)
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var payload struct {
+				Code  ErrorCode `json:"code"`
+				Error string    `json:"error"`
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			if payload.Code != ErrCodeCompile || payload.Error == "" {
+				t.Errorf("Unexpected payload %+v", payload)
+			}
+			received <- name
+		})
+	}
+	all := httptest.NewServer(handler("all"))
+	defer all.Close()
+	successOnly := httptest.NewServer(handler("success-only"))
+	defer successOnly.Close()
+
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, "echo 'syntax error' >&2; exit 1"),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Webhooks:                  []Webhook{{URL: all.URL, Failed: true}, {URL: successOnly.URL}},
+	})
+
+	if _, err := gb.Compile(); err == nil {
+		t.Fatal("Expected the build to fail")
+	}
+
+	select {
+	case name := <-received:
+		if name != "all" {
+			t.Errorf("Expected only the Failed webhook, got %s", name)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Webhook not delivered")
+	}
+	select {
+	case name := <-received:
+		t.Errorf("Unexpected delivery to %s", name)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestWebhookSupersededAndClosed(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan float64, 4)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			ID float64 `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload.ID
+		<-release
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	gb := New(&Config{
+		Command:                   writeFakeCompiler(t, dir, fakeEchoCompiler),
+		MainInputFileRelativePath: "main.go",
+		OutName:                   "app",
+		OutFolderRelativePath:     dir,
+		Webhooks:                  []Webhook{{URL: srv.URL, Retries: 5, Backoff: time.Hour}},
+	})
+
+	var ids []uint64
+	for i := 0; i < 3; i++ {
+		result, err := gb.Compile()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, result.ID)
+		if i == 0 {
+			if id := <-received; id != float64(result.ID) {
+				t.Fatalf("Expected build %d delivered first, got %v", result.ID, id)
+			}
+		}
+	}
+
+	// the second result is superseded while the first is in flight, the third waits
+	release <- struct{}{}
+	select {
+	case id := <-received:
+		t.Fatalf("Expected the retry to wait for the hour backoff, got a delivery of %v", id)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	gb.hookMu.Lock()
+	next := gb.hooks[srv.URL].next
+	gb.hookMu.Unlock()
+	if next == nil || next.id != ids[2] {
+		t.Fatalf("Expected build %d waiting in place of build %d, got %+v", ids[2], ids[1], next)
+	}
+
+	// closing abandons the retry and drops the third result
+	gb.Close()
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(5 * time.Millisecond) {
+		gb.hookMu.Lock()
+		sending := gb.hooks[srv.URL].sending
+		gb.hookMu.Unlock()
+		if !sending {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected Close to stop the delivery")
+		}
+	}
+	select {
+	case id := <-received:
+		t.Errorf("Expected no delivery after Close, got %v (builds %v)", id, ids)
+	default:
+	}
+	close(release)
+}
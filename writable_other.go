@@ -0,0 +1,8 @@
+//go:build !unix
+
+package gobuild
+
+// dirWritable assumes a writable folder, Windows ignores the read-only attribute on folders
+func dirWritable(dir string) bool {
+	return true
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package gobuild
+
+import "syscall"
+
+// dirWritable asks the kernel, without creating files a watcher would see
+// Covers permissions and read-only mounts (EROFS)
+func dirWritable(dir string) bool {
+	const wOK = 0x2 // W_OK
+	return syscall.Access(dir, wOK) == nil
+}